@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path"
 	"strings"
@@ -56,8 +57,8 @@ var rootCmd = &cobra.Command{
 		// Generate & split traces
 		valid, invalid := generateTestTraces(cfg, schema)
 		// Write out
-		writeTestTraces(cfg.model, "accepts", schema, valid)
-		writeTestTraces(cfg.model, "rejects", schema, invalid)
+		writeTestTraces(cfg.model, "accepts", valid)
+		writeTestTraces(cfg.model, "rejects", invalid)
 		os.Exit(0)
 
 	},
@@ -102,29 +103,43 @@ func findModel(name string) Model {
 }
 
 // Generate test traces
-func generateTestTraces(cfg TestGenConfig, schema sc.Schema) ([]tr.Trace, []tr.Trace) {
-	// NOTE: This is really a temporary solution for now.  It doesn't handle
-	// length multipliers.  It doesn't allow for modules with different heights.
-	// It uses a fixed pool.
+func generateTestTraces(cfg TestGenConfig, schema sc.Schema) ([][]tr.RawColumn, [][]tr.RawColumn) {
+	var enumerators []util.Enumerator[[]tr.RawColumn]
+	//
 	pool := generatePool(cfg)
-	valid := make([]tr.Trace, 0)
-	invalid := make([]tr.Trace, 0)
+	builder := sc.NewTraceBuilder(schema).Expand(true).Parallel(false).Padding(0)
 	//
 	for n := cfg.min_lines; n < cfg.max_lines; n++ {
-		enumerator := sc.NewTraceEnumerator(n, schema, pool)
-		// Generate and split the traces
-		for enumerator.HasNext() {
-			trace := enumerator.Next()
-			// Check whether trace is valid or not (according to the oracle)
-			if cfg.model.Oracle(schema, trace) {
-				valid = append(valid, trace)
-			} else {
-				invalid = append(invalid, trace)
-			}
+		heights := make([]uint, schema.Modules().Count())
+		//
+		for i := range heights {
+			heights[i] = n
 		}
+		//
+		enumerators = append(enumerators, sc.NewColumnEnumerator(schema, heights, pool))
+	}
+	// Generate and split the traces
+	return sc.ClassifyTraces(util.NewConcatEnumerator(enumerators), modelOracle(cfg.model, schema, builder),
+		math.MaxUint)
+}
+
+// Construct an oracle from a given model, such that each trace is built before
+// being checked by the model.  Traces which cannot be built are reported, and
+// considered rejected.
+func modelOracle(model Model, schema sc.Schema, builder sc.TraceBuilder) sc.TraceOracle {
+	return func(columns []tr.RawColumn) bool {
+		trace, errs := builder.Build(columns)
+		// Should be unreachable, since control the trace!
+		for _, err := range errs {
+			log.Errorf("invalid trace constructed for model %s (%s)", model.Name, err)
+		}
+		//
+		if trace == nil {
+			return false
+		}
+		//
+		return model.Oracle(schema, trace)
 	}
-	// Done
-	return valid, invalid
 }
 
 func generatePool(cfg TestGenConfig) []fr.Element {
@@ -139,13 +154,12 @@ func generatePool(cfg TestGenConfig) []fr.Element {
 	return elems
 }
 
-func writeTestTraces(model Model, ext string, schema sc.Schema, traces []tr.Trace) {
+func writeTestTraces(model Model, ext string, traces [][]tr.RawColumn) {
 	var sb strings.Builder
 	// Construct filename
 	filename := fmt.Sprintf("testdata/%s.auto.%s", model.Name, ext)
 	// Generate lines
-	for _, trace := range traces {
-		raw := traceToColumns(schema, trace)
+	for _, raw := range traces {
 		json := json.ToJsonString(raw)
 		sb.WriteString(json)
 		sb.WriteString("\n")
@@ -158,27 +172,6 @@ func writeTestTraces(model Model, ext string, schema sc.Schema, traces []tr.Trac
 	log.Infof("Wrote %s (%d traces)\n", filename, len(traces))
 }
 
-// Convert a trace into an array of raw columns.
-func traceToColumns(schema sc.Schema, trace tr.Trace) []tr.RawColumn {
-	ncols := schema.InputColumns().Count()
-	cols := make([]tr.RawColumn, ncols)
-	i := 0
-	// Convert each column
-	for iter := schema.InputColumns(); iter.HasNext(); {
-		sc_col := iter.Next()
-		// Lookup the column data
-		tr_col := findColumn(sc_col.Context.Module(), sc_col.Name, schema, trace)
-		// Determine module name
-		mod := schema.Modules().Nth(sc_col.Context.Module())
-		// Assignt the raw colmn
-		cols[i] = tr.RawColumn{Module: mod.Name, Name: sc_col.Name, Data: tr_col.Data()}
-		//
-		i++
-	}
-	//
-	return cols
-}
-
 func readSchemaFile(filename string) *hir.Schema {
 	// Read schema file
 	bytes, err := os.ReadFile(filename)
@@ -190,7 +183,7 @@ func readSchemaFile(filename string) *hir.Schema {
 	// Package up as source file
 	srcfile := sexp.NewSourceFile(filename, bytes)
 	// Attempt to parse schema
-	schema, err2 := corset.CompileSourceFile(false, false, srcfile)
+	schema, err2 := corset.CompileSourceFile(true, false, srcfile)
 	// Check whether parsed successfully or not
 	if err2 == nil {
 		// Ok
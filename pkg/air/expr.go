@@ -16,6 +16,7 @@ import (
 type Expr interface {
 	util.Boundable
 	sc.Evaluable
+	sc.Explainable
 
 	// Add two expressions together, producing a third.
 	Add(Expr) Expr
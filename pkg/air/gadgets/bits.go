@@ -13,6 +13,7 @@ import (
 // which enforces that all values in the given column are either 0 or 1. For a
 // column X, this corresponds to the vanishing constraint X * (X-1) == 0.
 func ApplyBinaryGadget(col uint, schema *air.Schema) {
+	defer attributeTo("binary", schema)()
 	// Identify target column
 	column := schema.Columns().Nth(col)
 	// Determine column name
@@ -31,6 +32,7 @@ func ApplyBinaryGadget(col uint, schema *air.Schema) {
 // number of bits.  This is implemented using a *byte decomposition* which adds
 // n columns and a vanishing constraint (where n*8 >= nbits).
 func ApplyBitwidthGadget(col uint, nbits uint, schema *air.Schema) {
+	defer attributeTo("bitwidth", schema)()
 	if nbits%8 != 0 {
 		panic("asymmetric bitwidth constraints not yet supported")
 	} else if nbits == 0 {
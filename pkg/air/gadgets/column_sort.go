@@ -21,6 +21,7 @@ import (
 // and assumes the data either comes sorted or is sorted by some other
 // computation.
 func ApplyColumnSortGadget(col uint, sign bool, bitwidth uint, schema *air.Schema) {
+	defer attributeTo("column sort", schema)()
 	var deltaName string
 	// Identify target column
 	column := schema.Columns().Nth(col)
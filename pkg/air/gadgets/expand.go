@@ -14,6 +14,7 @@ import (
 // in the case the given expression is a direct column access by simply
 // returning the accessed column index.
 func Expand(ctx trace.Context, e air.Expr, schema *air.Schema) uint {
+	defer attributeTo("expand", schema)()
 	if ctx.IsVoid() || ctx.IsConflicted() {
 		panic("conflicting (or void) context")
 	}
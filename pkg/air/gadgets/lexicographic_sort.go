@@ -29,6 +29,7 @@ import (
 // ensure it is positive.  The delta column is constrained to a given bitwidth,
 // with constraints added as necessary to ensure this.
 func ApplyLexicographicSortingGadget(columns []uint, signs []bool, bitwidth uint, schema *air.Schema) {
+	defer attributeTo("lexicographic sort", schema)()
 	ncols := len(columns)
 	// Check preconditions
 	if ncols != len(signs) {
@@ -29,6 +29,7 @@ func Normalise(e air.Expr, schema *air.Schema) air.Expr {
 // column which holds the multiplicative inverse.  Constraints are also added to
 // ensure it really holds the inverted value.
 func ApplyPseudoInverseGadget(e air.Expr, schema *air.Schema) air.Expr {
+	defer attributeTo("pseudo inverse", schema)()
 	// Determine enclosing module.
 	ctx := e.Context(schema)
 	// Sanity check
@@ -0,0 +1,16 @@
+package gadgets
+
+import (
+	"github.com/consensys/go-corset/pkg/air"
+)
+
+// Attribute all assignments and constraints which a gadget adds to a given
+// schema to that gadget.  This returns a function which should be called once
+// the gadget has completed (e.g. using defer).
+func attributeTo(gadget string, schema *air.Schema) func() {
+	mark := schema.Mark()
+	//
+	return func() {
+		schema.AttributeGadget(mark, schema.Mark(), gadget)
+	}
+}
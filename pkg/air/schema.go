@@ -52,6 +52,8 @@ type Schema struct {
 	assertions []PropertyAssertion
 	// Cache list of columns declared in inputs and assignments.
 	column_cache []schema.Column
+	// Provenance of assignments and constraints arising from lowering.
+	schema.Provenances
 }
 
 // EmptySchema is used to construct a fresh schema onto which new columns and
@@ -68,6 +70,12 @@ func EmptySchema[C schema.Evaluable]() *Schema {
 	return p
 }
 
+// Mark identifies the current point in the construction of this schema, such
+// that any assignments and constraints added subsequently can be attributed.
+func (p *Schema) Mark() schema.ProvenanceMark {
+	return schema.ProvenanceMark{Assignments: uint(len(p.assignments)), Constraints: uint(len(p.constraints))}
+}
+
 // AddModule adds a new module to this schema, returning its module index.
 func (p *Schema) AddModule(name string) uint {
 	mid := uint(len(p.modules))
@@ -78,12 +86,13 @@ func (p *Schema) AddModule(name string) uint {
 
 // AddColumn appends a new data column whose values must be provided by the
 // user.
-func (p *Schema) AddColumn(context trace.Context, name string, datatype schema.Type) uint {
+func (p *Schema) AddColumn(context trace.Context, name string, datatype schema.Type, display schema.Display,
+	padding fr.Element) uint {
 	if context.Module() >= uint(len(p.modules)) {
 		panic(fmt.Sprintf("invalid module index (%d)", context.Module()))
 	}
 
-	col := assignment.NewDataColumn(context, name, datatype)
+	col := assignment.NewDataColumn(context, name, datatype, display, padding)
 	// NOTE: the air level has no ability to enforce the type specified for a
 	// given column.
 	p.inputs = append(p.inputs, col)
@@ -134,7 +143,8 @@ func (p *Schema) AddLookupConstraint(handle string, source trace.Context,
 // ensures that one column is a permutation of another.
 func (p *Schema) AddPermutationConstraint(targets []uint, sources []uint) {
 	// TODO: sanity target and source columns are in the same module.
-	p.constraints = append(p.constraints, constraint.NewPermutationConstraint(targets, sources))
+	handle := constraint.PermutationHandle(targets, sources, p)
+	p.constraints = append(p.constraints, constraint.NewPermutationConstraint(handle, targets, sources))
 }
 
 // AddPropertyAssertion appends a new property assertion.
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/hir"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/trace"
@@ -86,6 +87,13 @@ type register struct {
 	// must be a factor of the number of rows in the column.  For example, a
 	// column with length multiplier of 2 must have an even number of rows, etc.
 	LengthMultiplier uint `json:"length_multiplier"`
+	// Display indicates how values of this register should be shown.  As for
+	// MustProve, this field is not present in the original binfile format and
+	// is instead determined from the columns allocated to this register.
+	Display sc.Display `json:"-"`
+	// Padding indicates the value used for padding rows of this register.
+	// Again, this is determined from the columns allocated to this register.
+	Padding fr.Element `json:"-"`
 }
 
 type columnSet struct {
@@ -157,7 +165,36 @@ func transferColumnInfo(cs *columnSet) {
 			// Copy over must-prove info.
 			cs.Registers[c.Register].MustProve = true
 		}
+		// Copy over display info (if recognised).
+		if display, ok := sc.ParseDisplay(c.Base); ok {
+			cs.Registers[c.Register].Display = display
+		}
+		// Copy over padding info (if given).
+		if c.PaddingValue != nil {
+			cs.Registers[c.Register].Padding = paddingValue(c.Handle, c.PaddingValue)
+		}
+	}
+}
+
+// Convert a padding value into a field element.  Padding values can be given
+// either as plain numbers, as strings or as (serialised) big integers.
+func paddingValue(handle string, value any) fr.Element {
+	var padding fr.Element
+	//
+	switch v := value.(type) {
+	case float64:
+		padding.SetInt64(int64(v))
+	case string:
+		if _, err := padding.SetString(v); err != nil {
+			panic(fmt.Sprintf("invalid padding value for column %s (%s)", handle, v))
+		}
+	case []any:
+		padding = (&jsonExprConst{v}).ToField()
+	default:
+		panic(fmt.Sprintf("invalid padding value for column %s (%v)", handle, v))
 	}
+	//
+	return padding
 }
 
 // Allocate all registers as columns in the given schema, whilst producing a
@@ -175,7 +212,7 @@ func allocateRegisters(cs *constraintSet, schema *hir.Schema) map[uint]uint {
 			ctx := trace.NewContext(mid, c.LengthMultiplier)
 			col_type := c.Type.toHir()
 			// Add column for this
-			cid := schema.AddDataColumn(ctx, handle.column, col_type)
+			cid := schema.AddDataColumn(ctx, handle.column, col_type, c.Display, c.Padding)
 			// Check whether a type constraint required or not.
 			if c.MustProve && col_type.AsUint() != nil {
 				bound := col_type.AsUint().Bound()
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	log "github.com/sirupsen/logrus"
+)
+
+// FailureBaseline records the set of known failures for each of a set of trace
+// files.  This allows traces to be checked against constraints which are known
+// not to hold (e.g. because they are being fixed), such that only new failures
+// are considered errors.
+type FailureBaseline struct {
+	// Known failures for each trace file, indexed by trace file (whose path is
+	// normalised, see baselineKey).
+	Traces map[string][]BaselineEntry `json:"traces"`
+	// Protects concurrent updates to this baseline.
+	mux sync.Mutex
+}
+
+// BaselineEntry identifies a known failure, namely a constraint which is known
+// to reject a given trace at a given IR level.
+type BaselineEntry struct {
+	// IR level at which the failure arises (e.g. HIR, MIR or AIR).
+	IR string `json:"ir"`
+	// Handle of the failing constraint.
+	Handle string `json:"handle"`
+}
+
+// Read a failure baseline from a given file.  If the file does not exist, then
+// nil is returned.
+func readBaseline(filename string) (*FailureBaseline, error) {
+	var baseline FailureBaseline
+	// Read data file
+	bytes, err := os.ReadFile(filename)
+	// Check success
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if err = json.Unmarshal(bytes, &baseline); err != nil {
+		return nil, fmt.Errorf("malformed baseline file %s (%s)", filename, err)
+	}
+	// Normalise trace files (e.g. for baselines written by hand)
+	traces := baseline.Traces
+	baseline.Traces = nil
+	//
+	for file, entries := range traces {
+		for _, entry := range entries {
+			baseline.record(file, entry.IR, []string{entry.Handle})
+		}
+	}
+	//
+	return &baseline, nil
+}
+
+// Write a failure baseline to a given file.  Entries are sorted to ensure the
+// file is stable across runs.
+func writeBaseline(filename string, baseline *FailureBaseline) error {
+	for _, entries := range baseline.Traces {
+		slices.SortFunc(entries, func(l BaselineEntry, r BaselineEntry) int {
+			if c := cmp.Compare(l.IR, r.IR); c != 0 {
+				return c
+			}
+			//
+			return cmp.Compare(l.Handle, r.Handle)
+		})
+	}
+	//
+	bytes, err := json.MarshalIndent(baseline, "", "  ")
+	//
+	if err == nil {
+		err = os.WriteFile(filename, bytes, 0644)
+	}
+	//
+	return err
+}
+
+// Record a set of failures for a given trace file and IR level.  Each failure is
+// recorded at most once.
+func (p *FailureBaseline) record(file string, ir string, handles []string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	//
+	if p.Traces == nil {
+		p.Traces = make(map[string][]BaselineEntry)
+	}
+	//
+	file = baselineKey(file)
+	//
+	for _, handle := range handles {
+		entry := BaselineEntry{ir, handle}
+		//
+		if !slices.Contains(p.Traces[file], entry) {
+			p.Traces[file] = append(p.Traces[file], entry)
+		}
+	}
+}
+
+// Determine the handles of all known failures for a given trace file and IR
+// level.
+func (p *FailureBaseline) known(file string, ir string) []string {
+	var handles []string
+	//
+	if p == nil {
+		return nil
+	}
+	//
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	//
+	for _, entry := range p.Traces[baselineKey(file)] {
+		if entry.IR == ir {
+			handles = append(handles, entry.Handle)
+		}
+	}
+	//
+	return handles
+}
+
+// Determine the key identifying a given trace file in a baseline.  Paths are
+// normalised, such that the same trace file given in different ways (e.g.
+// "./t.lt" and "t.lt") is identified by the same key.
+func baselineKey(file string) string {
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+// Count the total number of known failures in this baseline.
+func (p *FailureBaseline) size() uint {
+	count := uint(0)
+	//
+	for _, entries := range p.Traces {
+		count += uint(len(entries))
+	}
+	//
+	return count
+}
+
+// Split a set of failures into those which are expected (i.e. are either known
+// failures in the baseline, or were explicitly expected) and those which are
+// not.  When recording a baseline, all failures are expected.
+func partitionFailures(ir string, failures []sc.Failure, cfg checkConfig) (expected []sc.Failure,
+	unexpected []sc.Failure) {
+	known := cfg.baseline.known(cfg.traceFile, ir)
+	//
+	for _, f := range failures {
+		handle := failureHandle(f)
+		//
+		if cfg.recording != nil || slices.Contains(known, handle) || slices.Contains(cfg.expectFail, handle) {
+			expected = append(expected, f)
+		} else {
+			unexpected = append(unexpected, f)
+		}
+	}
+	//
+	return expected, unexpected
+}
+
+// Check the failures observed for a given trace at a given IR level against
+// those expected, returning false if any expected failure did not arise.
+// Furthermore, known failures in the baseline which did not arise are reported
+// as fixed, and all failures are recorded when recording a new baseline.
+func checkExpectedFailures(ir string, failures []sc.Failure, cfg checkConfig) bool {
+	ok := true
+	handles := make([]string, len(failures))
+	//
+	for i, f := range failures {
+		handles[i] = failureHandle(f)
+	}
+	// Record failures (if applicable)
+	if cfg.recording != nil {
+		cfg.recording.record(cfg.traceFile, ir, handles)
+		return true
+	}
+	// Report any fixed failures
+	for _, handle := range cfg.baseline.known(cfg.traceFile, ir) {
+		if !slices.Contains(handles, handle) {
+			log.Infof("constraint \"%s\" no longer fails (%s)", handle, cfg.label(ir))
+		}
+	}
+	// Check expected failures arose
+	for _, handle := range cfg.expectFail {
+		if !slices.Contains(handles, handle) {
+			log.Errorf("constraint \"%s\" expected to fail (%s)", handle, cfg.label(ir))
+			//
+			ok = false
+		}
+	}
+	//
+	return ok
+}
+
+// Determine the handle identifying the constraint responsible for a given
+// failure.
+func failureHandle(failure sc.Failure) string {
+	switch f := failure.(type) {
+	case *constraint.VanishingFailure:
+		return f.Handle
+	case *constraint.LookupFailure:
+		return f.Handle
+	case *constraint.RangeFailure:
+		return f.Handle
+	case *constraint.PermutationFailure:
+		return f.Handle
+	case *sc.AssertionFailure:
+		return f.Handle
+	default:
+		return failure.Message()
+	}
+}
@@ -5,8 +5,15 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/hir"
+	"github.com/consensys/go-corset/pkg/mir"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/schema/constraint"
 	tr "github.com/consensys/go-corset/pkg/trace"
@@ -17,16 +24,19 @@ import (
 
 // computeCmd represents the compute command
 var checkCmd = &cobra.Command{
-	Use:   "check [flags] trace_file constraint_file",
-	Short: "Check a given trace against a set of constraints.",
-	Long: `Check a given trace against a set of constraints.
-	Traces can be given either as JSON or binary lt files.
-	Constraints can be given either as lisp or bin files.`,
+	Use:   "check [flags] trace_file(s) constraint_file",
+	Short: "Check one or more traces against a set of constraints.",
+	Long: `Check one or more traces against a set of constraints.
+	Traces can be given either as JSON or binary lt files, and may be
+	given as glob patterns or directories (which are searched for trace
+	files).  Constraints can be given either as lisp or bin files.  When
+	several traces are given, they are checked in parallel and a summary
+	is reported.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var hirSchema *hir.Schema
 		var cfg checkConfig
 
-		if len(args) != 2 {
+		if len(args) < 2 {
 			fmt.Println(cmd.UsageString())
 			os.Exit(1)
 		}
@@ -41,35 +51,115 @@ var checkCmd = &cobra.Command{
 		cfg.hir = GetFlag(cmd, "hir")
 		cfg.expand = !GetFlag(cmd, "raw")
 		cfg.report = GetFlag(cmd, "report")
+		cfg.reportFormat = GetString(cmd, "report-format")
+		cfg.explain = GetFlag(cmd, "explain")
 		cfg.reportPadding = GetUint(cmd, "report-context")
 		cfg.reportCellWidth = GetUint(cmd, "report-cellwidth")
-		cfg.spillage = GetInt(cmd, "spillage")
+		cfg.reportNearest = GetUint(cmd, "report-nearest")
+		cfg.spillage = GetSpillage(cmd, "spillage")
 		cfg.strict = !GetFlag(cmd, "warn")
 		cfg.stdlib = !GetFlag(cmd, "no-stdlib")
 		cfg.debug = GetFlag(cmd, "debug")
 		cfg.quiet = GetFlag(cmd, "quiet")
-		cfg.padding.Right = GetUint(cmd, "padding")
+		cfg.padding = GetRange(cmd, "padding")
 		cfg.parallelExpansion = !GetFlag(cmd, "sequential")
 		cfg.batchSize = GetUint(cmd, "batch")
+		cfg.maxFailures = GetUint(cmd, "max-failures")
+		cfg.maxTotalFailures = GetUint(cmd, "max-total-failures")
 		cfg.ansiEscapes = GetFlag(cmd, "ansi-escapes")
-		// TODO: support true ranges
-		cfg.padding.Left = cfg.padding.Right
-		if !cfg.hir && !cfg.mir && !cfg.air {
-			// If IR not specified default to running all.
+		cfg.jobs = max(1, GetUint(cmd, "jobs"))
+		cfg.modules = GetStringArray(cmd, "module")
+		cfg.expectFail = GetStringArray(cmd, "expect-fail")
+		cfg.consistency = GetFlag(cmd, "consistency")
+		cfg.rows = util.NewPair[uint, uint](0, math.MaxUint)
+		// Parse row window (if given)
+		if GetString(cmd, "rows") != "" {
+			cfg.rows = GetRange(cmd, "rows")
+		}
+		// Parse constraint pattern (if given)
+		if pattern := GetString(cmd, "constraint"); pattern != "" {
+			if re, err := regexp.Compile(pattern); err != nil {
+				fmt.Printf("invalid --constraint: %s\n", err)
+				os.Exit(2)
+			} else {
+				cfg.constraint = re
+			}
+		}
+		// Sanity check report format
+		if cfg.reportFormat != "text" && cfg.reportFormat != "json" {
+			fmt.Printf("unknown report format \"%s\"\n", cfg.reportFormat)
+			os.Exit(2)
+		}
+		// Parse padding protocol (if given)
+		if protocol, err := parsePaddingProtocol(GetStringArray(cmd, "pad-to")); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		} else {
+			cfg.paddingProtocol = protocol
+		}
+		//
+		if cfg.consistency || (!cfg.hir && !cfg.mir && !cfg.air) {
+			// If IR not specified (or checking consistency) default to running
+			// all.
 			cfg.hir, cfg.mir, cfg.air = true, true, true
 		}
 		//
 		stats := util.NewPerfStats()
 		// Parse constraints
-		hirSchema = readSchema(cfg.stdlib, cfg.debug, legacy, args[1:])
+		hirSchema = readSchema(cfg.stdlib, cfg.debug, legacy, args[len(args)-1:])
+		cfg.sources = hirSchema
 		//
 		stats.Log("Reading constraints file")
-		// Parse trace file
-		columns := readTraceFile(args[0])
+		// Read baseline (if applicable)
+		baselineFile := GetString(cmd, "baseline")
+		//
+		if baselineFile != "" {
+			if baseline, err := readBaseline(baselineFile); err != nil {
+				fmt.Println(err)
+				os.Exit(2)
+			} else if baseline == nil || GetFlag(cmd, "update-baseline") {
+				// Record a new baseline
+				cfg.recording = &FailureBaseline{}
+			} else {
+				cfg.baseline = baseline
+			}
+		}
+		// Lower constraints (once)
+		schemas := lowerSchemas(hirSchema, cfg)
 		//
-		stats.Log("Reading trace file")
-		// Go!
-		if !checkTraceWithLowering(columns, hirSchema, cfg) {
+		stats.Log("Lowering constraints")
+		// Determine trace files
+		files, err := expandTraceFiles(args[:len(args)-1])
+		ok, consistent := true, true
+		//
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		} else if len(files) == 1 {
+			// Parse trace file
+			columns := readTraceFile(files[0])
+			//
+			stats.Log("Reading trace file")
+			// Go!
+			results := checkTraceWithLowering(columns, schemas, cfg.withTraceFile(files[0]))
+			ok, consistent = printTraceSummary(files, schemas, [][]bool{results}, []error{nil}, cfg)
+		} else {
+			ok, consistent = checkTraceFiles(files, schemas, cfg)
+		}
+		// Write baseline (if applicable)
+		if cfg.recording != nil {
+			if err := writeBaseline(baselineFile, cfg.recording); err != nil {
+				fmt.Println(err)
+				os.Exit(2)
+			}
+			//
+			log.Infof("recorded %d failure(s) in baseline %s", cfg.recording.size(), baselineFile)
+		}
+		// Divergence between IR levels indicates a bug in lowering, rather than
+		// an invalid trace.
+		if !consistent {
+			os.Exit(3)
+		} else if !ok {
 			os.Exit(1)
 		}
 	},
@@ -85,11 +175,15 @@ type checkConfig struct {
 	// Performing checking at AIR level
 	air bool
 	// Determines how much spillage to account for.  This gives the user the
-	// ability to override the inferred default.  A negative value indicates
-	// this default should be used.
-	spillage int
-	// Determines how much padding to use
+	// ability to override the inferred default.  A nil value indicates this
+	// default should be used.
+	spillage sc.SpillageOverride
+	// Determines how much padding to use.  Specifically, every amount of
+	// padding in this (inclusive) range is checked.
 	padding util.Pair[uint, uint]
+	// Determines the protocol (if any) used to pad modules after the given
+	// amount of padding has been applied (e.g. upto a power of two).
+	paddingProtocol sc.PaddingProtocol
 	// Determines whether or not to enable debugging constraints
 	debug bool
 	// Suppress output (e.g. warnings)
@@ -108,71 +202,323 @@ type checkConfig struct {
 	// Specifies whether or not to report details of the failure (e.g. for
 	// debugging purposes).
 	report bool
+	// Specifies the format in which failures are reported.  This is either
+	// "text" (the default) or "json".
+	reportFormat string
+	// Specifies whether or not to explain failures by showing the evaluation of
+	// each subexpression of the failing constraint on the failing row(s).
+	explain bool
 	// Specifies the number of additional rows to show eitherside of the failing
 	// area. This essentially allows more contextual information to be shown.
 	reportPadding uint
 	// Specifies the width of a cell to show.
 	reportCellWidth uint
+	// Specifies the number of closest target rows to show for each source
+	// tuple missing from a failing lookup.
+	reportNearest uint
 	// Perform trace expansion in parallel (or not)
 	parallelExpansion bool
 	// Size of constraint batches to execute in parallel
 	batchSize uint
+	// Maximum number of failing rows to report for any given constraint (where
+	// zero indicates no limit).
+	maxFailures uint
+	// Maximum number of failing constraints to report overall, and likewise for
+	// failing assertions (where zero indicates no limit).  Each failing
+	// constraint counts once, regardless of how many failing rows it reports.
+	maxTotalFailures uint
 	// Enable ansi escape codes in reports
 	ansiEscapes bool
+	// Schema from which the source-level columns allocated to each register
+	// are recovered when reporting failures.  This may be nil.
+	sources *hir.Schema
+	// Restricts checking to constraints of the given modules, unless empty.
+	modules []string
+	// Restricts checking to constraints whose handles match this pattern,
+	// unless nil.
+	constraint *regexp.Regexp
+	// Restricts checking to the given (inclusive) window of rows in each
+	// module.
+	rows util.Pair[uint, uint]
+	// Maximum number of trace files to check concurrently.
+	jobs uint
+	// Name of the trace file being checked.  When checking several trace
+	// files, this is used to identify the trace in reports.
+	traceFile string
+	// Ensures reports for different trace files are not interleaved, when
+	// checking several trace files concurrently.  This may be nil.
+	output *sync.Mutex
+	// Known failures which should not be considered errors.  This may be nil.
+	baseline *FailureBaseline
+	// Records all failures when a baseline is being written.  This may be nil.
+	recording *FailureBaseline
+	// Handles of constraints which are expected to reject the trace(s).
+	expectFail []string
+	// Report disagreement between IR levels (i.e. where a trace is accepted at
+	// some level but rejected at another) as an error, and attribute it to the
+	// constraints responsible.
+	consistency bool
 }
 
-// Check a given trace is consistently accepted (or rejected) at the different
-// IR levels.
-func checkTraceWithLowering(cols []tr.RawColumn, schema *hir.Schema, cfg checkConfig) bool {
-	res := true
-	// Process individually
+// Schema lowered to a given IR level (e.g. HIR, MIR or AIR).
+type loweredSchema struct {
+	ir     string
+	schema sc.Schema
+}
+
+// Lower a given schema to each of the IR levels requested in the given
+// configuration.  Lowering is performed only once for each level.
+func lowerSchemas(hirSchema *hir.Schema, cfg checkConfig) []loweredSchema {
+	var (
+		schemas   []loweredSchema
+		mirSchema *mir.Schema
+	)
+	// Lower to MIR only when required
+	if cfg.mir || cfg.air {
+		mirSchema = hirSchema.LowerToMir()
+	}
+	//
 	if cfg.hir {
-		res = checkTrace("HIR", cols, schema, cfg)
+		schemas = append(schemas, loweredSchema{"HIR", hirSchema})
 	}
 
 	if cfg.mir {
-		res = checkTrace("MIR", cols, schema.LowerToMir(), cfg) && res
+		schemas = append(schemas, loweredSchema{"MIR", mirSchema})
 	}
 
 	if cfg.air {
-		res = checkTrace("AIR", cols, schema.LowerToMir().LowerToAir(), cfg) && res
+		schemas = append(schemas, loweredSchema{"AIR", mirSchema.LowerToAir()})
+	}
+	// Warn about insufficient spillage
+	for _, s := range schemas {
+		reportSpillageWarnings(s.ir, s.schema, cfg)
+	}
+	//
+	return schemas
+}
+
+// Check a set of trace files against a given set of (lowered) schemas.  Trace
+// files are checked concurrently, after which a summary of the outcome for
+// each trace file and IR level is printed.  This returns false if any trace
+// file is rejected (or could not be read) and, secondly, false if the outcomes
+// for any trace file diverged between IR levels.
+func checkTraceFiles(files []string, schemas []loweredSchema, cfg checkConfig) (bool, bool) {
+	var (
+		wg      sync.WaitGroup
+		results = make([][]bool, len(files))
+		errs    = make([]error, len(files))
+		// Bounds the number of trace files being checked concurrently
+		jobs = make(chan bool, cfg.jobs)
+	)
+	//
+	cfg.output = &sync.Mutex{}
+	//
+	for i, file := range files {
+		wg.Add(1)
+		//
+		go func(i int, cfg checkConfig) {
+			defer wg.Done()
+			//
+			jobs <- true
+			//
+			if columns, err := parseTraceFile(file); err != nil {
+				errs[i] = err
+			} else {
+				results[i] = checkTraceWithLowering(columns, schemas, cfg)
+			}
+			//
+			<-jobs
+		}(i, cfg.withTraceFile(file))
+	}
+	//
+	wg.Wait()
+	//
+	return printTraceSummary(files, schemas, results, errs, cfg)
+}
+
+// Print a summary table identifying which trace files were accepted (or
+// rejected) at each IR level, returning false if any were rejected.  When
+// checking consistency, an additional column identifies trace files whose
+// outcomes differ between IR levels, and false is (secondly) returned if any
+// did.  The table is not printed for machine-readable reports.
+func printTraceSummary(files []string, schemas []loweredSchema, results [][]bool, errs []error,
+	cfg checkConfig) (bool, bool) {
+	ok, consistent := true, true
+	consistency := cfg.consistency
+	width := uint(len(schemas) + 1)
+	//
+	if consistency {
+		width++
+	}
+	//
+	tbl := util.NewTablePrinter(width, uint(len(files)+1))
+	//
+	tbl.Set(0, 0, "Trace")
+	//
+	for j, s := range schemas {
+		tbl.Set(uint(j+1), 0, s.ir)
+	}
+	//
+	if consistency {
+		tbl.Set(width-1, 0, "Consistency")
 	}
+	//
+	for i, file := range files {
+		row := uint(i + 1)
+		tbl.Set(0, row, file)
+		//
+		for j := range schemas {
+			outcome := "pass"
+			//
+			if errs[i] != nil {
+				outcome = "error"
+			} else if !results[i][j] {
+				outcome = "fail"
+			}
+			//
+			ok = ok && outcome == "pass"
+			tbl.Set(uint(j+1), row, outcome)
+		}
+		//
+		if consistency && errs[i] == nil {
+			outcome := "ok"
+			//
+			if diverged(results[i]) {
+				outcome = "diverged"
+				consistent = false
+			}
+			//
+			tbl.Set(width-1, row, outcome)
+		}
+	}
+	//
+	if cfg.reportFormat != "json" {
+		tbl.Print()
+	}
+	// Report any trace files which could not be read
+	for i, err := range errs {
+		if err != nil {
+			log.Errorf("%s (%s)", err, files[i])
+		}
+	}
+	//
+	return ok, consistent
+}
+
+// Construct a copy of this configuration for checking a given trace file.
+func (cfg checkConfig) withTraceFile(file string) checkConfig {
+	cfg.traceFile = file
+	return cfg
+}
+
+// Run a given function which produces output.  When checking several trace files
+// concurrently, this ensures output for different trace files is not
+// interleaved.
+func (cfg checkConfig) synchronised(fn func()) {
+	if cfg.output != nil {
+		cfg.output.Lock()
+		defer cfg.output.Unlock()
+	}
+	//
+	fn()
+}
+
+// Determine the label used to identify a given IR level in reports.  When
+// checking several trace files, this also identifies the trace file.
+func (cfg checkConfig) label(ir string) string {
+	if cfg.output != nil {
+		return fmt.Sprintf("%s, %s", cfg.traceFile, ir)
+	}
+	//
+	return ir
+}
 
-	return res
+// Determine the trace file to identify in machine-readable reports, which is
+// empty unless several trace files are being checked.
+func (cfg checkConfig) batchTraceFile() string {
+	if cfg.output != nil {
+		return cfg.traceFile
+	}
+	//
+	return ""
+}
+
+// Check a given trace is consistently accepted (or rejected) at the different
+// IR levels, returning the outcome for each.
+func checkTraceWithLowering(cols []tr.RawColumn, schemas []loweredSchema, cfg checkConfig) []bool {
+	outcomes := make([]bool, len(schemas))
+	// Process individually
+	for i, s := range schemas {
+		outcomes[i] = checkTrace(s.ir, cols, s.schema, cfg)
+	}
+	// Check outcomes agree (if applicable)
+	if cfg.consistency {
+		checkConsistency(cols, schemas, outcomes, cfg)
+	}
+	//
+	return outcomes
 }
 
 func checkTrace(ir string, cols []tr.RawColumn, schema sc.Schema, cfg checkConfig) bool {
-	builder := sc.NewTraceBuilder(schema).Expand(cfg.expand).Parallel(cfg.parallelExpansion).BatchSize(cfg.batchSize)
+	// Determine which constraints to check and, hence, which columns to compute
+	filter := constraintFilter(schema, cfg)
+	builder := traceBuilderFor(schema, filter, cfg)
+	// Identify trace file in reports (if applicable)
+	label := cfg.label(ir)
 	//
 	for n := cfg.padding.Left; n <= cfg.padding.Right; n++ {
 		stats := util.NewPerfStats()
-		trace, errs := builder.Padding(n).Build(cols)
+		trace, errs := builder.PaddingProtocol(paddingProtocolFor(n, cfg)).Build(cols)
 		// Log cost of expansion
 		stats.Log("Expanding trace columns")
 		// Report any errors
-		reportErrors(cfg.strict, ir, errs)
+		cfg.synchronised(func() { reportErrors(cfg.strict, label, errs) })
 		// Check whether considered unrecoverable
 		if trace == nil || (cfg.strict && len(errs) > 0) {
 			return false
 		}
+		// Identify amount of padding when checking a range
+		if cfg.padding.Left != cfg.padding.Right {
+			cfg.synchronised(func() { log.Debugf("checking trace with padding %d (%s)", n, label) })
+		}
 		// Validate trace
 		stats = util.NewPerfStats()
 		//
 		if err := validationCheck(trace, schema); err != nil {
-			reportErrors(true, ir, []error{err})
+			cfg.synchronised(func() { reportValidationFailure(ir, err, cfg) })
 			return false
 		}
 		// Check trace
 		stats.Log("Validating trace")
 		stats = util.NewPerfStats()
 		// Check constraints
-		if errs := sc.Accepts(cfg.batchSize, schema, trace); len(errs) > 0 {
-			reportFailures(ir, errs, trace, cfg)
+		failures := sc.AcceptsSelected(cfg.batchSize, cfg.maxFailures, cfg.maxTotalFailures, filter, cfg.rows, schema,
+			trace)
+		// Separate out expected failures (e.g. those in the baseline)
+		expected, unexpected := partitionFailures(ir, failures, cfg)
+		// Check assertions (unless constraints failed unexpectedly)
+		if len(unexpected) == 0 {
+			asserts := sc.AssertsSelected(cfg.batchSize, cfg.maxFailures, cfg.maxTotalFailures, filter, cfg.rows,
+				schema, trace)
+			expectedAsserts, unexpectedAsserts := partitionFailures(ir, asserts, cfg)
+			//
+			failures = append(failures, asserts...)
+			expected = append(expected, expectedAsserts...)
+			unexpected = append(unexpected, unexpectedAsserts...)
+		}
+		// Check expected failures did arise
+		var ok bool
+		//
+		cfg.synchronised(func() { ok = checkExpectedFailures(ir, failures, cfg) })
+		//
+		if len(unexpected) > 0 {
+			reportRejection(ir, n, unexpected, trace, schema, cfg)
 			return false
+		} else if len(expected) > 0 && !cfg.quiet {
+			cfg.synchronised(func() { log.Infof("trace rejected by %d expected failure(s) (%s)", len(expected), label) })
 		}
-		// Check assertions
-		if errs := sc.Asserts(cfg.batchSize, schema, trace); len(errs) > 0 {
-			reportFailures(ir, errs, trace, cfg)
+		//
+		if !ok {
 			return false
 		}
 
@@ -182,20 +528,165 @@ func checkTrace(ir string, cols []tr.RawColumn, schema sc.Schema, cfg checkConfi
 	return true
 }
 
+// Construct a trace builder for a given schema according to the given
+// configuration, such that only columns required by the constraints selected by
+// a given filter are computed.
+func traceBuilderFor(schema sc.Schema, filter sc.ConstraintFilter, cfg checkConfig) sc.TraceBuilder {
+	builder := sc.NewTraceBuilder(schema).Expand(cfg.expand).Parallel(cfg.parallelExpansion).BatchSize(cfg.batchSize)
+	builder = builder.Spillage(cfg.spillage)
+	//
+	if filter != nil {
+		builder = builder.Columns(sc.SelectedColumns(schema, filter))
+	}
+	// Rows beyond the window being checked are only required when they can be
+	// accessed from within it.  One further row is kept so that the last row of
+	// a truncated module is never within the window.  Since padding protocols
+	// may depend on the height of a module, truncation is not possible in their
+	// presence.
+	if reach, ok := sc.SelectedReach(schema, filter); ok && cfg.expand && cfg.paddingProtocol == nil &&
+		cfg.rows.Right < math.MaxUint-reach-1 {
+		builder = builder.Truncate(cfg.rows.Right + reach + 2)
+	}
+	//
+	return builder
+}
+
+// Construct a filter which selects the constraints to be checked, according to
+// the given modules and constraint pattern.  This returns nil when all
+// constraints are to be checked.
+func constraintFilter(schema sc.Schema, cfg checkConfig) sc.ConstraintFilter {
+	var filters []sc.ConstraintFilter
+	//
+	if len(cfg.modules) > 0 {
+		filters = append(filters, sc.ModuleFilter(schema, cfg.modules))
+	}
+	//
+	if cfg.constraint != nil {
+		filters = append(filters, sc.NameFilter(cfg.constraint))
+	}
+	//
+	if len(filters) == 0 {
+		return nil
+	}
+	//
+	return sc.ConjunctFilter(filters...)
+}
+
+// Report any modules whose spillage has been overridden with less than the
+// inferred spillage, since this may lead to valid traces being rejected.
+func reportSpillageWarnings(ir string, schema sc.Schema, cfg checkConfig) {
+	if cfg.spillage != nil && cfg.expand && !cfg.quiet {
+		for _, warning := range sc.SpillageWarnings(schema, cfg.spillage) {
+			log.Warnf("%s (%s)", warning, ir)
+		}
+	}
+}
+
+// Report the failures for which a trace was rejected with a given amount of
+// padding.  When checking several trace files concurrently, this ensures
+// reports for different trace files are not interleaved.
+func reportRejection(ir string, n uint, failures []sc.Failure, trace tr.Trace, schema sc.Schema, cfg checkConfig) {
+	cfg.synchronised(func() {
+		reportPaddingFailure(cfg.label(ir), n, cfg)
+		reportFailures(ir, failures, trace, schema, cfg)
+	})
+}
+
+// Report the amount of padding with which a trace was rejected, when checking a
+// range of padding amounts (since otherwise this is already known).
+func reportPaddingFailure(ir string, n uint, cfg checkConfig) {
+	if cfg.padding.Left != cfg.padding.Right && !cfg.quiet {
+		log.Errorf("trace rejected with padding %d (%s)", n, ir)
+	}
+}
+
+// Determine the padding protocol to use for a given amount of padding.  That is,
+// a fixed amount of padding followed by the configured protocol (if any).
+func paddingProtocolFor(n uint, cfg checkConfig) sc.PaddingProtocol {
+	if cfg.paddingProtocol == nil {
+		return sc.FixedPadding(n)
+	}
+	//
+	return sc.SequentialPadding(sc.FixedPadding(n), cfg.paddingProtocol)
+}
+
+// Parse a padding protocol from a given set of specifications.  Each
+// specification is either "pow2" (i.e. pad each module upto the next power of
+// two), or "module=height" (i.e. pad the given module upto a fixed height).  The
+// root module is identified by an empty name (e.g. "=1024").  When no
+// specifications are given, no protocol is returned.
+func parsePaddingProtocol(specs []string) (sc.PaddingProtocol, error) {
+	var (
+		protocols []sc.PaddingProtocol
+		heights   map[string]uint = make(map[string]uint)
+	)
+	//
+	for _, spec := range specs {
+		if spec == "pow2" {
+			protocols = append(protocols, sc.PowerOfTwoPadding())
+		} else if split := strings.Split(spec, "="); len(split) != 2 {
+			return nil, fmt.Errorf("invalid padding protocol \"%s\"", spec)
+		} else if height, err := strconv.ParseUint(split[1], 10, 0); err != nil {
+			return nil, fmt.Errorf("invalid module height \"%s\"", spec)
+		} else {
+			heights[split[0]] = uint(height)
+		}
+	}
+	// Fixed heights are applied last, since they are exact.
+	if len(heights) > 0 {
+		protocols = append(protocols, sc.FixedHeightPadding(heights))
+	}
+	//
+	if len(protocols) == 0 {
+		return nil, nil
+	}
+	//
+	return sc.SequentialPadding(protocols...), nil
+}
+
+// Report a trace which failed validation, in the requested format.
+func reportValidationFailure(ir string, err *validationError, cfg checkConfig) {
+	if cfg.reportFormat == "json" {
+		reportValidationFailureAsJson(ir, cfg.batchTraceFile(), err)
+	} else {
+		reportErrors(true, cfg.label(ir), []error{err})
+	}
+}
+
+// Validation error identifies a cell of a trace holding a value which is not
+// within the type of its column.
+type validationError struct {
+	// Qualified name of the column
+	column string
+	// Row of the offending cell
+	row uint
+	// Value of the offending cell
+	value fr.Element
+}
+
+func (e *validationError) Error() string {
+	return fmt.Sprintf("row %d of column %s is out-of-bounds (%s)", e.row, e.column, e.value.String())
+}
+
 // Validate that values held in trace columns match the expected type.  This is
 // really a sanity check that the trace is not malformed.
-func validationCheck(tr tr.Trace, schema sc.Schema) error {
-	var err error
+func validationCheck(tr tr.Trace, schema sc.Schema) *validationError {
+	var err *validationError
 
 	schemaCols := schema.Columns()
 	// Construct a communication channel for errors.
-	c := make(chan error, tr.Width())
+	c := make(chan *validationError, tr.Width())
 	// Check each column in turn
 	for i := uint(0); i < tr.Width(); i++ {
 		// Extract ith column
 		col := tr.Column(i)
 		// Extract schema for ith column
 		scCol := schemaCols.Next()
+		// Skip columns which were not computed
+		if col.Data() == nil {
+			c <- nil
+			continue
+		}
 		// Determine enclosing module
 		mod := schema.Modules().Nth(scCol.Context.Module())
 		// Extract type for ith column
@@ -218,12 +709,12 @@ func validationCheck(tr tr.Trace, schema sc.Schema) error {
 }
 
 // Validate that all elements of a given column are within the given type.
-func validateColumn(colType sc.Type, col tr.Column, mod sc.Module) error {
+func validateColumn(colType sc.Type, col tr.Column, mod sc.Module) *validationError {
 	for j := 0; j < int(col.Data().Len()); j++ {
 		jth := col.Get(j)
 		if !colType.Accept(jth) {
 			qualColName := tr.QualifiedColumnName(mod.Name, col.Name())
-			return fmt.Errorf("row %d of column %s is out-of-bounds (%s)", j, qualColName, jth.String())
+			return &validationError{qualColName, uint(j), jth}
 		}
 	}
 	// success
@@ -231,30 +722,133 @@ func validateColumn(colType sc.Type, col tr.Column, mod sc.Module) error {
 }
 
 // Report constraint failures, whilst providing contextual information (when requested).
-func reportFailures(ir string, failures []sc.Failure, trace tr.Trace, cfg checkConfig) {
-	errs := make([]error, len(failures))
-	for i, f := range failures {
-		errs[i] = errors.New(f.Message())
-	}
-	// First, log errors
-	reportErrors(true, ir, errs)
-	// Second, produce report (if requested)
-	if cfg.report {
-		for _, f := range failures {
+func reportFailures(ir string, failures []sc.Failure, trace tr.Trace, schema sc.Schema, cfg checkConfig) {
+	// Machine-readable reports are handled separately
+	if cfg.reportFormat == "json" {
+		reportFailuresAsJson(ir, cfg.batchTraceFile(), failures, trace, cfg.sources)
+		return
+	}
+	// Identify trace file in reports (if applicable)
+	ir = cfg.label(ir)
+	//
+	for _, f := range failures {
+		// First, show source location (if known) or, otherwise, log error
+		if loc := sc.LocationOf(f); loc != nil {
+			printSourceLocation(*loc, fmt.Sprintf("%s (%s)", f.Message(), ir))
+			fmt.Println()
+		} else {
+			reportErrors(true, ir, []error{errors.New(f.Message())})
+		}
+		// Second, produce report (if requested)
+		if cfg.report {
 			reportFailure(f, trace, cfg)
 		}
+		// Third, explain failure (if requested)
+		if cfg.explain {
+			explainFailure(f, trace, schema)
+		}
 	}
 }
 
-// Print a human-readable report detailing the given failure
+// Print a human-readable explanation of the given failure, by showing how the
+// failing constraint evaluates on each failing row.
+func explainFailure(failure sc.Failure, trace tr.Trace, schema sc.Schema) {
+	var (
+		handle string
+		term   any
+		rows   []uint
+	)
+	//
+	switch f := failure.(type) {
+	case *constraint.VanishingFailure:
+		handle, term, rows = f.Handle, f.Constraint, f.Rows
+	case *constraint.RangeFailure:
+		handle, term, rows = f.Handle, f.Expr, f.Rows
+	case *sc.AssertionFailure:
+		handle, term, rows = f.Handle, f.Constraint, f.Rows
+	case *constraint.LookupFailure:
+		handle, term, rows = f.Handle, f, f.Rows
+	case *constraint.PermutationFailure:
+		// Permutations fail as a whole, rather than on specific rows
+		fmt.Printf("explaining %s:\n", f.Handle)
+		//
+		for _, e := range f.Explain(schema) {
+			for _, line := range e.Lines() {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		//
+		fmt.Println()
+	}
+	// Check whether term can be explained
+	if e, ok := term.(sc.Explainable); ok {
+		for _, row := range rows {
+			fmt.Printf("explaining %s (row %d):\n", handle, row)
+			//
+			for _, line := range e.ExplainAt(int(row), trace, schema).Lines() {
+				fmt.Printf("  %s\n", line)
+			}
+			//
+			fmt.Println()
+		}
+	}
+}
+
+// Print a human-readable report detailing the given failure.  When a failure
+// spans multiple rows, adjacent failing rows are grouped into windows and each
+// window is reported separately.
 func reportFailure(failure sc.Failure, trace tr.Trace, cfg checkConfig) {
 	if f, ok := failure.(*constraint.VanishingFailure); ok {
-		cells := f.RequiredCells(trace)
-		reportConstraintFailure("constraint", f.Handle, cells, trace, cfg)
+		for _, window := range failureWindows(f.Rows, cfg.reportPadding) {
+			wf := &constraint.VanishingFailure{Handle: f.Handle, Constraint: f.Constraint, Rows: window}
+			reportConstraintFailure("constraint", f.Handle, wf.RequiredCells(trace), trace, cfg)
+		}
+	} else if f, ok := failure.(*constraint.RangeFailure); ok {
+		for _, window := range failureWindows(f.Rows, cfg.reportPadding) {
+			wf := &constraint.RangeFailure{Handle: f.Handle, Expr: f.Expr, Rows: window}
+			reportConstraintFailure("range", f.Handle, wf.RequiredCells(trace), trace, cfg)
+		}
+	} else if f, ok := failure.(*constraint.LookupFailure); ok {
+		for _, window := range failureWindows(f.Rows, cfg.reportPadding) {
+			wf := &constraint.LookupFailure{Handle: f.Handle, Sources: f.Sources, Rows: window}
+			reportConstraintFailure("lookup", f.Handle, wf.RequiredCells(trace), trace, cfg)
+		}
+		// Report missing tuples
+		reportLookupTuples(f, trace, cfg)
+	} else if f, ok := failure.(*constraint.PermutationFailure); ok {
+		reportPermutationTuples(f, trace, cfg)
 	} else if f, ok := failure.(*sc.AssertionFailure); ok {
-		cells := f.RequiredCells(trace)
-		reportConstraintFailure("assertion", f.Handle, cells, trace, cfg)
+		for _, window := range failureWindows(f.Rows, cfg.reportPadding) {
+			wf := &sc.AssertionFailure{Handle: f.Handle, Constraint: f.Constraint, Rows: window}
+			reportConstraintFailure("assertion", f.Handle, wf.RequiredCells(trace), trace, cfg)
+		}
+	}
+}
+
+// Group a sorted set of failing rows into "windows" of adjacent rows.  Two rows
+// are considered adjacent when their reports (including the given amount of
+// padding) would overlap or touch.
+func failureWindows(rows []uint, padding uint) [][]uint {
+	var (
+		windows [][]uint
+		window  []uint
+	)
+	//
+	for _, row := range rows {
+		if len(window) > 0 && row-window[len(window)-1] > 2*padding+1 {
+			// Too far away, so start a new window
+			windows = append(windows, window)
+			window = nil
+		}
+		//
+		window = append(window, row)
 	}
+	// Append final window
+	if len(window) > 0 {
+		windows = append(windows, window)
+	}
+	//
+	return windows
 }
 
 // Print a human-readable report detailing the given failure with a vanishing constraint.
@@ -273,21 +867,9 @@ func reportConstraintFailure(kind string, handle string, cells *util.AnySortedSe
 	for _, c := range cells.ToArray() {
 		cols.Insert(c.Column)
 	}
-	// Construct & configure printer
-	tp := tr.NewPrinter().Start(start).End(end).MaxCellWidth(cfg.reportCellWidth).Padding(cfg.reportPadding)
-	// Determine whether to enable ANSI escapes (e.g. for colour in the terminal)
-	tp = tp.AnsiEscapes(cfg.ansiEscapes)
-	// Filter out columns not used in evaluating the constraint.
-	tp = tp.Columns(func(col uint, trace tr.Trace) bool {
-		return cols.Contains(col)
-	})
-	// Highlight failing cells
-	tp = tp.Highlight(func(cell tr.CellRef, trace tr.Trace) bool {
-		return cells.Contains(cell)
-	})
 	// Print out report
 	fmt.Printf("failing %s %s:\n", kind, handle)
-	tp.Print(trace)
+	printTraceWindow(start, end, cfg.reportPadding, cols, cells, trace, cfg)
 	fmt.Println()
 }
 
@@ -312,8 +894,11 @@ func reportErrors(error bool, ir string, errs []error) {
 func init() {
 	rootCmd.AddCommand(checkCmd)
 	checkCmd.Flags().Bool("report", false, "report details of failure for debugging")
+	checkCmd.Flags().String("report-format", "text", "specify format for reporting failures (text or json)")
+	checkCmd.Flags().Bool("explain", false, "explain failures by evaluating each subexpression on the failing row(s)")
 	checkCmd.Flags().Uint("report-context", 2, "specify number of rows to show eitherside of failure in report")
 	checkCmd.Flags().Uint("report-cellwidth", 32, "specify max number of bytes to show in a given cell in the report")
+	checkCmd.Flags().Uint("report-nearest", 3, "specify number of closest target rows to show for a failing lookup")
 	checkCmd.Flags().Bool("raw", false, "assume input trace already expanded")
 	checkCmd.Flags().Bool("hir", false, "check at HIR level")
 	checkCmd.Flags().Bool("mir", false, "check at MIR level")
@@ -324,9 +909,27 @@ func init() {
 	checkCmd.Flags().Bool("debug", false, "enable debugging constraints")
 	checkCmd.Flags().BoolP("quiet", "q", false, "suppress output (e.g. warnings)")
 	checkCmd.Flags().Bool("sequential", false, "perform sequential trace expansion")
-	checkCmd.Flags().Uint("padding", 0, "specify amount of (front) padding to apply")
+	checkCmd.Flags().String("padding", "0",
+		"specify amount of (front) padding to apply, or a range of amounts to check (e.g. 0..4)")
+	checkCmd.Flags().StringArray("pad-to", []string{},
+		"specify protocol for padding modules after padding applied (pow2 or module=height)")
 	checkCmd.Flags().UintP("batch", "b", math.MaxUint, "specify batch size for constraint checking")
-	checkCmd.Flags().Int("spillage", -1,
-		"specify amount of splillage to account for (where -1 indicates this should be inferred)")
+	checkCmd.Flags().Uint("max-failures", 1,
+		"specify max number of failing rows to report for each constraint (0 for no limit)")
+	checkCmd.Flags().Uint("max-total-failures", 0,
+		"specify max number of failing constraints to report overall, each with upto --max-failures rows, "+
+			"counting constraints and assertions separately (0 for no limit)")
+	checkCmd.Flags().StringArray("spillage", []string{},
+		"specify amount of spillage to apply, either globally (n) or per module (module=n), instead of inferring it (-1)")
+	checkCmd.Flags().StringArray("module", []string{}, "restrict checking to constraints of the given module(s)")
+	checkCmd.Flags().String("constraint", "", "restrict checking to constraints whose handles match a regular expression")
+	checkCmd.Flags().String("rows", "", "restrict checking to a window of rows in each module (e.g. 100..200)")
+	checkCmd.Flags().String("baseline", "",
+		"specify file of known failures, such that only new failures are errors (recorded if file does not exist)")
+	checkCmd.Flags().Bool("update-baseline", false, "record all failures in the baseline file, replacing its contents")
+	checkCmd.Flags().StringArray("expect-fail", []string{}, "specify handle of a constraint expected to reject the trace")
+	checkCmd.Flags().Bool("consistency", false,
+		"report (and explain) traces accepted at some IR levels but not others, exiting with status 3")
+	checkCmd.Flags().UintP("jobs", "j", uint(runtime.NumCPU()), "specify max number of trace files to check concurrently")
 	checkCmd.Flags().Bool("ansi-escapes", true, "specify whether to allow ANSI escapes or not (e.g. for colour reports)")
 }
@@ -0,0 +1,365 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// Identifies a constraint (or assertion) at a given IR level, where the level is
+// an index into the lowered schemas being checked.
+type leveledConstraint struct {
+	level     int
+	assertion bool
+	index     uint
+}
+
+// Determine the constraint (or assertion) identified at a given IR level.
+func (p leveledConstraint) constraint(schemas []loweredSchema) sc.Constraint {
+	if p.assertion {
+		return schemas[p.level].schema.Assertions().Nth(p.index)
+	}
+	//
+	return schemas[p.level].schema.Constraints().Nth(p.index)
+}
+
+// Check whether the outcomes of checking a trace at different IR levels agree.
+// If not, then the trace is accepted at some level(s) but rejected at others,
+// which indicates a bug in lowering.  In such case, the divergence is reported
+// (in the requested format) and attributed (where possible) to the constraints
+// responsible.
+func checkConsistency(cols []tr.RawColumn, schemas []loweredSchema, outcomes []bool, cfg checkConfig) {
+	var accepted, rejected []string
+	// Check whether outcomes agree
+	if !diverged(outcomes) {
+		return
+	}
+	//
+	for i, s := range schemas {
+		if outcomes[i] {
+			accepted = append(accepted, s.ir)
+		} else {
+			rejected = append(rejected, s.ir)
+		}
+	}
+	//
+	msg := fmt.Sprintf("inconsistent outcome: accepted at %s, but rejected at %s", strings.Join(accepted, ", "),
+		strings.Join(rejected, ", "))
+	// Ensure reports for different trace files are not interleaved
+	cfg.synchronised(func() {
+		if cfg.reportFormat == "json" {
+			reportDivergenceAsJson(rejected, cfg.batchTraceFile(), msg)
+			return
+		}
+		//
+		if cfg.output != nil {
+			msg = fmt.Sprintf("%s (%s)", msg, cfg.traceFile)
+		}
+		//
+		log.Error(msg)
+		explainInconsistency(cols, schemas, cfg)
+	})
+}
+
+// Determine whether the outcomes of checking a trace at different IR levels
+// diverge (i.e. it is accepted at some level but rejected at another).
+func diverged(outcomes []bool) bool {
+	return slices.Contains(outcomes, true) && slices.Contains(outcomes, false)
+}
+
+// Explain why the outcomes of checking a trace at different IR levels disagree.
+// This identifies the first amount of padding for which they disagree and, for
+// each constraint (or assertion) failing at some level, evaluates those it was
+// lowered from (or to) at the other levels on the same row.
+func explainInconsistency(cols []tr.RawColumn, schemas []loweredSchema, cfg checkConfig) {
+	lineage := newLineage(schemas)
+	//
+	for n := cfg.padding.Left; n <= cfg.padding.Right; n++ {
+		var (
+			traces   = make([]tr.Trace, len(schemas))
+			failures = make([][]leveledConstraint, len(schemas))
+			causes   = make(map[leveledConstraint]sc.Failure)
+			nfailing = 0
+		)
+		//
+		for i, s := range schemas {
+			builder := traceBuilderFor(s.schema, constraintFilter(s.schema, cfg), cfg)
+			trace, _ := builder.PaddingProtocol(paddingProtocolFor(n, cfg)).Build(cols)
+			// Check whether trace could be built
+			if trace == nil {
+				fmt.Printf("[%s] trace could not be expanded (padding %d)\n", s.ir, n)
+				return
+			}
+			//
+			traces[i] = trace
+			failures[i] = failingConstraints(i, false, s.schema, trace, cfg, causes)
+			failures[i] = append(failures[i], failingConstraints(i, true, s.schema, trace, cfg, causes)...)
+			//
+			if len(failures[i]) > 0 {
+				nfailing++
+			}
+		}
+		// Check whether levels disagree with this amount of padding
+		if nfailing == 0 || nfailing == len(schemas) {
+			continue
+		}
+		//
+		for i := range schemas {
+			for _, c := range failures[i] {
+				explainDivergence(c, causes[c], lineage, schemas, traces)
+			}
+		}
+		//
+		return
+	}
+	//
+	fmt.Println("no diverging constraint found (e.g. rejection arose from trace expansion)")
+}
+
+// Determine the constraints (or assertions) of a given schema which reject a
+// given trace, recording the failure arising for each.
+func failingConstraints(level int, assertions bool, schema sc.Schema, trace tr.Trace, cfg checkConfig,
+	causes map[leveledConstraint]sc.Failure) []leveledConstraint {
+	var (
+		failing []leveledConstraint
+		filter  = constraintFilter(schema, cfg)
+		iter    = schema.Constraints()
+	)
+	//
+	if assertions {
+		iter = schema.Assertions()
+	}
+	//
+	for i := uint(0); iter.HasNext(); i++ {
+		ith := iter.Next()
+		//
+		if filter != nil && !filter(ith) {
+			continue
+		}
+		//
+		var failure sc.Failure
+		//
+		if w, ok := ith.(sc.Windowed); ok {
+			failure = w.AcceptsWithin(trace, cfg.rows, 1)
+		} else {
+			failure = ith.Accepts(trace, 1)
+		}
+		//
+		if failure != nil {
+			c := leveledConstraint{level, assertions, i}
+			failing = append(failing, c)
+			causes[c] = failure
+		}
+	}
+	//
+	return failing
+}
+
+// Explain the divergence arising from a given failing constraint, by evaluating
+// each of its relatives (i.e. the constraints it was lowered from, or lowered
+// to) on the failing row.
+func explainDivergence(c leveledConstraint, failure sc.Failure, lineage lineage, schemas []loweredSchema,
+	traces []tr.Trace) {
+	schema := schemas[c.level].schema
+	ith := c.constraint(schemas)
+	row, hasRow := failureRow(failure)
+	//
+	if hasRow {
+		fmt.Printf("[%s] %s fails on row %d\n", schemas[c.level].ir, sc.OriginOf(ith, schema), row)
+	} else {
+		fmt.Printf("[%s] %s fails\n", schemas[c.level].ir, sc.OriginOf(ith, schema))
+	}
+	//
+	for _, r := range lineage.relativesOf(c) {
+		relation := "lowered to"
+		rschema := schemas[r.level].schema
+		rth := r.constraint(schemas)
+		//
+		if r.level < c.level {
+			relation = "lowered from"
+		}
+		//
+		outcome := "holds"
+		// Map failing row into trace at this level
+		if hasRow {
+			if rrow, ok := mapRow(row, ith, schema, traces[c.level], rth, rschema, traces[r.level]); !ok {
+				outcome = "cannot be evaluated on corresponding row"
+			} else if !holdsOn(rth, traces[r.level], rrow) {
+				outcome = fmt.Sprintf("fails on row %d", rrow)
+			} else {
+				outcome = fmt.Sprintf("holds on row %d", rrow)
+			}
+		} else if rth.Accepts(traces[r.level], 1) != nil {
+			outcome = "fails"
+		}
+		//
+		fmt.Printf("\t%s [%s] %s which %s\n", relation, schemas[r.level].ir, sc.OriginOf(rth, rschema), outcome)
+	}
+	//
+	fmt.Println()
+}
+
+// Records how the constraints (and assertions) at each IR level were lowered
+// from those at the level above.  This is determined once for a given set of
+// lowered schemas, such that the relatives of a constraint can be found without
+// rescanning every schema.
+type lineage struct {
+	// Constraint from which a given constraint was lowered (if known).
+	parents map[leveledConstraint]leveledConstraint
+	// Constraints lowered from a given constraint.
+	children map[leveledConstraint][]leveledConstraint
+}
+
+// Determine the lineage of the constraints of a given set of lowered schemas.
+// Constraints are related using the provenance recorded during lowering.
+// Assertions are not attributed, but retain their handle (and module) when
+// lowered and are related using these instead.
+func newLineage(schemas []loweredSchema) lineage {
+	lineage := lineage{make(map[leveledConstraint]leveledConstraint), make(map[leveledConstraint][]leveledConstraint)}
+	//
+	for level, s := range schemas {
+		if attributed, ok := s.schema.(sc.Attributed); ok {
+			for i := uint(0); i < s.schema.Constraints().Count(); i++ {
+				provenance := attributed.ConstraintProvenance(i)
+				index, ok := provenance.OriginConstraint()
+				parent := slices.IndexFunc(schemas, func(s loweredSchema) bool { return s.ir == provenance.IR })
+				//
+				if ok && parent >= 0 {
+					lineage.link(leveledConstraint{parent, false, index}, leveledConstraint{level, false, i})
+				}
+			}
+		}
+		//
+		if level > 0 {
+			lineage.linkAssertions(level-1, level, schemas)
+		}
+	}
+	//
+	return lineage
+}
+
+// Relate each assertion at one IR level to the assertion at the level above
+// with the same handle and module(s).
+func (p *lineage) linkAssertions(from int, to int, schemas []loweredSchema) {
+	parents := make(map[string]uint)
+	//
+	for i, iter := uint(0), schemas[from].schema.Assertions(); iter.HasNext(); i++ {
+		if key, ok := assertionKey(iter.Next(), schemas[from].schema); ok {
+			if _, seen := parents[key]; !seen {
+				parents[key] = i
+			}
+		}
+	}
+	//
+	for i, iter := uint(0), schemas[to].schema.Assertions(); iter.HasNext(); i++ {
+		if key, ok := assertionKey(iter.Next(), schemas[to].schema); !ok {
+			continue
+		} else if index, ok := parents[key]; ok {
+			p.link(leveledConstraint{from, true, index}, leveledConstraint{to, true, i})
+		}
+	}
+}
+
+// Record that a given child was lowered from a given parent.
+func (p *lineage) link(parent leveledConstraint, child leveledConstraint) {
+	p.parents[child] = parent
+	p.children[parent] = append(p.children[parent], child)
+}
+
+// Determine the relatives of a given constraint at the other IR levels.  That
+// is, the constraints from which it was (transitively) lowered, along with
+// those which were (transitively) lowered from it.
+func (p *lineage) relativesOf(c leveledConstraint) []leveledConstraint {
+	var relatives []leveledConstraint
+	// Ancestors
+	for parent, ok := p.parents[c]; ok; parent, ok = p.parents[parent] {
+		relatives = append(relatives, parent)
+	}
+	// Descendants
+	for worklist := p.children[c]; len(worklist) > 0; worklist = worklist[1:] {
+		relatives = append(relatives, worklist[0])
+		worklist = append(worklist, p.children[worklist[0]]...)
+	}
+	//
+	return relatives
+}
+
+// Determine the key relating an assertion to those at other IR levels (i.e. its
+// handle and module(s)), or false if it has none.
+func assertionKey(assertion sc.Constraint, schema sc.Schema) (string, bool) {
+	if s, ok := assertion.(sc.Selectable); ok {
+		return fmt.Sprintf("%s@%v", s.Name(), s.Modules(schema)), true
+	}
+	//
+	return "", false
+}
+
+// Map a row of the trace at one IR level to the corresponding row of the trace
+// at another.  Since the spillage inferred at different levels can differ, the
+// heights of the enclosing module can also differ.  Since spillage is applied
+// at the front of a module, rows are aligned at the end of the module.
+func mapRow(row uint, from sc.Constraint, fromSchema sc.Schema, fromTrace tr.Trace, to sc.Constraint,
+	toSchema sc.Schema, toTrace tr.Trace) (uint, bool) {
+	fromHeight, fromOk := moduleHeight(from, fromSchema, fromTrace)
+	toHeight, toOk := moduleHeight(to, toSchema, toTrace)
+	//
+	if !fromOk || !toOk {
+		return row, true
+	} else if mapped := int(row) + int(toHeight) - int(fromHeight); mapped >= 0 {
+		return uint(mapped), true
+	}
+	//
+	return 0, false
+}
+
+// Determine the height of the (first) module constrained by a given constraint
+// in a given trace, or false if this is unknown.
+func moduleHeight(c sc.Constraint, schema sc.Schema, trace tr.Trace) (uint, bool) {
+	if s, ok := c.(sc.Selectable); ok {
+		if mods := s.Modules(schema); len(mods) > 0 {
+			return trace.Modules().Nth(mods[0]).Height(), true
+		}
+	}
+	//
+	return 0, false
+}
+
+// Check whether a given constraint holds on a given row of a given trace.
+// Constraints which cannot be checked on individual rows are checked as a
+// whole.
+func holdsOn(c sc.Constraint, trace tr.Trace, row uint) bool {
+	if w, ok := c.(sc.Windowed); ok {
+		return w.AcceptsWithin(trace, util.NewPair(row, row), 1) == nil
+	}
+	//
+	return c.Accepts(trace, 1) == nil
+}
+
+// Determine the first row on which a given failure arose, or false if the
+// failure does not identify any row (e.g. a failing permutation).
+func failureRow(failure sc.Failure) (uint, bool) {
+	var rows []uint
+	//
+	switch f := failure.(type) {
+	case *constraint.VanishingFailure:
+		rows = f.Rows
+	case *constraint.RangeFailure:
+		rows = f.Rows
+	case *constraint.LookupFailure:
+		rows = f.Rows
+	case *sc.AssertionFailure:
+		rows = f.Rows
+	}
+	//
+	if len(rows) == 0 {
+		return 0, false
+	}
+	//
+	return rows[0], true
+}
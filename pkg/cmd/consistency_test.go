@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/corset"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+// ============================================================================
+// Lineage
+// ============================================================================
+
+// Constraint whose lowering introduces an inverse column, alongside a property
+// and a constraint on another module.
+const lineageSource = `(defpurefun ((vanish :@loob) x) x)
+(defcolumns X Y)
+(defconstraint c () (vanish (* Y (- 1 (~ X)))))
+(defproperty p (vanish (* X Y)))
+(module m)
+(defcolumns Z)
+(defproperty p (vanish Z))`
+
+func Test_Lineage_01(t *testing.T) {
+	// Constraints are related at each level via their provenance.
+	schemas, lineage := lineageOf(t)
+	c := checkRelatives(t, schemas, lineage, leveledConstraint{0, false, 0}, 1, 2)
+	// Relatives are related in both directions
+	for _, r := range c {
+		if !slices.Contains(lineage.relativesOf(r), leveledConstraint{0, false, 0}) {
+			t.Errorf("expected %v to be related to HIR constraint", r)
+		}
+	}
+}
+
+func Test_Lineage_02(t *testing.T) {
+	// Assertions are related at each level via their handle and module.
+	schemas, lineage := lineageOf(t)
+	//
+	for i := uint(0); i < schemas[0].schema.Assertions().Count(); i++ {
+		checkRelatives(t, schemas, lineage, leveledConstraint{0, true, i}, 1, 2)
+	}
+}
+
+func Test_Lineage_03(t *testing.T) {
+	// Assertions with the same handle in different modules are not related.
+	schemas, lineage := lineageOf(t)
+	//
+	for i := uint(0); i < schemas[0].schema.Assertions().Count(); i++ {
+		c := leveledConstraint{0, true, i}
+		key, _ := assertionKey(c.constraint(schemas), schemas[0].schema)
+		//
+		for _, r := range lineage.relativesOf(c) {
+			if rkey, _ := assertionKey(r.constraint(schemas), schemas[r.level].schema); rkey != key {
+				t.Errorf("assertion %s related to assertion %s", key, rkey)
+			}
+		}
+	}
+}
+
+func Test_FailureRow_01(t *testing.T) {
+	// Assertion failures identify the failing row.
+	if row, ok := failureRow(&sc.AssertionFailure{Handle: "p", Rows: []uint{3, 4}}); !ok || row != 3 {
+		t.Errorf("expected failing row 3, got %d (%t)", row, ok)
+	}
+}
+
+// Compile the lineage source and determine the lineage of its constraints when
+// lowered to every level.
+func lineageOf(t *testing.T) ([]loweredSchema, lineage) {
+	hirSchema, errs := corset.CompileSourceFile(false, false, sexp.NewSourceFile("test.lisp", []byte(lineageSource)))
+	//
+	if len(errs) > 0 {
+		t.Fatalf("error compiling constraints: %v", errs)
+	}
+	//
+	schemas := lowerSchemas(hirSchema, checkConfig{hir: true, mir: true, air: true})
+	//
+	return schemas, newLineage(schemas)
+}
+
+// Check a given constraint has relatives at (exactly) the given levels, each
+// of the same kind.
+func checkRelatives(t *testing.T, schemas []loweredSchema, lineage lineage, c leveledConstraint,
+	levels ...int) []leveledConstraint {
+	relatives := lineage.relativesOf(c)
+	//
+	for _, level := range levels {
+		if !slices.ContainsFunc(relatives, func(r leveledConstraint) bool { return r.level == level }) {
+			t.Errorf("expected relative of %s at %s", sc.OriginOf(c.constraint(schemas), schemas[c.level].schema),
+				schemas[level].ir)
+		}
+	}
+	//
+	for _, r := range relatives {
+		if r.assertion != c.assertion || r.level == c.level {
+			t.Errorf("unexpected relative %v of %v", r, c)
+		}
+	}
+	//
+	return relatives
+}
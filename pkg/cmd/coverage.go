@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [flags] trace_file(s) constraint_file",
+	Short: "report how thoroughly a set of traces exercises a set of constraints.",
+	Long: `Check a set of traces against a set of constraints and report, for
+	each vanishing constraint, the number of rows on which it was live
+	(i.e. required something to vanish) versus vacuously satisfied.  Any
+	constraints, or branches of conditionals, which were never exercised
+	are also reported.  Traces may be given as glob patterns or
+	directories.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		// Configure log level
+		if GetFlag(cmd, "verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+		//
+		stdlib := !GetFlag(cmd, "no-stdlib")
+		debug := GetFlag(cmd, "debug")
+		legacy := GetFlag(cmd, "legacy")
+		format := GetString(cmd, "format")
+		// Sanity check format
+		if format != "text" && format != "json" {
+			fmt.Printf("unknown format \"%s\"\n", format)
+			os.Exit(2)
+		}
+		// Parse constraints
+		hirSchema := readSchema(stdlib, debug, legacy, args[len(args)-1:])
+		// Determine trace files
+		files, err := expandTraceFiles(args[:len(args)-1])
+		//
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		// Go!
+		constraints, coverage, ntraces := measureCoverage(hirSchema, files)
+		//
+		if format == "json" {
+			printCoverageAsJson(hirSchema, ntraces, constraints, coverage)
+		} else {
+			printCoverage(hirSchema, constraints, coverage)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+	coverageCmd.Flags().Bool("debug", false, "enable debugging constraints")
+	coverageCmd.Flags().String("format", "text", "specify format of coverage report (text or json)")
+}
+
+// CoverageRecord is a machine-readable summary of the coverage of a single
+// vanishing constraint.
+type CoverageRecord struct {
+	// Module containing the constraint.
+	Module string `json:"module"`
+	// Handle of the constraint.
+	Handle string `json:"handle"`
+	// Number of rows on which the constraint was live.
+	Live uint `json:"live"`
+	// Number of rows on which the constraint was vacuously satisfied.
+	Vacuous uint `json:"vacuous"`
+	// Coverage of each branch of each conditional within the constraint.
+	Branches []BranchRecord `json:"branches"`
+}
+
+// BranchRecord is a machine-readable summary of the coverage of a single branch
+// of a conditional.
+type BranchRecord struct {
+	// Condition of the enclosing conditional.
+	Condition string `json:"condition"`
+	// Identifies the branch, which is either "zero" (i.e. taken when the
+	// condition is zero) or "nonzero".
+	Branch string `json:"branch"`
+	// Number of rows on which this branch was taken.
+	Rows uint `json:"rows"`
+}
+
+// CoverageReport is a machine-readable summary of the coverage of all vanishing
+// constraints by a set of traces.
+type CoverageReport struct {
+	// Number of traces considered (i.e. those accepted).
+	Traces uint `json:"traces"`
+	// Coverage of each vanishing constraint.
+	Constraints []CoverageRecord `json:"constraints"`
+}
+
+// Measure the coverage of every vanishing constraint in a given schema over a
+// given set of trace files, returning also the number of trace files
+// considered.  Trace files which cannot be read (or expanded), or which are
+// rejected, are reported and otherwise ignored.  Only rows given in the trace
+// files are considered (i.e. not those added as spillage or padding).
+func measureCoverage(schema *hir.Schema, files []string) ([]hir.VanishingConstraint, []*hir.Coverage, uint) {
+	var (
+		constraints []hir.VanishingConstraint
+		coverage    []*hir.Coverage
+		ntraces     uint
+	)
+	// Identify vanishing constraints
+	for iter := schema.Constraints(); iter.HasNext(); {
+		if c, ok := iter.Next().(hir.VanishingConstraint); ok {
+			constraints = append(constraints, c)
+			coverage = append(coverage, hir.NewCoverage(c))
+		}
+	}
+	//
+	builder := sc.NewTraceBuilder(schema)
+	//
+	for _, file := range files {
+		columns, err := parseTraceFile(file)
+		//
+		if err != nil {
+			log.Errorf("%s (%s)", err, file)
+			continue
+		}
+		//
+		trace, errs := builder.Build(columns)
+		//
+		if trace == nil {
+			reportErrors(true, file, errs)
+			continue
+		} else if len(sc.Accepts(math.MaxUint, schema, trace)) > 0 {
+			log.Warnf("trace rejected, hence ignored (%s)", file)
+			continue
+		}
+		//
+		padding := paddingRows(schema, columns, trace)
+		//
+		for i, c := range constraints {
+			coverage[i].Record(c, trace, padding[c.Context.Module()])
+		}
+		//
+		ntraces++
+	}
+	//
+	return constraints, coverage, ntraces
+}
+
+// Determine the number of rows at the front of each module of an expanded trace
+// which were added during expansion (i.e. spillage and padding), rather than
+// given in the original columns.  Rows are measured before any length
+// multiplier is applied, hence the length of each column is normalised by its
+// multiplier.
+func paddingRows(schema sc.Schema, columns []tr.RawColumn, trace tr.Trace) []uint {
+	heights := make(map[string]uint)
+	multipliers := make(map[string]uint)
+	padding := make([]uint, trace.Modules().Count())
+	//
+	for iter := schema.Columns(); iter.HasNext(); {
+		col := iter.Next()
+		mod := schema.Modules().Nth(col.Context.Module()).Name
+		multipliers[tr.QualifiedColumnName(mod, col.Name)] = col.Context.LengthMultiplier()
+	}
+	//
+	for _, col := range columns {
+		height := col.Data.Len()
+		//
+		if multiplier := multipliers[col.QualifiedName()]; multiplier > 1 {
+			height = height / multiplier
+		}
+		//
+		heights[col.Module] = max(heights[col.Module], height)
+	}
+	//
+	for i := range padding {
+		module := trace.Modules().Nth(uint(i))
+		padding[i] = module.Height() - min(module.Height(), heights[module.Name()])
+	}
+	//
+	return padding
+}
+
+// Print a human-readable coverage report, consisting of a summary table
+// followed by the constraints and branches which were never exercised.
+func printCoverage(schema *hir.Schema, constraints []hir.VanishingConstraint, coverage []*hir.Coverage) {
+	tbl := util.NewTablePrinter(5, uint(len(constraints)+1))
+	tbl.SetRow(0, "Module", "Constraint", "Live", "Vacuous", "Branches")
+	//
+	for i, c := range constraints {
+		cov := coverage[i]
+		module := schema.Modules().Nth(c.Context.Module()).Name
+		taken, total := branchesTaken(cov)
+		//
+		tbl.SetRow(uint(i+1), module, c.Handle, fmt.Sprintf("%d", cov.Live), fmt.Sprintf("%d", cov.Vacuous),
+			fmt.Sprintf("%d/%d", taken, total))
+	}
+	//
+	tbl.Print()
+	// Report anything unexercised
+	for i, c := range constraints {
+		if coverage[i].Live == 0 {
+			fmt.Printf("constraint \"%s\" never exercised\n", c.Handle)
+		}
+		//
+		for _, b := range toBranchRecords(schema, coverage[i]) {
+			if b.Rows == 0 {
+				fmt.Printf("%s branch of (if %s ...) in constraint \"%s\" never exercised\n", b.Branch, b.Condition,
+					c.Handle)
+			}
+		}
+	}
+}
+
+// Print a machine-readable coverage report as a single JSON object.
+func printCoverageAsJson(schema *hir.Schema, ntraces uint, constraints []hir.VanishingConstraint,
+	coverage []*hir.Coverage) {
+	report := CoverageReport{ntraces, make([]CoverageRecord, len(constraints))}
+	//
+	for i, c := range constraints {
+		module := schema.Modules().Nth(c.Context.Module()).Name
+		branches := toBranchRecords(schema, coverage[i])
+		report.Constraints[i] = CoverageRecord{module, c.Handle, coverage[i].Live, coverage[i].Vacuous, branches}
+	}
+	// Encode report
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	//
+	if err != nil {
+		fmt.Printf("cannot encode coverage report (%s)\n", err)
+		os.Exit(2)
+	}
+	//
+	fmt.Println(string(bytes))
+}
+
+// Construct branch records for every branch of every conditional within a
+// constraint.  Missing branches (e.g. the false branch of a conditional with
+// only a true branch) are not included, since they cannot be exercised.
+func toBranchRecords(schema *hir.Schema, coverage *hir.Coverage) []BranchRecord {
+	branches := make([]BranchRecord, 0)
+	//
+	for i, e := range coverage.Conditionals {
+		condition := e.Condition.Lisp(schema).String(false)
+		//
+		if e.TrueBranch != nil {
+			branches = append(branches, BranchRecord{condition, "zero", coverage.TrueBranch[i]})
+		}
+		//
+		if e.FalseBranch != nil {
+			branches = append(branches, BranchRecord{condition, "nonzero", coverage.FalseBranch[i]})
+		}
+	}
+	//
+	return branches
+}
+
+// Determine how many branches of the conditionals within a constraint were
+// taken, along with the total number of branches.
+func branchesTaken(coverage *hir.Coverage) (uint, uint) {
+	var taken, total uint
+	//
+	for i, e := range coverage.Conditionals {
+		if e.TrueBranch != nil {
+			total++
+			//
+			if coverage.TrueBranch[i] > 0 {
+				taken++
+			}
+		}
+		//
+		if e.FalseBranch != nil {
+			total++
+			//
+			if coverage.FalseBranch[i] > 0 {
+				taken++
+			}
+		}
+	}
+	//
+	return taken, total
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/air"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ============================================================================
+// Padding Rows
+// ============================================================================
+
+func Test_PaddingRows_01(t *testing.T) {
+	// One row of spillage is added to each module, where Y has a length
+	// multiplier of 2.
+	checkPaddingRows(t, 0, []uint{1, 1})
+}
+
+func Test_PaddingRows_02(t *testing.T) {
+	checkPaddingRows(t, 2, []uint{3, 3})
+}
+
+// Check the padding rows determined for a trace with two rows in each module
+// (i.e. X holds two values, and Y four), built with a given amount of padding.
+func checkPaddingRows(t *testing.T, padding uint, expected []uint) {
+	schema := air.EmptySchema[air.Expr]()
+	root := trace.NewContext(schema.AddModule(""), 1)
+	m := trace.NewContext(schema.AddModule("m"), 2)
+	//
+	schema.AddColumn(root, "X", sc.NewUintType(8), sc.DISPLAY_DEFAULT, fr.NewElement(0))
+	schema.AddColumn(m, "Y", sc.NewUintType(8), sc.DISPLAY_DEFAULT, fr.NewElement(0))
+	//
+	columns := []trace.RawColumn{{Module: "", Name: "X", Data: rawData(1, 2)},
+		{Module: "m", Name: "Y", Data: rawData(1, 2, 3, 4)}}
+	tr, errs := sc.NewTraceBuilder(schema).Padding(padding).Build(columns)
+	//
+	if tr == nil || len(errs) > 0 {
+		t.Fatalf("error building trace: %v", errs)
+	}
+	//
+	if actual := paddingRows(schema, columns, tr); !slices.Equal(actual, expected) {
+		t.Errorf("expected padding rows %v, got %v", expected, actual)
+	}
+}
+
+func rawData(values ...uint64) util.FrArray {
+	data := util.NewFrArray(uint(len(values)), 8)
+	//
+	for i, v := range values {
+		data.Set(uint(i), fr.NewElement(v))
+	}
+	//
+	return data
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/consensys/go-corset/pkg/hir"
@@ -37,13 +39,18 @@ var debugCmd = &cobra.Command{
 		stdlib := !GetFlag(cmd, "no-stdlib")
 		debug := GetFlag(cmd, "debug")
 		legacy := GetFlag(cmd, "legacy")
+		sources := GetFlag(cmd, "sources")
+		provenance := GetFlag(cmd, "provenance")
+		spillage := GetFlag(cmd, "spillage")
 		// Parse constraints
 		hirSchema := readSchema(stdlib, debug, legacy, args)
 		// Print constraints
 		if stats {
 			printStats(hirSchema, hir, mir, air)
+		} else if spillage {
+			printSpillage(hirSchema, hir, mir, air)
 		} else {
-			printSchemas(hirSchema, hir, mir, air)
+			printSchemas(hirSchema, hir, mir, air, sources, provenance)
 		}
 	},
 }
@@ -55,35 +62,143 @@ func init() {
 	debugCmd.Flags().Bool("air", false, "Print constraints at AIR level")
 	debugCmd.Flags().Bool("stats", false, "Print summary information")
 	debugCmd.Flags().Bool("debug", false, "enable debugging constraints")
+	debugCmd.Flags().Bool("sources", false, "show source-level columns allocated to each register")
+	debugCmd.Flags().Bool("provenance", false, "show the item and gadget from which each lowered item originated")
+	debugCmd.Flags().Bool("spillage", false, "show the required spillage of each module, and the assignment requiring it")
 }
 
-func printSchemas(hirSchema *hir.Schema, hir bool, mir bool, air bool) {
+func printSchemas(hirSchema *hir.Schema, hir bool, mir bool, air bool, sources bool, provenance bool) {
 	mirSchema := hirSchema.LowerToMir()
 	airSchema := mirSchema.LowerToAir()
+	srcSchema := hirSchema
+	// Determine whether to show sources
+	if !sources {
+		srcSchema = nil
+	}
 
 	if hir {
-		printSchema(hirSchema)
+		printSchema(hirSchema, srcSchema, provenance)
 	}
 
 	if mir {
-		printSchema(mirSchema)
+		printSchema(mirSchema, srcSchema, provenance)
 	}
 
 	if air {
-		printSchema(airSchema)
+		printSchema(airSchema, srcSchema, provenance)
 	}
 }
 
-// Print out all declarations included in a given
-func printSchema(schema schema.Schema) {
-	for i := schema.Declarations(); i.HasNext(); {
+// Print out all declarations included in a given schema.  When a source schema
+// is given, the source-level columns allocated to each register are also shown.
+// Likewise, when provenance is requested, the item (and gadget) from which each
+// lowered item originated is shown along with a summary of the items arising
+// from each origin.
+func printSchema(schema schema.Schema, sources *hir.Schema, provenance bool) {
+	column := uint(0)
+	summary := newProvenanceSummary()
+	// Determine number of input declarations
+	ninputs := schema.Declarations().Count() - schema.Assignments().Count()
+	//
+	for i, index := schema.Declarations(), uint(0); i.HasNext(); index++ {
 		ith := i.Next()
 		fmt.Println(ith.Lisp(schema).String(true))
+		// Print provenance (if applicable)
+		if attributed, ok := schema.(sc.Attributed); ok && provenance && index >= ninputs {
+			origin := attributed.AssignmentProvenance(index - ninputs)
+			printProvenance(origin)
+			summary.add(origin, ith.Columns().Count(), 0)
+		}
+		//
+		for c := ith.Columns(); c.HasNext(); {
+			c.Next()
+			//
+			if sources != nil {
+				printRegisterSources(column, schema, sources)
+			}
+			//
+			column++
+		}
 	}
 
-	for i := schema.Constraints(); i.HasNext(); {
+	for i, index := schema.Constraints(), uint(0); i.HasNext(); index++ {
 		ith := i.Next()
 		fmt.Println(ith.Lisp(schema).String(true))
+		// Print provenance (if applicable)
+		if attributed, ok := schema.(sc.Attributed); ok && provenance {
+			origin := attributed.ConstraintProvenance(index)
+			printProvenance(origin)
+			summary.add(origin, 0, 1)
+		}
+	}
+	// Print provenance summary (if applicable)
+	if _, ok := schema.(sc.Attributed); ok && provenance {
+		summary.print()
+	}
+}
+
+// Print the provenance of a given item (if known).
+func printProvenance(provenance sc.Provenance) {
+	if !provenance.IsEmpty() {
+		fmt.Printf(";; %s\n", provenance.String())
+	}
+}
+
+// ProvenanceSummary counts the number of columns and constraints arising from
+// each (ultimately) originating item.  This helps attribute the size of a lowered schema to
+// the items from which it originated.
+type provenanceSummary struct {
+	origins     []string
+	columns     map[string]uint
+	constraints map[string]uint
+}
+
+func newProvenanceSummary() *provenanceSummary {
+	return &provenanceSummary{nil, make(map[string]uint), make(map[string]uint)}
+}
+
+// Attribute a number of columns and constraints to the item from which a given
+// provenance ultimately arose (e.g. an AIR item is attributed to an HIR item).
+func (p *provenanceSummary) add(provenance sc.Provenance, columns uint, constraints uint) {
+	origin := provenance.Root().Origin
+	//
+	if origin == "" {
+		return
+	} else if _, ok := p.columns[origin]; !ok {
+		p.origins = append(p.origins, origin)
+	}
+	//
+	p.columns[origin] += columns
+	p.constraints[origin] += constraints
+}
+
+func (p *provenanceSummary) print() {
+	// Sort origins by number of columns, then constraints (largest first)
+	slices.SortStableFunc(p.origins, func(l string, r string) int {
+		if p.columns[l] != p.columns[r] {
+			return cmp.Compare(p.columns[r], p.columns[l])
+		}
+		//
+		return cmp.Compare(p.constraints[r], p.constraints[l])
+	})
+	//
+	for _, origin := range p.origins {
+		fmt.Printf(";; %s: %d column(s), %d constraint(s)\n", origin, p.columns[origin], p.constraints[origin])
+	}
+}
+
+// Print out the source-level columns allocated to a given register, along with
+// the selector of the perspective in which each is declared (if applicable).
+func printRegisterSources(column uint, schema schema.Schema, sources *hir.Schema) {
+	name := schema.Columns().Nth(column).Name
+	//
+	for _, source := range sources.RegisterSources(column) {
+		if source.Selector != nil {
+			selector := source.Selector.Lisp(sources).String(false)
+			fmt.Printf(";; %s <- %s (when %s)\n", name, source.QualifiedName(), selector)
+		} else {
+			fmt.Printf(";; %s <- %s\n", name, source.QualifiedName())
+		}
 	}
 }
 
@@ -125,6 +240,55 @@ func printStats(hirSchema *hir.Schema, hir bool, mir bool, air bool) {
 	tbl.Print()
 }
 
+// Print the required spillage for each module at each of the selected IRs,
+// along with the assignment (if any) which requires that spillage.
+func printSpillage(hirSchema *hir.Schema, hir bool, mir bool, air bool) {
+	var (
+		schemas []schema.Schema
+		irs     []string
+	)
+	//
+	mirSchema := hirSchema.LowerToMir()
+	airSchema := mirSchema.LowerToAir()
+	//
+	if hir {
+		schemas, irs = append(schemas, hirSchema), append(irs, "HIR")
+	}
+
+	if mir {
+		schemas, irs = append(schemas, mirSchema), append(irs, "MIR")
+	}
+
+	if air {
+		schemas, irs = append(schemas, airSchema), append(irs, "AIR")
+	}
+	//
+	nmodules := hirSchema.Modules().Count()
+	tbl := util.NewTablePrinter(4, 1+nmodules*uint(len(schemas)))
+	tbl.SetRow(0, "IR", "Module", "Spillage", "Required By")
+	//
+	for i, row := 0, uint(1); i < len(schemas); i++ {
+		for m := uint(0); m < nmodules; m, row = m+1, row+1 {
+			driver := "(initial padding row)"
+			name := schemas[i].Modules().Nth(m).Name
+			spillage := sc.RequiredSpillage(m, schemas[i])
+			//
+			if assignment := sc.SpillageDriver(m, schemas[i]); assignment != nil {
+				driver = sc.OriginOf(assignment, schemas[i])
+			}
+			//
+			if name == "" {
+				name = "(root)"
+			}
+			//
+			tbl.SetRow(row, irs[i], name, fmt.Sprintf("%d", spillage), driver)
+		}
+	}
+	//
+	tbl.SetMaxWidths(64)
+	tbl.Print()
+}
+
 // ============================================================================
 // Schema Summarisers
 // ============================================================================
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// traceDiffCmd represents the trace diff command for comparing traces.
+var traceDiffCmd = &cobra.Command{
+	Use:   "diff [flags] trace_file trace_file",
+	Short: "Compare two trace files.",
+	Long: `Compare two trace files module by module, reporting
+	columns which were added or removed, modules whose heights differ,
+	and the first differing cells of each column.  Differing cells are
+	shown side-by-side.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		// Parse traces
+		before := readTraceFile(args[0])
+		after := readTraceFile(args[1])
+		filter := GetString(cmd, "filter")
+		rows := util.NewPair[uint, uint](0, math.MaxUint)
+		limit := GetUint(cmd, "max-diffs")
+		padding := GetUint(cmd, "padding")
+		maxWidth := GetUint(cmd, "max-width")
+		ansiEscapes := GetFlag(cmd, "ansi-escapes")
+		// Parse row window (if given)
+		if GetString(cmd, "rows") != "" {
+			rows = GetRange(cmd, "rows")
+		}
+		// Construct filters
+		if filter != "" {
+			before, after = filterColumns(before, filter), filterColumns(after, filter)
+		}
+		// Go!
+		if diffTraces(before, after, rows, limit, padding, maxWidth, ansiEscapes) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	traceCmd.AddCommand(traceDiffCmd)
+	traceDiffCmd.Flags().StringP("filter", "f", "", "Filter columns matching regex")
+	traceDiffCmd.Flags().String("rows", "", "restrict comparison to a (inclusive) window of rows (e.g. 10..20)")
+	traceDiffCmd.Flags().Uint("max-diffs", 10, "maximum number of differing cells to report per column")
+	traceDiffCmd.Flags().Uint("padding", 2, "number of additional rows to show either side of differing cells")
+	traceDiffCmd.Flags().Uint("max-width", 32, "specify maximum display width for a column")
+	traceDiffCmd.Flags().Bool("ansi-escapes", true, "specify whether to allow ANSI escapes or not (e.g. for colour)")
+}
+
+// ColumnDiff identifies the differences between two versions of the same
+// column.
+type ColumnDiff struct {
+	// Name of the column.
+	Name string
+	// Original column data.
+	Before util.FrArray
+	// Updated column data.
+	After util.FrArray
+	// Rows on which the two versions differ (upto some limit).
+	Rows []uint
+	// Total number of rows on which the two versions differ.
+	Count uint
+}
+
+// Compare two traces module by module, printing the differences between them.
+// Only rows within the given (inclusive) window are compared, and at most
+// limit differing cells are reported for any column.  This returns true if any
+// differences were found.
+func diffTraces(before []trace.RawColumn, after []trace.RawColumn, rows util.Pair[uint, uint], limit uint,
+	padding uint, maxWidth uint, ansiEscapes bool) bool {
+	differs := false
+	beforeModules, afterModules := groupColumnsByModule(before), groupColumnsByModule(after)
+	//
+	for _, mod := range unionOfKeys(beforeModules, afterModules) {
+		var diffs []ColumnDiff
+		//
+		beforeCols, afterCols := beforeModules[mod], afterModules[mod]
+		name := mod
+		//
+		if name == "" {
+			name = "(root)"
+		}
+		// Check heights
+		if bh, ah := rawModuleHeight(beforeCols), rawModuleHeight(afterCols); bh != ah {
+			fmt.Printf("module %s: height differs (%d vs %d)\n", name, bh, ah)
+			differs = true
+		}
+		//
+		for _, col := range unionOfKeys(beforeCols, afterCols) {
+			b, bok := beforeCols[col]
+			a, aok := afterCols[col]
+			qualifiedName := trace.QualifiedColumnName(mod, col)
+			//
+			if !aok {
+				fmt.Printf("module %s: removed column %s\n", name, qualifiedName)
+				differs = true
+			} else if !bok {
+				fmt.Printf("module %s: added column %s\n", name, qualifiedName)
+				differs = true
+			} else if diff := diffColumn(qualifiedName, b, a, rows, limit); diff.Count > 0 {
+				diffs = append(diffs, diff)
+			}
+		}
+		//
+		for _, diff := range diffs {
+			fmt.Printf("module %s: column %s differs on %d row(s)\n", name, diff.Name, diff.Count)
+		}
+		//
+		printColumnDiffs(diffs, padding, maxWidth, ansiEscapes)
+		//
+		differs = differs || len(diffs) > 0
+	}
+	//
+	return differs
+}
+
+// Compare two versions of a given column, identifying (at most limit) rows
+// within a given (inclusive) window on which they differ.  A row which exists
+// in only one version is considered to differ.
+func diffColumn(name string, before util.FrArray, after util.FrArray, rows util.Pair[uint, uint],
+	limit uint) ColumnDiff {
+	diff := ColumnDiff{name, before, after, nil, 0}
+	end := max(before.Len(), after.Len())
+	// Restrict to window
+	if rows.Right < end {
+		end = rows.Right + 1
+	}
+	//
+	for i := rows.Left; i < end; i++ {
+		if i >= before.Len() || i >= after.Len() {
+			diff.Count++
+		} else if b, a := before.Get(i), after.Get(i); b.Cmp(&a) != 0 {
+			diff.Count++
+		} else {
+			continue
+		}
+		//
+		if uint(len(diff.Rows)) < limit {
+			diff.Rows = append(diff.Rows, i)
+		}
+	}
+	//
+	return diff
+}
+
+// Print the differing cells of a given set of columns side-by-side, such that
+// nearby differing rows are grouped into windows.  Each column is shown twice
+// (i.e. before and after), with differing cells highlighted.
+func printColumnDiffs(diffs []ColumnDiff, padding uint, maxWidth uint, ansiEscapes bool) {
+	var (
+		rows    []uint
+		columns []trace.ArrayColumn
+		modules []trace.ArrayModule
+		data    []util.FrArray
+		cells   = util.NewAnySortedSet[trace.CellRef]()
+	)
+	// Construct a trace holding both versions of each column.  Each column is
+	// placed in its own module, since the versions may differ in height.
+	for _, diff := range diffs {
+		for _, version := range []util.FrArray{diff.Before, diff.After} {
+			index := uint(len(columns))
+			ctx := trace.NewContext(index, 1)
+			columns = append(columns, trace.EmptyArrayColumn(ctx, diff.Name))
+			modules = append(modules, trace.EmptyArrayModule(diff.Name))
+			data = append(data, version)
+			//
+			for _, row := range diff.Rows {
+				cells.Insert(trace.NewCellRef(index, int(row)))
+			}
+		}
+		//
+		rows = append(rows, diff.Rows...)
+	}
+	//
+	tr := trace.NewArrayTrace(modules, columns)
+	// Fill columns (which also determines module heights)
+	for i, version := range data {
+		tr.FillColumn(uint(i), version, fr.NewElement(0))
+	}
+	//
+	slices.Sort(rows)
+	rows = slices.Compact(rows)
+	//
+	for _, window := range failureWindows(rows, padding) {
+		tp := trace.NewPrinter().Start(window[0]).End(window[len(window)-1]).Padding(padding)
+		tp = tp.MaxCellWidth(maxWidth).AnsiEscapes(ansiEscapes)
+		// Highlight differing cells
+		tp = tp.Highlight(func(cell trace.CellRef, tr trace.Trace) bool {
+			return cells.Contains(cell)
+		})
+		// Identify which version each column is
+		tp = tp.ColumnNames(func(col uint, tr trace.Trace) string {
+			if col%2 == 0 {
+				return fmt.Sprintf("%s (before)", tr.Column(col).Name())
+			}
+			//
+			return fmt.Sprintf("%s (after)", tr.Column(col).Name())
+		})
+		//
+		tp.Print(tr)
+		fmt.Println()
+	}
+}
+
+// Group the columns of a trace by module, and then by name.
+func groupColumnsByModule(cols []trace.RawColumn) map[string]map[string]util.FrArray {
+	modules := make(map[string]map[string]util.FrArray)
+	//
+	for _, col := range cols {
+		if _, ok := modules[col.Module]; !ok {
+			modules[col.Module] = make(map[string]util.FrArray)
+		}
+		//
+		modules[col.Module][col.Name] = col.Data
+	}
+	//
+	return modules
+}
+
+// Determine the height of a module from its raw columns, which is taken as the
+// height of its tallest column.
+func rawModuleHeight(cols map[string]util.FrArray) uint {
+	height := uint(0)
+	//
+	for _, data := range cols {
+		height = max(height, data.Len())
+	}
+	//
+	return height
+}
+
+// Determine the (sorted) union of the keys of two maps.
+func unionOfKeys[T any](left map[string]T, right map[string]T) []string {
+	var keys []string
+	//
+	for k := range left {
+		keys = append(keys, k)
+	}
+	//
+	for k := range right {
+		if _, ok := left[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	//
+	slices.Sort(keys)
+	//
+	return keys
+}
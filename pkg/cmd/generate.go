@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/trace/json"
+	"github.com/consensys/go-corset/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var genTracesCmd = &cobra.Command{
+	Use:   "gen-traces [flags] constraint_file(s)",
+	Short: "generate test traces for a set of constraints.",
+	Long: `Generate traces for a given set of constraints, and classify them as
+	accepted or rejected using the constraints themselves as the oracle.  By
+	default, all traces over a given pool of elements are enumerated for
+	each module height in a given range.  Alternatively, a given number of
+	traces can be sampled at random.  Accepted traces are written to a
+	.accepts file, and rejected traces to a .rejects file (one JSON trace
+	per line).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var columns util.Enumerator[[]trace.RawColumn]
+		//
+		if len(args) < 1 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		// Configure log level
+		if GetFlag(cmd, "verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+		//
+		stdlib := !GetFlag(cmd, "no-stdlib")
+		debug := GetFlag(cmd, "debug")
+		legacy := GetFlag(cmd, "legacy")
+		rows := GetRange(cmd, "rows")
+		pool := GetRange(cmd, "pool")
+		sample := GetUint(cmd, "sample")
+		limit := GetUint(cmd, "max-traces")
+		output := GetString(cmd, "out")
+		heights := GetHeights(cmd, "height")
+		// Parse constraints
+		hirSchema := readSchema(stdlib, debug, legacy, args)
+		// Determine output files
+		if output == "" {
+			output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".auto"
+		}
+		// Determine traces to classify
+		if sample > 0 {
+			seed := GetUint64(cmd, "seed")
+			columns = sc.NewTraceGenerator(hirSchema, seed, sample, rows.Right).Heights(rows).FixedHeights(heights)
+		} else {
+			columns = enumerateColumns(hirSchema, rows, heights, elementPool(pool))
+		}
+		// Go!
+		builder := sc.NewTraceBuilder(hirSchema).Parallel(false)
+		accepts, rejects := sc.ClassifyTraces(columns, sc.AcceptedBy(builder), limit)
+		//
+		writeTraces(fmt.Sprintf("%s.accepts", output), accepts)
+		writeTraces(fmt.Sprintf("%s.rejects", output), rejects)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(genTracesCmd)
+	genTracesCmd.Flags().Bool("debug", false, "enable debugging constraints")
+	genTracesCmd.Flags().String("rows", "1..2",
+		"specify (inclusive) range of module heights to enumerate or sample (e.g. 1..4)")
+	genTracesCmd.Flags().String("pool", "0..2", "specify (inclusive) range of elements to enumerate (e.g. 0..3)")
+	genTracesCmd.Flags().StringArray("height", []string{},
+		"specify fixed height for all modules (n) or for a given module (module=n), instead of enumerating them")
+	genTracesCmd.Flags().Uint("sample", 0,
+		"sample this many random traces (with values drawn from column types) instead of enumerating them")
+	genTracesCmd.Flags().Uint64("seed", 0, "specify seed for random sampling")
+	genTracesCmd.Flags().Uint("max-traces", math.MaxUint, "specify maximum number of traces to generate")
+	genTracesCmd.Flags().StringP("out", "o", "", "specify prefix of output files (e.g. \"dir/name.auto\")")
+}
+
+// Construct an enumerator over all sets of input columns of a given schema, for
+// each module height in a given (inclusive) range.  The heights of some
+// modules may instead be fixed.
+func enumerateColumns(schema *hir.Schema, rows util.Pair[uint, uint], fixed sc.ModuleHeights,
+	pool []fr.Element) util.Enumerator[[]trace.RawColumn] {
+	var (
+		enumerators []util.Enumerator[[]trace.RawColumn]
+		previous    []uint
+	)
+	//
+	for n := rows.Left; n <= rows.Right; n++ {
+		heights := make([]uint, schema.Modules().Count())
+		//
+		for i := range heights {
+			heights[i] = n
+			//
+			if fixed == nil {
+				continue
+			} else if h, ok := fixed(schema.Modules().Nth(uint(i)).Name); ok {
+				heights[i] = h
+			}
+		}
+		// Avoid duplicates (e.g. when all heights are fixed)
+		if !slices.Equal(heights, previous) {
+			enumerators = append(enumerators, sc.NewColumnEnumerator(schema, heights, pool))
+			previous = heights
+		}
+	}
+	//
+	return util.NewConcatEnumerator(enumerators)
+}
+
+// Construct the pool of elements in a given (inclusive) range.
+func elementPool(bounds util.Pair[uint, uint]) []fr.Element {
+	var elems []fr.Element
+	//
+	for i := bounds.Left; i <= bounds.Right; i++ {
+		elems = append(elems, fr.NewElement(uint64(i)))
+	}
+	//
+	return elems
+}
+
+// Write a given set of traces to a file, with one JSON trace per line.
+func writeTraces(filename string, traces [][]trace.RawColumn) {
+	var sb strings.Builder
+	// Generate lines
+	for _, columns := range traces {
+		sb.WriteString(json.ToJsonString(columns))
+		sb.WriteString("\n")
+	}
+	// Write the file
+	if err := os.WriteFile(filename, []byte(sb.String()), 0644); err != nil {
+		fmt.Println(err)
+		os.Exit(4)
+	}
+	// Log what happened
+	log.Infof("Wrote %s (%d traces)", filename, len(traces))
+}
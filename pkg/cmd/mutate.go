@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var mutateCmd = &cobra.Command{
+	Use:   "mutate [flags] trace_file constraint_file(s)",
+	Short: "identify columns which are under-constrained by mutating an accepted trace.",
+	Long: `Mutate the cells of an accepted trace, one at a time, and check
+	whether the mutated trace is still accepted.  Each cell of each input
+	column is replaced in turn by other values drawn from the column's type
+	(e.g. 0, 1 or its largest value).  Any mutation which is still accepted
+	suggests the column is missing constraints.  Such mutations are reported,
+	grouped by module and column.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		// Configure log level
+		if GetFlag(cmd, "verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+		//
+		stdlib := !GetFlag(cmd, "no-stdlib")
+		debug := GetFlag(cmd, "debug")
+		legacy := GetFlag(cmd, "legacy")
+		filter := GetString(cmd, "filter")
+		examples := GetUint(cmd, "max-examples")
+		rows := util.NewPair[uint, uint](0, math.MaxUint)
+		// Parse row window (if given)
+		if GetString(cmd, "rows") != "" {
+			rows = GetRange(cmd, "rows")
+		}
+		// Parse constraints and trace
+		hirSchema := readSchema(stdlib, debug, legacy, args[1:])
+		columns := readTraceFile(args[0])
+		// Determine IR levels to check
+		cfg := checkConfig{hir: GetFlag(cmd, "hir"), mir: GetFlag(cmd, "mir"), air: GetFlag(cmd, "air")}
+		//
+		if !cfg.hir && !cfg.mir && !cfg.air {
+			cfg.hir = true
+		}
+		//
+		oracle := acceptanceOracle(lowerSchemas(hirSchema, cfg))
+		// Sanity check original trace is accepted
+		if !oracle(columns) {
+			fmt.Println("trace is not accepted")
+			os.Exit(2)
+		}
+		// Determine columns to mutate
+		var pattern *regexp.Regexp
+		//
+		if filter != "" {
+			pattern = regexp.MustCompile(filter)
+		}
+		// Go!
+		survivors := mutateTrace(columns, columnTypes(hirSchema), pattern, rows, oracle)
+		//
+		if printSurvivors(survivors, examples) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mutateCmd)
+	mutateCmd.Flags().Bool("debug", false, "enable debugging constraints")
+	mutateCmd.Flags().StringP("filter", "f", "", "only mutate columns matching regex")
+	mutateCmd.Flags().String("rows", "", "only mutate rows within a (inclusive) window (e.g. 10..20)")
+	mutateCmd.Flags().Uint("max-examples", 3, "maximum number of accepted mutations to show per column")
+	mutateCmd.Flags().Bool("hir", false, "check mutations at HIR level")
+	mutateCmd.Flags().Bool("mir", false, "check mutations at MIR level")
+	mutateCmd.Flags().Bool("air", false, "check mutations at AIR level")
+}
+
+// Mutation identifies a single cell of a trace which was replaced by a given
+// value.
+type Mutation struct {
+	// Row of the mutated cell.
+	Row uint
+	// Original value of the cell.
+	Before fr.Element
+	// Mutated value of the cell.
+	After fr.Element
+}
+
+// MutationSummary summarises the mutations applied to a given column.
+type MutationSummary struct {
+	// Module containing the column.
+	Module string
+	// Name of the column.
+	Name string
+	// Total number of mutations applied to the column.
+	Total uint
+	// Mutations which were accepted.
+	Accepted []Mutation
+}
+
+// Construct an oracle which holds for a given set of input columns when the
+// trace built from them is accepted at every given IR level.
+func acceptanceOracle(schemas []loweredSchema) sc.TraceOracle {
+	oracles := make([]sc.TraceOracle, len(schemas))
+	//
+	for i, s := range schemas {
+		oracles[i] = sc.AcceptedBy(sc.NewTraceBuilder(s.schema))
+	}
+	//
+	return func(columns []trace.RawColumn) bool {
+		for _, oracle := range oracles {
+			if !oracle(columns) {
+				return false
+			}
+		}
+		//
+		return true
+	}
+}
+
+// Mutate each cell (within a given window) of each input column matching a
+// given pattern, recording those mutations which are still accepted.  Columns
+// whose type is unknown (i.e. which are not input columns of the schema) are
+// not mutated.
+func mutateTrace(columns []trace.RawColumn, types map[string]sc.Type, pattern *regexp.Regexp,
+	rows util.Pair[uint, uint], oracle sc.TraceOracle) []MutationSummary {
+	var summaries []MutationSummary
+	//
+	for i, col := range columns {
+		datatype, ok := types[col.QualifiedName()]
+		//
+		if !ok || (pattern != nil && !pattern.MatchString(col.QualifiedName())) {
+			continue
+		}
+		//
+		summary := MutationSummary{col.Module, col.Name, 0, nil}
+		original := col.Data
+		//
+		for row := rows.Left; row < original.Len() && row <= rows.Right; row++ {
+			before := original.Get(row)
+			//
+			for _, after := range mutationsOf(before, datatype) {
+				data := util.WidenFrArray(original.Clone(), util.FrElementBitWidth(after))
+				data.Set(row, after)
+				columns[i].Data = data
+				summary.Total++
+				//
+				if oracle(columns) {
+					summary.Accepted = append(summary.Accepted, Mutation{row, before, after})
+				}
+			}
+		}
+		// Restore original column
+		columns[i].Data = original
+		summaries = append(summaries, summary)
+		//
+		log.Debugf("applied %d mutations to column %s", summary.Total, col.QualifiedName())
+	}
+	//
+	return summaries
+}
+
+// Determine the values with which a given value of a given type can be
+// replaced.  These are the boundary values of the type, along with the values
+// either side of the given value (where they belong to the type).
+func mutationsOf(value fr.Element, datatype sc.Type) []fr.Element {
+	var (
+		mutations []fr.Element
+		one       = fr.NewElement(1)
+		next      fr.Element
+		prev      fr.Element
+	)
+	//
+	next.Add(&value, &one)
+	prev.Sub(&value, &one)
+	//
+	for _, v := range append(sc.BoundaryValues(datatype), prev, next) {
+		if v != value && datatype.Accept(v) && !slices.Contains(mutations, v) {
+			mutations = append(mutations, v)
+		}
+	}
+	//
+	return mutations
+}
+
+// Print the mutations which were accepted, grouped by module and then column,
+// returning true if there were any.  At most a given number of example
+// mutations are shown for each column.
+func printSurvivors(summaries []MutationSummary, examples uint) bool {
+	survived := false
+	modules := groupSummariesByModule(summaries)
+	//
+	for _, module := range unionOfKeys(modules, nil) {
+		var lines []string
+		//
+		for _, s := range modules[module] {
+			if len(s.Accepted) == 0 {
+				continue
+			}
+			//
+			var shown []string
+			//
+			for _, m := range s.Accepted[:min(uint(len(s.Accepted)), examples)] {
+				shown = append(shown, fmt.Sprintf("row %d: %s => %s", m.Row, m.Before.String(), m.After.String()))
+			}
+			//
+			lines = append(lines, fmt.Sprintf("\tcolumn %s: %d of %d mutation(s) accepted (e.g. %s)",
+				trace.QualifiedColumnName(s.Module, s.Name), len(s.Accepted), s.Total, strings.Join(shown, "; ")))
+		}
+		//
+		if len(lines) == 0 {
+			continue
+		}
+		//
+		if module == "" {
+			module = "(root)"
+		}
+		//
+		fmt.Printf("module %s:\n", module)
+		//
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		//
+		survived = true
+	}
+	//
+	if !survived {
+		fmt.Println("no mutations accepted")
+	}
+	//
+	return survived
+}
+
+// Group mutation summaries by the module of the column they concern.
+func groupSummariesByModule(summaries []MutationSummary) map[string][]MutationSummary {
+	modules := make(map[string][]MutationSummary)
+	//
+	for _, s := range summaries {
+		modules[s.Module] = append(modules[s.Module], s)
+	}
+	//
+	return modules
+}
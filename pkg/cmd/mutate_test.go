@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ============================================================================
+// Mutations
+// ============================================================================
+
+func Test_MutationsOf_01(t *testing.T) {
+	checkMutationsOf(t, 5, sc.NewUintType(8), elements(0, 1, 127, 128, 255, 4, 6))
+}
+
+func Test_MutationsOf_02(t *testing.T) {
+	// Values outside the type are not mutations
+	checkMutationsOf(t, 0, sc.NewUintType(8), elements(1, 127, 128, 255))
+	checkMutationsOf(t, 255, sc.NewUintType(8), elements(0, 1, 127, 128, 254))
+}
+
+func Test_MutationsOf_03(t *testing.T) {
+	checkMutationsOf(t, 1, sc.NewUintType(1), elements(0))
+}
+
+func Test_MutateTrace_01(t *testing.T) {
+	// Only the first row of X is constrained, and Y is not an input column.
+	columns := mutationColumns()
+	summaries := mutateTrace(columns, mutationTypes(), nil, util.NewPair[uint, uint](0, math.MaxUint),
+		mutationOracle)
+	//
+	checkMutationSummaries(t, summaries, 11, elements(0, 1, 127, 128, 255, 3))
+	// Original columns are restored
+	if x := columns[0].Data.Get(0); x != fr.NewElement(1) {
+		t.Errorf("expected original column to be restored")
+	}
+}
+
+func Test_MutateTrace_02(t *testing.T) {
+	// Only rows within the window are mutated
+	summaries := mutateTrace(mutationColumns(), mutationTypes(), nil, util.NewPair[uint, uint](0, 0),
+		mutationOracle)
+	//
+	checkMutationSummaries(t, summaries, 5, nil)
+}
+
+func Test_MutateTrace_03(t *testing.T) {
+	// Only columns matching the pattern are mutated
+	summaries := mutateTrace(mutationColumns(), mutationTypes(), regexp.MustCompile("^Y$"),
+		util.NewPair[uint, uint](0, math.MaxUint), mutationOracle)
+	//
+	if len(summaries) != 0 {
+		t.Errorf("expected no columns mutated, got %v", summaries)
+	}
+}
+
+func mutationColumns() []trace.RawColumn {
+	x, y := util.NewFrArray(2, 8), util.NewFrArray(2, 8)
+	x.Set(0, fr.NewElement(1))
+	x.Set(1, fr.NewElement(2))
+	//
+	return []trace.RawColumn{{Module: "", Name: "X", Data: x}, {Module: "", Name: "Y", Data: y}}
+}
+
+func mutationTypes() map[string]sc.Type {
+	return map[string]sc.Type{"X": sc.NewUintType(8)}
+}
+
+// Accepts any trace where the first row of X holds 1.
+func mutationOracle(columns []trace.RawColumn) bool {
+	return columns[0].Data.Get(0) == fr.NewElement(1)
+}
+
+// Check the mutations of column X, where the given mutations (of row 1) are
+// expected to be accepted.
+func checkMutationSummaries(t *testing.T, summaries []MutationSummary, total uint, accepted []fr.Element) {
+	var actual []fr.Element
+	//
+	if len(summaries) != 1 || summaries[0].Name != "X" {
+		t.Fatalf("expected column X mutated, got %v", summaries)
+	}
+	//
+	for _, m := range summaries[0].Accepted {
+		if m.Row != 1 || m.Before != fr.NewElement(2) {
+			t.Errorf("unexpected mutation accepted on row %d", m.Row)
+		}
+		//
+		actual = append(actual, m.After)
+	}
+	//
+	if summaries[0].Total != total || !slices.Equal(actual, accepted) {
+		t.Errorf("expected %d mutations with %v accepted, got %d with %v accepted", total, accepted,
+			summaries[0].Total, actual)
+	}
+}
+
+func checkMutationsOf(t *testing.T, value uint64, datatype sc.Type, expected []fr.Element) {
+	if actual := mutationsOf(fr.NewElement(value), datatype); !slices.Equal(actual, expected) {
+		t.Errorf("mutations of %d in %s are %v, expected %v", value, datatype.String(), actual, expected)
+	}
+}
+
+func elements(values ...uint64) []fr.Element {
+	elements := make([]fr.Element, len(values))
+	//
+	for i, v := range values {
+		elements[i] = fr.NewElement(v)
+	}
+	//
+	return elements
+}
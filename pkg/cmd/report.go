@@ -0,0 +1,423 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// FailureRecord provides a machine-readable representation of a single
+// constraint failure, suitable for consumption by external tooling (e.g. CI
+// pipelines).
+type FailureRecord struct {
+	// IR level at which the failure arose (e.g. HIR, MIR or AIR).
+	IR string `json:"ir"`
+	// Trace file in which the failure arose, when checking several trace
+	// files.
+	Trace string `json:"trace,omitempty"`
+	// Handle of the failing constraint.
+	Handle string `json:"handle"`
+	// Kind of the failing constraint (e.g. vanishing, lookup, etc).
+	Kind string `json:"kind"`
+	// Human-readable message describing the failure.
+	Message string `json:"message"`
+	// Rows on which the failure arose.
+	Rows []uint `json:"rows"`
+	// Cells involved in the failure, along with their values.
+	Cells []CellRecord `json:"cells"`
+	// Tuples involved in the failure (e.g. those missing from a lookup).
+	Tuples []TupleRecord `json:"tuples,omitempty"`
+	// Location in the original source file(s) of the failing constraint.
+	Location *LocationRecord `json:"location,omitempty"`
+}
+
+// LocationRecord identifies a location within an original source file.
+type LocationRecord struct {
+	// Name of the source file.
+	File string `json:"file"`
+	// Line number (counting from 1).
+	Line int `json:"line"`
+	// Column number (counting from 1).
+	Column int `json:"column"`
+}
+
+// TupleRecord identifies a tuple of values involved in a failure, along with
+// the rows on which it occurs.
+type TupleRecord struct {
+	// Side of the constraint on which this tuple arises (e.g. source or
+	// target).
+	Side string `json:"side"`
+	// Values making up this tuple.
+	Values []string `json:"values"`
+	// Multiplicity of this tuple (e.g. the number of times it is missing).
+	Multiplicity uint `json:"multiplicity"`
+	// Rows on which this tuple occurs.
+	Rows []uint `json:"rows"`
+}
+
+// CellRecord identifies the value of a given cell involved in a failure.
+type CellRecord struct {
+	// Qualified name of the column holding this cell.
+	Column string `json:"column"`
+	// Row of this cell.
+	Row int `json:"row"`
+	// Value held in this cell.
+	Value string `json:"value"`
+	// Perspective-qualified name(s) of the source-level column(s) active for
+	// this cell, where the column is a register shared between perspectives.
+	Source string `json:"source,omitempty"`
+}
+
+// Report constraint failures as a sequence of JSON records, one per line.
+func reportFailuresAsJson(ir string, file string, failures []sc.Failure, trace tr.Trace, sources *hir.Schema) {
+	for _, f := range failures {
+		record := toFailureRecord(ir, f, trace, sources)
+		record.Trace = file
+		//
+		printFailureRecord(record)
+	}
+}
+
+// Report a trace which failed validation (i.e. because some cell holds a value
+// outside the type of its column) as a JSON record.
+func reportValidationFailureAsJson(ir string, file string, err *validationError) {
+	cells := []CellRecord{{err.column, int(err.row), err.value.String(), ""}}
+	//
+	printFailureRecord(FailureRecord{ir, file, "", "validation", err.Error(), []uint{err.row}, cells, nil, nil})
+}
+
+// Report a trace whose outcome diverged between IR levels as JSON records, one
+// for each level at which it was rejected.
+func reportDivergenceAsJson(irs []string, file string, msg string) {
+	for _, ir := range irs {
+		printFailureRecord(FailureRecord{ir, file, "", "divergence", msg, []uint{}, []CellRecord{}, nil, nil})
+	}
+}
+
+// Print a failure record as JSON on a single line.  Records which cannot be
+// encoded are reported as errors instead.
+func printFailureRecord(record FailureRecord) {
+	if bytes, err := json.Marshal(record); err != nil {
+		log.Errorf("cannot encode failure %q (%s)", record.Message, err)
+	} else {
+		fmt.Println(string(bytes))
+	}
+}
+
+// Construct a failure record from a given failure.  This extracts the
+// structured information held in the failure (where available).
+func toFailureRecord(ir string, failure sc.Failure, trace tr.Trace, sources *hir.Schema) FailureRecord {
+	var (
+		handle string
+		kind   string
+		rows   []uint
+		cells  *util.AnySortedSet[tr.CellRef]
+		tuples []TupleRecord
+	)
+	//
+	switch f := failure.(type) {
+	case *constraint.VanishingFailure:
+		handle, kind, rows, cells = f.Handle, "vanishing", f.Rows, f.RequiredCells(trace)
+	case *constraint.LookupFailure:
+		handle, kind, rows, cells = f.Handle, "lookup", f.Rows, f.RequiredCells(trace)
+		//
+		for _, t := range f.Missing(trace) {
+			tuples = append(tuples, toTupleRecord("source", t))
+		}
+	case *constraint.RangeFailure:
+		handle, kind, rows, cells = f.Handle, "range", f.Rows, f.RequiredCells(trace)
+	case *constraint.PermutationFailure:
+		handle, kind, rows = f.Handle, "permutation", []uint{}
+		//
+		for _, t := range f.MissingFromTarget {
+			tuples = append(tuples, toTupleRecord("source", t))
+		}
+		//
+		for _, t := range f.MissingFromSource {
+			tuples = append(tuples, toTupleRecord("target", t))
+		}
+	case *sc.AssertionFailure:
+		handle, kind, rows, cells = f.Handle, "assertion", f.Rows, f.RequiredCells(trace)
+	default:
+		kind = "unknown"
+	}
+	//
+	cellRecords := toCellRecords(cells, trace, sources)
+	record := FailureRecord{ir, "", handle, kind, failure.Message(), rows, cellRecords, tuples, nil}
+	// Include source location (if known)
+	if loc := sc.LocationOf(failure); loc != nil {
+		record.Location = &LocationRecord{loc.Filename, loc.Line, 1 + loc.Offset}
+	}
+	//
+	return record
+}
+
+// Construct a tuple record from a given tuple arising on a given side of a
+// failing constraint.
+func toTupleRecord(side string, tuple constraint.Tuple) TupleRecord {
+	strs := make([]string, len(tuple.Values))
+	//
+	for i, v := range tuple.Values {
+		strs[i] = v.String()
+	}
+	//
+	return TupleRecord{side, strs, tuple.Multiplicity, tuple.Rows}
+}
+
+// Convert a set of cell references into cell records, by extracting the value
+// of each cell from the trace.
+func toCellRecords(cells *util.AnySortedSet[tr.CellRef], trace tr.Trace, sources *hir.Schema) []CellRecord {
+	records := make([]CellRecord, 0)
+	//
+	if cells == nil {
+		return records
+	}
+	//
+	for _, c := range cells.ToArray() {
+		col := trace.Column(c.Column)
+		name := qualifiedColumnName(c.Column, trace)
+		val := col.Get(c.Row)
+		source := ""
+		// Identify active source column(s) for shared registers
+		if isSharedRegister(c.Column, sources) {
+			source = strings.Join(activeSources(c, trace, sources, true), "|")
+		}
+		//
+		records = append(records, CellRecord{name, c.Row, val.String(), source})
+	}
+	//
+	return records
+}
+
+// Determine the fully qualified name of a given column in a trace.
+func qualifiedColumnName(column uint, trace tr.Trace) string {
+	col := trace.Column(column)
+	mod := trace.Modules().Nth(col.Context().Module())
+	//
+	return tr.QualifiedColumnName(mod.Name(), col.Name())
+}
+
+// Determine whether a given column is a register to which source-level columns
+// from one or more perspectives were allocated.  For such registers, the
+// register name itself (e.g. "A_xor_B") is not particularly meaningful.
+func isSharedRegister(column uint, sources *hir.Schema) bool {
+	if sources == nil {
+		return false
+	}
+	//
+	for _, source := range sources.RegisterSources(column) {
+		if source.Perspective != "" {
+			return true
+		}
+	}
+	//
+	return false
+}
+
+// Determine the title to show for a given column.  For registers shared between
+// perspectives, this lists the perspective-qualified names of all source-level
+// columns allocated to it.  Otherwise, the column's name is used.
+func sourceColumnName(column uint, trace tr.Trace, sources *hir.Schema) string {
+	if !isSharedRegister(column, sources) {
+		return trace.Column(column).Name()
+	}
+	//
+	var names []string
+	//
+	for _, source := range sources.RegisterSources(column) {
+		names = append(names, source.QualifiedName())
+	}
+	//
+	return strings.Join(names, "|")
+}
+
+// Determine which of the source-level columns allocated to the register of a
+// given cell are active on the cell's row.  These are identified either by
+// their qualified names, or by their perspectives.
+func activeSources(cell tr.CellRef, trace tr.Trace, sources *hir.Schema, qualified bool) []string {
+	var names []string
+	//
+	for _, source := range sources.RegisterSources(cell.Column) {
+		if !source.IsActiveAt(cell.Row, trace) {
+			continue
+		} else if qualified {
+			names = append(names, source.QualifiedName())
+		} else if source.Perspective != "" {
+			names = append(names, source.Perspective)
+		}
+	}
+	//
+	return names
+}
+
+// Determine how values of a given column should be displayed, based on the
+// display of the column in the original (HIR) schema.
+func columnDisplay(column uint, sources *hir.Schema) sc.Display {
+	if sources == nil {
+		return sc.DISPLAY_DEFAULT
+	}
+	//
+	return sources.ColumnDisplay(column)
+}
+
+// Print a human-readable report detailing the source tuples of a failing lookup
+// which are missing from the target.  For each missing tuple, the closest
+// target tuples (i.e. those matching on the most values) are shown.  At most
+// maxFailures tuples are reported (unless this is zero).
+func reportLookupTuples(f *constraint.LookupFailure, trace tr.Trace, cfg checkConfig) {
+	missing := f.Missing(trace)
+	//
+	for i, tuple := range missing {
+		if cfg.maxFailures != 0 && uint(i) >= cfg.maxFailures {
+			fmt.Printf("(%d more missing tuples)\n\n", len(missing)-i)
+			return
+		}
+		//
+		fmt.Printf("missing tuple %s occurs %d time(s) (%s):\n", tupleToString(tuple.Values), len(tuple.Rows),
+			sc.RowsToString(tuple.Rows))
+		// Report closest target tuples
+		nearest := 0
+		//
+		for _, row := range f.NearestTargets(tuple.Values, cfg.reportNearest, trace) {
+			cols := util.NewSortedSet[uint]()
+			matching := util.NewAnySortedSet[tr.CellRef]()
+			count := 0
+			// Determine target columns, and highlight those which match.
+			for j, e := range f.Targets {
+				cells := e.RequiredCells(int(row), trace)
+				jth := e.EvalAt(int(row), trace)
+				//
+				for _, c := range cells.ToArray() {
+					cols.Insert(c.Column)
+				}
+				//
+				if jth.Equal(&tuple.Values[j]) {
+					matching.InsertSorted(cells)
+					count++
+				}
+			}
+			// Target rows with nothing in common are not worth showing
+			if count > 0 {
+				fmt.Printf("closest target (row %d, %d of %d matching):\n", row, count, len(f.Targets))
+				printTraceWindow(row, row, 0, cols, matching, trace, cfg)
+				//
+				nearest++
+			}
+		}
+		//
+		if nearest == 0 {
+			fmt.Println("no target rows match on any value")
+		}
+		//
+		fmt.Println()
+	}
+}
+
+// Print a human-readable report detailing the multiset difference between the
+// source and target columns of a failing permutation.
+func reportPermutationTuples(f *constraint.PermutationFailure, trace tr.Trace, cfg checkConfig) {
+	reportMissingTuples(f.Handle, "target", f.MissingFromTarget, f.Sources, trace, cfg)
+	reportMissingTuples(f.Handle, "source", f.MissingFromSource, f.Targets, trace, cfg)
+}
+
+// Print a human-readable report detailing tuples missing from one side of a
+// failing permutation, by showing the rows of the other side on which they
+// occur.  At most maxFailures tuples are reported (unless this is zero).
+func reportMissingTuples(handle string, side string, tuples []constraint.Tuple, columns []uint,
+	trace tr.Trace, cfg checkConfig) {
+	// Determine columns to show
+	cols := util.NewSortedSet[uint]()
+	//
+	for _, col := range columns {
+		cols.Insert(col)
+	}
+	//
+	for i, tuple := range tuples {
+		if cfg.maxFailures != 0 && uint(i) >= cfg.maxFailures {
+			fmt.Printf("(%d more tuples missing from %s)\n\n", len(tuples)-i, side)
+			return
+		}
+		//
+		fmt.Printf("permutation %s: tuple %s missing from %s %d time(s) (%s):\n", handle,
+			tupleToString(tuple.Values), side, tuple.Multiplicity, sc.RowsToString(tuple.Rows))
+		//
+		for _, window := range failureWindows(tuple.Rows, cfg.reportPadding) {
+			cells := util.NewAnySortedSet[tr.CellRef]()
+			// Highlight all cells of the tuple
+			for _, row := range window {
+				for _, col := range columns {
+					cells.Insert(tr.NewCellRef(col, int(row)))
+				}
+			}
+			//
+			printTraceWindow(window[0], window[len(window)-1], cfg.reportPadding, cols, cells, trace, cfg)
+		}
+		//
+		fmt.Println()
+	}
+}
+
+// Print a window of a trace, showing only the given columns whilst highlighting
+// the given cells.
+func printTraceWindow(start uint, end uint, padding uint, cols *util.SortedSet[uint],
+	cells *util.AnySortedSet[tr.CellRef], trace tr.Trace, cfg checkConfig) {
+	// Construct & configure printer
+	tp := tr.NewPrinter().Start(start).End(end).MaxCellWidth(cfg.reportCellWidth).Padding(padding)
+	// Determine whether to enable ANSI escapes (e.g. for colour in the terminal)
+	tp = tp.AnsiEscapes(cfg.ansiEscapes)
+	// Filter out columns not of interest
+	tp = tp.Columns(func(col uint, trace tr.Trace) bool {
+		return cols.Contains(col)
+	})
+	// Highlight cells of interest
+	tp = tp.Highlight(func(cell tr.CellRef, trace tr.Trace) bool {
+		return cells.Contains(cell)
+	})
+	// Show source-level names for registers shared between perspectives, along
+	// with the perspective active on each row.
+	tp = tp.ColumnNames(func(col uint, trace tr.Trace) string {
+		return sourceColumnName(col, trace, cfg.sources)
+	})
+	tp = tp.Annotate(func(cell tr.CellRef, trace tr.Trace) string {
+		if !isSharedRegister(cell.Column, cfg.sources) {
+			return ""
+		} else if active := activeSources(cell, trace, cfg.sources, false); len(active) > 0 {
+			return strings.Join(active, ",")
+		}
+		//
+		return "inactive"
+	})
+	// Show values according to the display of their column
+	tp = tp.Format(func(col uint, val fr.Element) string {
+		return columnDisplay(col, cfg.sources).Format(val)
+	})
+	//
+	tp.Print(trace)
+}
+
+// Convert a tuple of values into a human-readable string.
+func tupleToString(values []fr.Element) string {
+	var builder strings.Builder
+	//
+	builder.WriteString("(")
+	//
+	for i, v := range values {
+		if i != 0 {
+			builder.WriteString(",")
+		}
+		//
+		builder.WriteString(fmt.Sprintf("0x%s", v.Text(16)))
+	}
+	//
+	builder.WriteString(")")
+	//
+	return builder.String()
+}
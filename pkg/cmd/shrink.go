@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// traceShrinkCmd represents the trace shrink command for minimising traces.
+var traceShrinkCmd = &cobra.Command{
+	Use:   "shrink [flags] trace_file out_file",
+	Short: "Minimise a trace which fails a given constraint.",
+	Long: `Minimise a trace which is rejected by a given constraint, such that
+	the minimised trace is still rejected by that constraint.  This repeatedly
+	removes ranges of rows from modules, drops unused columns and simplifies values
+	towards zero (or their padding value) until no further step preserves
+	the failure.  The minimised trace is then written to the given output
+	file (e.g. as JSON).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		// Configure log level
+		if GetFlag(cmd, "verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+		//
+		stdlib := !GetFlag(cmd, "no-stdlib")
+		debug := GetFlag(cmd, "debug")
+		legacy := GetFlag(cmd, "legacy")
+		handle := GetString(cmd, "constraint")
+		files := GetStringArray(cmd, "schema")
+		//
+		if handle == "" || len(files) == 0 {
+			fmt.Println("both --schema and --constraint must be given")
+			os.Exit(2)
+		}
+		// Parse constraints and trace
+		hirSchema := readSchema(stdlib, debug, legacy, files)
+		columns := inputColumns(readTraceFile(args[0]), sc.InputColumnsOf(hirSchema))
+		// Determine IR levels to check
+		cfg := checkConfig{hir: GetFlag(cmd, "hir"), mir: GetFlag(cmd, "mir"), air: GetFlag(cmd, "air")}
+		//
+		if !cfg.hir && !cfg.mir && !cfg.air {
+			cfg.hir = true
+		}
+		// Construct oracle
+		oracle := failureOracle(lowerSchemas(hirSchema, cfg), handle)
+		//
+		if !oracle(columns) {
+			fmt.Printf("trace is not rejected by constraint \"%s\"\n", handle)
+			os.Exit(2)
+		}
+		// Go!
+		shrunk := sc.ShrinkTrace(columns, sc.InputPaddingOf(hirSchema), sc.InputColumnsOf(hirSchema), oracle)
+		//
+		log.Infof("shrunk trace from %d cells to %d cells", traceCells(columns), traceCells(shrunk))
+		writeTraceFile(args[1], shrunk)
+	},
+}
+
+func init() {
+	traceCmd.AddCommand(traceShrinkCmd)
+	traceShrinkCmd.Flags().StringArray("schema", []string{}, "constraint file(s) against which to check the trace")
+	traceShrinkCmd.Flags().String("constraint", "", "handle of failing constraint to preserve")
+	traceShrinkCmd.Flags().Bool("debug", false, "enable debugging constraints")
+	traceShrinkCmd.Flags().Bool("hir", false, "preserve failure at HIR level")
+	traceShrinkCmd.Flags().Bool("mir", false, "preserve failure at MIR level")
+	traceShrinkCmd.Flags().Bool("air", false, "preserve failure at AIR level")
+}
+
+// Construct an oracle which holds for a given set of input columns when the
+// constraint(s) with the given handle reject the trace built from them at every
+// given IR level.
+func failureOracle(schemas []loweredSchema, handle string) sc.TraceOracle {
+	var oracles []sc.TraceOracle
+	//
+	filter := sc.NameFilter(regexp.MustCompile(fmt.Sprintf("^%s$", regexp.QuoteMeta(handle))))
+	//
+	for _, s := range schemas {
+		var constraints []sc.Constraint
+		//
+		for iter := sc.SelectConstraints(s.schema.Constraints(), filter); iter.HasNext(); {
+			constraints = append(constraints, iter.Next())
+		}
+		//
+		if len(constraints) == 0 {
+			fmt.Printf("unknown constraint \"%s\" at %s level\n", handle, s.ir)
+			os.Exit(2)
+		}
+		// Only required columns need be computed
+		builder := sc.NewTraceBuilder(s.schema).Columns(sc.SelectedColumns(s.schema, filter))
+		oracles = append(oracles, sc.RejectedBy(builder, constraints...))
+	}
+	//
+	return func(columns []trace.RawColumn) bool {
+		for _, oracle := range oracles {
+			if !oracle(columns) {
+				return false
+			}
+		}
+		//
+		return true
+	}
+}
+
+// Determine the total number of cells in a given set of columns.
+func traceCells(columns []trace.RawColumn) uint {
+	var n uint
+	//
+	for _, col := range columns {
+		n += col.Data.Len()
+	}
+	//
+	return n
+}
+
+// Determine the input columns of a given set of columns, discarding all others
+// (e.g. unknown columns) since these can only give rise to warnings when the
+// trace is built.
+func inputColumns(columns []trace.RawColumn, isInput func(trace.RawColumn) bool) []trace.RawColumn {
+	var inputs []trace.RawColumn
+	//
+	for _, col := range columns {
+		if isInput(col) {
+			inputs = append(inputs, col)
+		} else {
+			log.Warnf("ignoring non-input column %s", col.QualifiedName())
+		}
+	}
+	//
+	return inputs
+}
@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"os"
 
-	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/hir"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	tr "github.com/consensys/go-corset/pkg/trace"
@@ -20,7 +20,11 @@ var testCmd = &cobra.Command{
 	Short: "Test a set of constraints (e.g. using randomly inputs). [EXPERIMENTAL]",
 	Long: `Check a given trace against a set of constraints.
 	Constraints can be given either as lisp or bin files.  The goal is to check for
-	 properties which don't hold on valid traces`,
+	 properties which don't hold on valid traces.  Traces are generated at random
+	 (from a given seed), with values drawn from the declared type of each column
+	 and heights chosen independently for each module.  The first counterexample
+	 found (if any) is written out as a JSON trace.  Alternatively, the tests
+	 embedded in the constraints (i.e. using deftest) can be checked instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var cfg checkConfig
 		var hirSchema *hir.Schema
@@ -44,12 +48,19 @@ var testCmd = &cobra.Command{
 		cfg.reportPadding = GetUint(cmd, "report-context")
 		// cfg.strict = !GetFlag(cmd, "warn")
 		// cfg.quiet = GetFlag(cmd, "quiet")
-		cfg.padding.Right = GetUint(cmd, "padding")
+		cfg.padding = GetRange(cmd, "padding")
+		cfg.rows = util.NewPair[uint, uint](0, math.MaxUint)
 		cfg.parallelExpansion = !GetFlag(cmd, "sequential")
 		cfg.batchSize = GetUint(cmd, "batch")
+		cfg.spillage = GetSpillage(cmd, "spillage")
 		cfg.ansiEscapes = GetFlag(cmd, "ansi-escapes")
-		// TODO: support true ranges
-		cfg.padding.Left = cfg.padding.Right
+		// Parse padding protocol (if given)
+		if protocol, err := parsePaddingProtocol(GetStringArray(cmd, "pad-to")); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		} else {
+			cfg.paddingProtocol = protocol
+		}
 		// Normalise IRs
 		if !cfg.hir && !cfg.mir && !cfg.air {
 			// If IR not specified default to running all.
@@ -59,76 +70,146 @@ var testCmd = &cobra.Command{
 		stats := util.NewPerfStats()
 		// Parse constraints
 		hirSchema = readSchema(cfg.stdlib, false, legacy, args)
+		cfg.sources = hirSchema
 		//
 		stats.Log("Reading constraints file")
+		// Check inline tests (if requested)
+		if GetFlag(cmd, "inline") {
+			if !runInlineTests(hirSchema, cfg) {
+				os.Exit(1)
+			}
+			//
+			return
+		}
+		// Determine seed
+		seed := GetUint64(cmd, "seed")
+		//
+		if !cmd.Flags().Changed("seed") {
+			seed = rand.Uint64()
+		}
 		//
-		if ok := runTests(2, cfg, hirSchema); !ok {
+		log.Infof("testing with seed %d", seed)
+		// Construct generator
+		iterations := GetUint(cmd, "iterations")
+		generator := sc.NewTraceGenerator(hirSchema, seed, iterations, GetUint(cmd, "max-height"))
+		//
+		if ok := runTests(generator, GetString(cmd, "counterexample"), cfg, hirSchema); !ok {
 			// Error signal
 			os.Exit(1)
 		}
 	},
 }
 
-func runTests(nrows uint, cfg checkConfig, hirSchema *hir.Schema) bool {
-	ok := true
-	// TODO: this only tests the happy path.
-	for iter := initTraceEnumerator(nrows, hirSchema); iter.HasNext(); {
-		// Read out next trace to test
-		trace := iter.Next()
+// Test a given schema against randomly generated traces, stopping at the first
+// counterexample found (i.e. a trace which is accepted by the constraints, but
+// for which some property does not hold).  The counterexample is written to the
+// given file.  This returns false if a counterexample was found.
+func runTests(generator util.Enumerator[[]tr.RawColumn], counterexample string, cfg checkConfig,
+	hirSchema *hir.Schema) bool {
+	// Lower constraints (once)
+	schemas := lowerSchemas(hirSchema, cfg)
+	//
+	for i := 0; generator.HasNext(); i++ {
+		// Generate next trace to test
+		columns := generator.Next()
 		// Test this specific trace
-		ok = testTraceWithLowering(trace, hirSchema, cfg) && ok
+		if !testTraceWithLowering(columns, schemas, cfg) {
+			writeTraceFile(counterexample, columns)
+			log.Errorf("counterexample found after %d trace(s) written to %s", i+1, counterexample)
+			//
+			return false
+		}
 	}
 	//
-	return ok
+	return true
 }
 
-// Check a given trace is consistently accepted (or rejected) at the different
-// IR levels.
-func testTraceWithLowering(trace tr.Trace, schema *hir.Schema, cfg checkConfig) bool {
+// Check whether a given trace is a counterexample at any of the given IR
+// levels.  That is, whether it is accepted by the constraints at some level but
+// some assertion does not hold.
+func testTraceWithLowering(columns []tr.RawColumn, schemas []loweredSchema, cfg checkConfig) bool {
 	ok := true
-	// Check whether assertions hold for this trace
-	asserts := sc.Asserts(cfg.batchSize, schema, trace)
-	// Process individually
-	if cfg.hir {
-		ok = testTrace("HIR", asserts, trace, schema, cfg) && ok
-	}
-
-	if cfg.mir {
-		ok = testTrace("MIR", asserts, trace, schema.LowerToMir(), cfg) && ok
-	}
-
-	if cfg.air {
-		ok = testTrace("AIR", asserts, trace, schema.LowerToMir().LowerToAir(), cfg) && ok
+	//
+	for _, s := range schemas {
+		ok = testTrace(s.ir, columns, s.schema, cfg) && ok
 	}
-
+	//
 	return ok
 }
 
-func testTrace(ir string, asserts []sc.Failure, trace tr.Trace, schema sc.Schema, cfg checkConfig) bool {
-	ok := true
+func testTrace(ir string, columns []tr.RawColumn, schema sc.Schema, cfg checkConfig) bool {
+	builder := sc.NewTraceBuilder(schema).Expand(cfg.expand).Parallel(cfg.parallelExpansion).Spillage(cfg.spillage)
 	//
 	for n := cfg.padding.Left; n <= cfg.padding.Right; n++ {
-		// Check constraints
-		if errs := sc.Accepts(cfg.batchSize, schema, trace); len(asserts) > 0 && len(errs) == 0 {
+		trace, _ := builder.PaddingProtocol(paddingProtocolFor(n, cfg)).Build(columns)
+		// Traces which cannot be expanded, or which are rejected, are ignored
+		if trace == nil || len(sc.Accepts(cfg.batchSize, schema, trace)) != 0 {
+			continue
+		}
+		// Check whether assertions hold for this trace
+		if asserts := sc.Asserts(cfg.batchSize, schema, trace); len(asserts) != 0 {
 			// Trace accepts, but at least one assertion has failed.
-			reportFailures(ir, asserts, trace, cfg)
+			reportFailures(ir, asserts, trace, schema, cfg)
 			// Indicate all is not well
-			ok = false
+			return false
 		}
 	}
 	// Done
-	return ok
+	return true
 }
 
-// Constructs a (lazy) enumerator over the set of traces to be used for testing.
-func initTraceEnumerator(nrows uint, hirSchema *hir.Schema) util.Enumerator[tr.Trace] {
-	// NOTE: This is really a temporary solution for now.  It doesn't handle
-	// length multipliers.  It doesn't allow for modules with different heights.
-	// It uses a fixed pool.
-	pool := []fr.Element{fr.NewElement(0), fr.NewElement(1), fr.NewElement(2),
-		fr.NewElement(3), fr.NewElement(4), fr.NewElement(5)}
-	// Done
-	return sc.NewTraceEnumerator(nrows, hirSchema, pool)
+// Check the tests embedded in a given schema (i.e. using deftest) at each of the
+// given IR levels, reporting whether each passes or fails.  A test passes when
+// its trace is accepted (or rejected) as expected at every level, and for every
+// amount of padding.  This returns false if any test failed.
+func runInlineTests(hirSchema *hir.Schema, cfg checkConfig) bool {
+	var (
+		schemas = lowerSchemas(hirSchema, cfg)
+		tests   = hirSchema.InlineTests()
+		failed  uint
+	)
+	//
+	for _, test := range tests {
+		if msg := testInline(test, schemas, cfg); msg != "" {
+			fmt.Printf("FAIL %s (%s)\n", test.Name, msg)
+			failed++
+		} else {
+			fmt.Printf("PASS %s\n", test.Name)
+		}
+	}
+	//
+	fmt.Printf("%d of %d test(s) passed\n", uint(len(tests))-failed, len(tests))
+	//
+	return failed == 0
+}
+
+// Check a given inline test at each of the given IR levels, returning a message
+// describing the first unexpected outcome (or the empty string if there was
+// none).  A trace which cannot be built is considered to be rejected.
+func testInline(test hir.InlineTest, schemas []loweredSchema, cfg checkConfig) string {
+	columns := test.Trace()
+	//
+	for _, s := range schemas {
+		builder := traceBuilderFor(s.schema, nil, cfg)
+		//
+		for n := cfg.padding.Left; n <= cfg.padding.Right; n++ {
+			trace, errs := builder.PaddingProtocol(paddingProtocolFor(n, cfg)).Build(columns)
+			//
+			for _, err := range errs {
+				log.Debugf("test %s: %s", test.Name, err)
+			}
+			//
+			accepted := trace != nil && len(sc.Accepts(cfg.batchSize, s.schema, trace)) == 0
+			//
+			if accepted && !test.Accepts {
+				return fmt.Sprintf("accepted at %s with padding %d, expected rejection", s.ir, n)
+			} else if !accepted && test.Accepts {
+				return fmt.Sprintf("rejected at %s with padding %d, expected acceptance", s.ir, n)
+			}
+		}
+	}
+	//
+	return ""
 }
 
 func init() {
@@ -140,9 +221,17 @@ func init() {
 	testCmd.Flags().Bool("mir", false, "check at MIR level")
 	testCmd.Flags().Bool("air", false, "check at AIR level")
 	testCmd.Flags().Bool("sequential", false, "perform sequential trace expansion")
-	testCmd.Flags().Uint("padding", 0, "specify amount of (front) padding to apply")
+	testCmd.Flags().String("padding", "0",
+		"specify amount of (front) padding to apply, or a range of amounts to check (e.g. 0..4)")
+	testCmd.Flags().StringArray("pad-to", []string{},
+		"specify protocol for padding modules after padding applied (pow2 or module=height)")
 	testCmd.Flags().UintP("batch", "b", math.MaxUint, "specify batch size for constraint checking")
-	testCmd.Flags().Int("spillage", -1,
-		"specify amount of splillage to account for (where -1 indicates this should be inferred)")
+	testCmd.Flags().StringArray("spillage", []string{},
+		"specify amount of spillage to apply, either globally (n) or per module (module=n), instead of inferring it (-1)")
 	testCmd.Flags().Bool("ansi-escapes", true, "specify whether to allow ANSI escapes or not (e.g. for colour reports)")
+	testCmd.Flags().Uint64("seed", 0, "specify seed for random trace generation (otherwise chosen at random)")
+	testCmd.Flags().Uint("iterations", 100, "specify number of random traces to generate")
+	testCmd.Flags().Uint("max-height", 4, "specify maximum height of any module in a generated trace")
+	testCmd.Flags().Bool("inline", false, "check tests embedded in the constraints (i.e. using deftest)")
+	testCmd.Flags().String("counterexample", "counterexample.json", "specify file to which a counterexample is written")
 }
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"math"
+	"math/big"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/trace"
 	"github.com/consensys/go-corset/pkg/util"
 	"github.com/spf13/cobra"
@@ -14,13 +20,20 @@ import (
 
 // traceCmd represents the trace command for manipulating traces.
 var traceCmd = &cobra.Command{
-	Use:   "trace [flags] trace_file",
+	Use:   "trace [flags] trace_file [constraint_file(s)]",
 	Short: "Operate on a trace file.",
 	Long: `Operate on a trace file, such as converting
 	it from one format (e.g. lt) to another (e.g. json),
-	or filtering out modules, or listing columns, etc.`,
+	or filtering out modules, or listing columns, etc.
+	Constraints can optionally be given, in which case
+	they determine how column values are displayed.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) != 1 {
+		var (
+			displays map[string]sc.Display
+			types    map[string]sc.Type
+		)
+		//
+		if len(args) < 1 {
 			fmt.Println(cmd.UsageString())
 			os.Exit(1)
 		}
@@ -35,6 +48,14 @@ var traceCmd = &cobra.Command{
 		max_width := GetUint(cmd, "max-width")
 		filter := GetString(cmd, "filter")
 		output := GetString(cmd, "out")
+		topK := GetUint(cmd, "top")
+		// Read constraints (if applicable)
+		if len(args) > 1 {
+			stdlib := !GetFlag(cmd, "no-stdlib")
+			legacy := GetFlag(cmd, "legacy")
+			schema := readSchema(stdlib, false, legacy, args[1:])
+			displays, types = columnDisplays(schema), columnTypes(schema)
+		}
 		// construct filters
 		if filter != "" {
 			cols = filterColumns(cols, filter)
@@ -43,7 +64,7 @@ var traceCmd = &cobra.Command{
 			sliceColumns(cols, start, end)
 		}
 		if list {
-			listColumns(cols, includes)
+			listColumns(cols, includes, types, topK)
 		}
 		if stats {
 			summaryStats(cols)
@@ -54,7 +75,7 @@ var traceCmd = &cobra.Command{
 		}
 
 		if print {
-			printTrace(start, max_width, cols)
+			printTrace(start, max_width, cols, displays)
 		}
 	},
 }
@@ -71,6 +92,35 @@ func init() {
 	traceCmd.Flags().Uint("max-width", 32, "specify maximum display width for a column")
 	traceCmd.Flags().StringP("out", "o", "", "Specify output file to write trace")
 	traceCmd.Flags().StringP("filter", "f", "", "Filter columns matching regex")
+	traceCmd.Flags().Uint("top", 3, "specify number of most frequent values to show in column histograms")
+}
+
+// Determine the display of each data column in a given schema, indexed by its
+// qualified name (i.e. as it would appear in a trace file).
+func columnDisplays(schema *hir.Schema) map[string]sc.Display {
+	displays := make(map[string]sc.Display)
+	//
+	for i, it := uint(0), schema.InputColumns(); it.HasNext(); i++ {
+		col := it.Next()
+		mod := schema.Modules().Nth(col.Context.Module())
+		displays[trace.QualifiedColumnName(mod.Name, col.Name)] = schema.ColumnDisplay(i)
+	}
+	//
+	return displays
+}
+
+// Determine the declared type of each data column in a given schema, indexed by
+// its qualified name (i.e. as it would appear in a trace file).
+func columnTypes(schema *hir.Schema) map[string]sc.Type {
+	types := make(map[string]sc.Type)
+	//
+	for it := schema.InputColumns(); it.HasNext(); {
+		col := it.Next()
+		mod := schema.Modules().Nth(col.Context.Module())
+		types[trace.QualifiedColumnName(mod.Name, col.Name)] = col.DataType
+	}
+	//
+	return types
 }
 
 // Construct a new trace containing only those columns from the original who
@@ -110,7 +160,9 @@ func sliceColumns(cols []trace.RawColumn, start uint, end uint) {
 	}
 }
 
-func printTrace(start uint, max_width uint, cols []trace.RawColumn) {
+// Print a given set of columns, where the values of each column are shown
+// according to its display (if known) or in hexadecimal notation otherwise.
+func printTrace(start uint, max_width uint, cols []trace.RawColumn, displays map[string]sc.Display) {
 	n := uint(len(cols))
 	height := maxHeightColumns(cols)
 	tbl := util.NewTablePrinter(1+height, 1+n)
@@ -121,12 +173,17 @@ func printTrace(start uint, max_width uint, cols []trace.RawColumn) {
 
 	for i := uint(0); i < n; i++ {
 		ith := cols[i].Data
+		display := displays[cols[i].QualifiedName()]
 		tbl.Set(0, i+1, cols[i].QualifiedName())
 
 		for j := uint(0); j < ith.Len(); j++ {
 			jth := ith.Get(j)
-
-			tbl.Set(j+1, i+1, jth.Text(16))
+			// Columns without a declared display retain the original format
+			if display == sc.DISPLAY_DEFAULT {
+				tbl.Set(j+1, i+1, jth.Text(16))
+			} else {
+				tbl.Set(j+1, i+1, display.Format(jth))
+			}
 		}
 	}
 	//
@@ -134,7 +191,10 @@ func printTrace(start uint, max_width uint, cols []trace.RawColumn) {
 	tbl.Print()
 }
 
-func listColumns(tr []trace.RawColumn, includes []string) {
+// List the columns of a trace along with the requested summaries of each.  When
+// declared types are known, columns holding values outside their declared type
+// are also reported.
+func listColumns(tr []trace.RawColumn, includes []string, types map[string]sc.Type, topK uint) {
 	summarisers := selectColumnSummarisers(includes)
 	m := 1 + uint(len(summarisers))
 	n := uint(len(tr))
@@ -152,7 +212,8 @@ func listColumns(tr []trace.RawColumn, includes []string) {
 		// Launch summarisers
 		go func(index uint) {
 			// Apply summarisers to column
-			row := summariseColumn(tr[index], summarisers)
+			ctx := summaryContext{types[tr[index].QualifiedName()], topK}
+			row := summariseColumn(tr[index], ctx, summarisers)
 			// Package result
 			c <- util.NewPair(index, row)
 		}(i)
@@ -167,6 +228,37 @@ func listColumns(tr []trace.RawColumn, includes []string) {
 	//
 	tbl.SetMaxWidths(64)
 	tbl.Print()
+	// Flag columns exceeding their declared type
+	for _, col := range tr {
+		if datatype, ok := types[col.QualifiedName()]; ok {
+			reportOutOfTypeValues(col, datatype)
+		}
+	}
+}
+
+// Report the number of values in a given column which are not accepted by its
+// declared type, along with the first such value (if any).
+func reportOutOfTypeValues(col trace.RawColumn, datatype sc.Type) {
+	var (
+		count uint
+		first uint
+	)
+	//
+	for i := uint(0); i < col.Data.Len(); i++ {
+		if !datatype.Accept(col.Data.Get(i)) {
+			if count == 0 {
+				first = i
+			}
+			//
+			count++
+		}
+	}
+	//
+	if count > 0 {
+		ith := col.Data.Get(first)
+		fmt.Printf("column %s has %d value(s) exceeding declared type %s (e.g. %s on row %d)\n", col.QualifiedName(),
+			count, datatype, ith.String(), first)
+	}
 }
 
 func selectColumnSummarisers(includes []string) []ColSummariser {
@@ -224,14 +316,14 @@ func flattenIncludes(includes []string) []string {
 	return includes
 }
 
-func summariseColumn(column trace.RawColumn, summarisers []ColSummariser) []string {
+func summariseColumn(column trace.RawColumn, ctx summaryContext, summarisers []ColSummariser) []string {
 	m := 1 + uint(len(summarisers))
 	//
 	row := make([]string, m)
 	row[0] = column.QualifiedName()
 	// Generate each summary
 	for j := 0; j < len(summarisers); j++ {
-		row[j+1] = summarisers[j].summary(column)
+		row[j+1] = summarisers[j].summary(column, ctx)
 	}
 	// Done
 	return row
@@ -260,7 +352,16 @@ func summaryStats(tr []trace.RawColumn) {
 type ColSummariser struct {
 	name        string
 	description string
-	summary     func(trace.RawColumn) string
+	summary     func(trace.RawColumn, summaryContext) string
+}
+
+// Provides contextual information about a column being summarised.
+type summaryContext struct {
+	// Declared type of the column, or nil if this is unknown (e.g. because no
+	// constraints were given).
+	datatype sc.Type
+	// Number of most frequent values to show in a histogram.
+	topK uint
 }
 
 var colSummarisers []ColSummariser = []ColSummariser{
@@ -268,7 +369,13 @@ var colSummarisers []ColSummariser = []ColSummariser{
 	{"bitwidth", "bitwidth for column as specified in trace file", bitWidthSummariser},
 	{"bytes", "total bytes required for column", bytesSummariser},
 	{"elements", "number of unique elements in column", uniqueElementsSummariser},
-	{"entropy", "number of lines in column whose value differs from previous line", entropySummariser},
+	{"changes", "percentage of lines in column whose value differs from previous line", changesSummariser},
+	{"min", "smallest value in column", minSummariser},
+	{"max", "largest value in column", maxSummariser},
+	{"histogram", "most frequent values in column (see --top)", histogramSummariser},
+	{"zeros", "percentage of lines in column which are zero", zerosSummariser},
+	{"maxwidth", "largest bitwidth of any value in column, compared with its declared type", maxWidthSummariser},
+	{"run", "length of the longest run of identical values in column", longestRunSummariser},
 }
 
 // Used to show the available options on the command-line.
@@ -282,15 +389,15 @@ func summariserOptions() string {
 	return summarisers
 }
 
-func lineCountSummariser(col trace.RawColumn) string {
+func lineCountSummariser(col trace.RawColumn, _ summaryContext) string {
 	return fmt.Sprintf("%d", col.Data.Len())
 }
 
-func bitWidthSummariser(col trace.RawColumn) string {
+func bitWidthSummariser(col trace.RawColumn, _ summaryContext) string {
 	return fmt.Sprintf("%d", col.Data.BitWidth())
 }
 
-func bytesSummariser(col trace.RawColumn) string {
+func bytesSummariser(col trace.RawColumn, _ summaryContext) string {
 	bitwidth := col.Data.BitWidth()
 	byteWidth := bitwidth / 8
 	// Determine proper bytewidth
@@ -301,7 +408,7 @@ func bytesSummariser(col trace.RawColumn) string {
 	return fmt.Sprintf("%d", col.Data.Len()*byteWidth)
 }
 
-func uniqueElementsSummariser(col trace.RawColumn) string {
+func uniqueElementsSummariser(col trace.RawColumn, _ summaryContext) string {
 	data := col.Data
 	elems := util.NewHashSet[util.BytesKey](data.Len() / 2)
 	// Add all the elements
@@ -313,26 +420,24 @@ func uniqueElementsSummariser(col trace.RawColumn) string {
 	return fmt.Sprintf("%d", elems.Size())
 }
 
-func entropySummariser(col trace.RawColumn) string {
+func changesSummariser(col trace.RawColumn, _ summaryContext) string {
 	data := col.Data
-	entropy := 0.0
+	changes := 0.0
 	//
-	if data.Len() > 0 {
-		last := data.Get(0)
-		count := 1
-		// Count all rows which have same value as previous row.
+	if data.Len() > 1 {
+		count := 0
+		// Count all rows which differ from the previous row.
 		for i := uint(1); i < data.Len(); i++ {
-			ith := data.Get(i)
-			if last.Cmp(&ith) == 0 {
+			ith, last := data.Get(i), data.Get(i-1)
+			if last.Cmp(&ith) != 0 {
 				count++
 			}
 		}
-		// Calculate entropy
-		entropy = float64(count) / float64(data.Len())
-		entropy *= 100
+		// Calculate percentage of rows (which have a previous row)
+		changes = 100 * float64(count) / float64(data.Len()-1)
 	}
 	// Done
-	return fmt.Sprintf("%2.1f%%", entropy)
+	return fmt.Sprintf("%2.1f%%", changes)
 }
 
 // ============================================================================
@@ -367,3 +472,130 @@ func trWidthSummariser(lowWidth uint, highWidth uint) traceSummariser {
 		},
 	}
 }
+
+func minSummariser(col trace.RawColumn, _ summaryContext) string {
+	return extremeValue(col, -1)
+}
+
+func maxSummariser(col trace.RawColumn, _ summaryContext) string {
+	return extremeValue(col, 1)
+}
+
+// Determine the extreme value of a column, where the sign determines whether
+// the smallest (i.e. -1) or largest (i.e. 1) value is returned.
+func extremeValue(col trace.RawColumn, sign int) string {
+	data := col.Data
+	//
+	if data.Len() == 0 {
+		return "-"
+	}
+	//
+	extreme := data.Get(0)
+	//
+	for i := uint(1); i < data.Len(); i++ {
+		ith := data.Get(i)
+		if ith.Cmp(&extreme) == sign {
+			extreme = ith
+		}
+	}
+	//
+	return extreme.String()
+}
+
+func histogramSummariser(col trace.RawColumn, ctx summaryContext) string {
+	var (
+		data   = col.Data
+		counts = make(map[fr.Element]uint)
+		values []fr.Element
+	)
+	// Count occurrences of each value
+	for i := uint(0); i < data.Len(); i++ {
+		ith := data.Get(i)
+		//
+		if counts[ith] == 0 {
+			values = append(values, ith)
+		}
+		//
+		counts[ith]++
+	}
+	// Sort by decreasing frequency, then increasing value
+	slices.SortFunc(values, func(l fr.Element, r fr.Element) int {
+		if c := cmp.Compare(counts[r], counts[l]); c != 0 {
+			return c
+		}
+		//
+		return l.Cmp(&r)
+	})
+	//
+	entries := make([]string, 0, ctx.topK)
+	//
+	for i := 0; i < len(values) && uint(i) < ctx.topK; i++ {
+		entries = append(entries, fmt.Sprintf("%s (x%d)", values[i].String(), counts[values[i]]))
+	}
+	//
+	if uint(len(values)) > ctx.topK {
+		entries = append(entries, "...")
+	}
+	//
+	return strings.Join(entries, ", ")
+}
+
+func zerosSummariser(col trace.RawColumn, _ summaryContext) string {
+	data := col.Data
+	zeros := 0.0
+	//
+	if data.Len() > 0 {
+		count := 0
+		//
+		for i := uint(0); i < data.Len(); i++ {
+			if ith := data.Get(i); ith.IsZero() {
+				count++
+			}
+		}
+		//
+		zeros = 100 * float64(count) / float64(data.Len())
+	}
+	// Done
+	return fmt.Sprintf("%2.1f%%", zeros)
+}
+
+func maxWidthSummariser(col trace.RawColumn, ctx summaryContext) string {
+	var (
+		data  = col.Data
+		width = 0
+		val   big.Int
+	)
+	//
+	for i := uint(0); i < data.Len(); i++ {
+		ith := data.Get(i)
+		ith.BigInt(&val)
+		width = max(width, val.BitLen())
+	}
+	// Compare against declared type (if known)
+	if ctx.datatype == nil {
+		return fmt.Sprintf("%d", width)
+	} else if uint(width) > ctx.datatype.BitWidth() {
+		return fmt.Sprintf("%d > %s (!)", width, ctx.datatype)
+	}
+	//
+	return fmt.Sprintf("%d <= %s", width, ctx.datatype)
+}
+
+func longestRunSummariser(col trace.RawColumn, _ summaryContext) string {
+	data := col.Data
+	longest := min(data.Len(), 1)
+	//
+	for i, run := uint(1), uint(1); i < data.Len(); i++ {
+		ith, last := data.Get(i), data.Get(i-1)
+		//
+		if ith.Cmp(&last) == 0 {
+			run++
+		} else {
+			run = 1
+		}
+		//
+		longest = max(longest, run)
+	}
+	// Done
+	return fmt.Sprintf("%d", longest)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// ============================================================================
+// Column Summarisers
+// ============================================================================
+
+func Test_Summarisers_01(t *testing.T) {
+	checkSummary(t, "lines", nil, 3, "6", "0")
+	checkSummary(t, "elements", nil, 3, "3", "0")
+}
+
+func Test_Summarisers_02(t *testing.T) {
+	checkSummary(t, "min", nil, 3, "0", "-")
+	checkSummary(t, "max", nil, 3, "7", "-")
+}
+
+func Test_Summarisers_03(t *testing.T) {
+	// Most frequent values first, with ties broken by value
+	checkSummary(t, "histogram", nil, 3, "3 (x3), 0 (x2), 7 (x1)", "")
+	checkSummary(t, "histogram", nil, 2, "3 (x3), 0 (x2), ...", "")
+	checkSummary(t, "histogram", nil, 0, "...", "")
+}
+
+func Test_Summarisers_04(t *testing.T) {
+	checkSummary(t, "zeros", nil, 3, "33.3%", "0.0%")
+}
+
+func Test_Summarisers_05(t *testing.T) {
+	// Observed bitwidth is compared against declared type (if known)
+	checkSummary(t, "maxwidth", nil, 3, "3", "0")
+	checkSummary(t, "maxwidth", sc.NewUintType(8), 3, "3 <= u8", "0 <= u8")
+	checkSummary(t, "maxwidth", sc.NewUintType(2), 3, "3 > u2 (!)", "0 <= u2")
+}
+
+func Test_Summarisers_06(t *testing.T) {
+	checkSummary(t, "run", nil, 3, "3", "0")
+}
+
+func Test_Summarisers_07(t *testing.T) {
+	// Three of the five rows following the first differ from their predecessor
+	checkSummary(t, "changes", nil, 3, "60.0%", "0.0%")
+}
+
+// Check the summary of a given summariser for a small fixed column, and for an
+// empty column.
+func checkSummary(t *testing.T, name string, datatype sc.Type, topK uint, expected string, empty string) {
+	summariser := selectColumnSummarisers([]string{name})[0]
+	ctx := summaryContext{datatype, topK}
+	//
+	column := trace.RawColumn{Module: "", Name: "X", Data: rawData(0, 3, 3, 3, 0, 7)}
+	if actual := summariser.summary(column, ctx); actual != expected {
+		t.Errorf("expected %s summary \"%s\", got \"%s\"", name, expected, actual)
+	}
+	//
+	column = trace.RawColumn{Module: "", Name: "X", Data: rawData()}
+	if actual := summariser.summary(column, ctx); actual != empty {
+		t.Errorf("expected %s summary \"%s\" of empty column, got \"%s\"", name, empty, actual)
+	}
+}
@@ -7,15 +7,19 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/consensys/go-corset/pkg/binfile"
 	"github.com/consensys/go-corset/pkg/corset"
 	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	"github.com/consensys/go-corset/pkg/trace"
 	"github.com/consensys/go-corset/pkg/trace/json"
 	"github.com/consensys/go-corset/pkg/trace/lt"
+	"github.com/consensys/go-corset/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -53,6 +57,17 @@ func GetUint(cmd *cobra.Command, flag string) uint {
 	return r
 }
 
+// GetUint64 gets an expected uint64, or panic if an error arises.
+func GetUint64(cmd *cobra.Command, flag string) uint64 {
+	r, err := cmd.Flags().GetUint64(flag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(4)
+	}
+
+	return r
+}
+
 // GetString gets an expected string, or panic if an error arises.
 func GetString(cmd *cobra.Command, flag string) string {
 	r, err := cmd.Flags().GetString(flag)
@@ -75,6 +90,169 @@ func GetStringArray(cmd *cobra.Command, flag string) []string {
 	return r
 }
 
+// GetRange gets an expected (inclusive) range of unsigned integers, given either
+// as a single value "n" or as "lo..hi", or panic if an error arises.
+func GetRange(cmd *cobra.Command, flag string) util.Pair[uint, uint] {
+	r, err := parseRange(GetString(cmd, flag))
+	if err != nil {
+		fmt.Printf("invalid --%s: %s\n", flag, err)
+		os.Exit(4)
+	}
+
+	return r
+}
+
+// GetSpillage gets an expected spillage override, given as a sequence of
+// specifications which are either "n" (i.e. for all modules) or "module=n"
+// (i.e. for a specific module), or panic if an error arises.  When no
+// specifications are given, nil is returned (i.e. spillage is inferred).
+func GetSpillage(cmd *cobra.Command, flag string) sc.SpillageOverride {
+	spillage, err := parseSpillage(GetStringArray(cmd, flag))
+	if err != nil {
+		fmt.Printf("invalid --%s: %s\n", flag, err)
+		os.Exit(4)
+	}
+
+	return spillage
+}
+
+// GetHeights gets expected fixed module heights, given as a sequence of
+// specifications which are either "n" (i.e. for all modules) or "module=n"
+// (i.e. for a specific module), or panic if an error arises.  When no
+// specifications are given, nil is returned (i.e. no heights are fixed).
+func GetHeights(cmd *cobra.Command, flag string) sc.ModuleHeights {
+	heights, err := parseHeights(GetStringArray(cmd, flag))
+	if err != nil {
+		fmt.Printf("invalid --%s: %s\n", flag, err)
+		os.Exit(4)
+	}
+
+	return heights
+}
+
+// Parse fixed module heights from a given set of specifications.  Each
+// specification is either "n" (i.e. for all modules) or "module=n" (i.e. for a
+// specific module), where modules given explicitly take precedence.  When no
+// specifications are given, nil is returned (i.e. no heights are fixed).
+func parseHeights(specs []string) (sc.ModuleHeights, error) {
+	var (
+		global  *uint
+		modules map[string]uint = make(map[string]uint)
+	)
+	//
+	for _, spec := range specs {
+		split := strings.Split(spec, "=")
+		//
+		if n, err := strconv.ParseUint(split[len(split)-1], 10, 0); err != nil || len(split) > 2 {
+			return nil, fmt.Errorf("malformed height \"%s\"", spec)
+		} else if m := uint(n); len(split) == 1 {
+			global = &m
+		} else {
+			modules[split[0]] = m
+		}
+	}
+	//
+	if global == nil && len(modules) == 0 {
+		return nil, nil
+	}
+	//
+	return func(module string) (uint, bool) {
+		if n, ok := modules[module]; ok {
+			return n, true
+		} else if global != nil {
+			return *global, true
+		}
+		//
+		return 0, false
+	}, nil
+}
+
+// Parse a spillage override from a given set of specifications.  Each
+// specification is either "n" (i.e. for all modules) or "module=n" (i.e. for a
+// specific module), where modules given explicitly take precedence.  An amount
+// of -1 indicates spillage should be inferred (e.g. "-1" or "module=-1").  When
+// no amounts are given, nil is returned (i.e. spillage is inferred).
+func parseSpillage(specs []string) (sc.SpillageOverride, error) {
+	var (
+		global  *uint
+		modules map[string]*uint = make(map[string]*uint)
+	)
+	//
+	for _, spec := range specs {
+		split := strings.Split(spec, "=")
+		//
+		if n, err := parseSpillageAmount(split[len(split)-1]); err != nil || len(split) > 2 {
+			return nil, fmt.Errorf("malformed spillage \"%s\"", spec)
+		} else if len(split) == 1 {
+			global = n
+		} else {
+			modules[split[0]] = n
+		}
+	}
+	//
+	if global == nil && len(modules) == 0 {
+		return nil, nil
+	}
+	//
+	return func(module string) (uint, bool) {
+		n, ok := modules[module]
+		//
+		if !ok {
+			n = global
+		}
+		//
+		if n == nil {
+			return 0, false
+		}
+		//
+		return *n, true
+	}, nil
+}
+
+// Parse an amount of spillage, where -1 indicates spillage should be inferred
+// (in which case nil is returned).
+func parseSpillageAmount(str string) (*uint, error) {
+	if str == "-1" {
+		return nil, nil
+	}
+	//
+	n, err := strconv.ParseUint(str, 10, 0)
+	if err != nil {
+		return nil, err
+	}
+	//
+	m := uint(n)
+	//
+	return &m, nil
+}
+
+// Parse an (inclusive) range of unsigned integers, given either as a single
+// value "n" (i.e. the range n..n) or as "lo..hi".
+func parseRange(str string) (util.Pair[uint, uint], error) {
+	var (
+		lo, hi uint64
+		err    error
+	)
+	//
+	bounds := strings.Split(str, "..")
+	//
+	if len(bounds) > 2 {
+		return util.NewPair[uint, uint](0, 0), fmt.Errorf("malformed range \"%s\"", str)
+	} else if lo, err = strconv.ParseUint(bounds[0], 10, 0); err != nil {
+		return util.NewPair[uint, uint](0, 0), fmt.Errorf("malformed range \"%s\"", str)
+	} else if hi = lo; len(bounds) == 2 {
+		if hi, err = strconv.ParseUint(bounds[1], 10, 0); err != nil {
+			return util.NewPair[uint, uint](0, 0), fmt.Errorf("malformed range \"%s\"", str)
+		}
+	}
+	//
+	if lo > hi {
+		return util.NewPair[uint, uint](0, 0), fmt.Errorf("empty range \"%s\"", str)
+	}
+	//
+	return util.NewPair(uint(lo), uint(hi)), nil
+}
+
 // Write a given trace file to disk
 func writeTraceFile(filename string, columns []trace.RawColumn) {
 	var err error
@@ -108,28 +286,10 @@ func writeTraceFile(filename string, columns []trace.RawColumn) {
 
 // Parse a trace file using a parser based on the extension of the filename.
 func readTraceFile(filename string) []trace.RawColumn {
-	var tr []trace.RawColumn
-	// Read data file
-	bytes, err := os.ReadFile(filename)
+	tr, err := parseTraceFile(filename)
 	// Check success
 	if err == nil {
-		// Check file extension
-		ext := path.Ext(filename)
-		//
-		switch ext {
-		case ".json":
-			tr, err = json.FromBytes(bytes)
-			if err == nil {
-				return tr
-			}
-		case ".lt":
-			tr, err = lt.FromBytes(bytes)
-			if err == nil {
-				return tr
-			}
-		default:
-			err = fmt.Errorf("Unknown trace file format: %s", ext)
-		}
+		return tr
 	}
 	// Handle error
 	fmt.Println(err)
@@ -138,6 +298,61 @@ func readTraceFile(filename string) []trace.RawColumn {
 	return nil
 }
 
+// Parse a trace file using a parser based on the extension of the filename,
+// returning an error if this fails.
+func parseTraceFile(filename string) ([]trace.RawColumn, error) {
+	// Read data file
+	bytes, err := os.ReadFile(filename)
+	// Check success
+	if err != nil {
+		return nil, err
+	}
+	// Check file extension
+	switch ext := path.Ext(filename); ext {
+	case ".json":
+		return json.FromBytes(bytes)
+	case ".lt":
+		return lt.FromBytes(bytes)
+	default:
+		return nil, fmt.Errorf("Unknown trace file format: %s", ext)
+	}
+}
+
+// Expand a list of trace files, where each is either a single file, a glob
+// pattern (e.g. "traces/*.lt") or a directory.  Directories are searched
+// recursively for any trace files (i.e. json or lt files).
+func expandTraceFiles(args []string) ([]string, error) {
+	var filenames []string
+	//
+	for _, arg := range args {
+		matches := []string{arg}
+		// Expand glob patterns
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			//
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, err
+			} else if len(matches) == 0 {
+				return nil, fmt.Errorf("no trace files matching \"%s\"", arg)
+			}
+		}
+		//
+		for _, f := range matches {
+			if info, err := os.Stat(f); err != nil {
+				return nil, err
+			} else if !info.IsDir() {
+				filenames = append(filenames, f)
+			} else if contents, err := expandDirectory(f, ".json", ".lt"); err != nil {
+				return nil, err
+			} else {
+				filenames = append(filenames, contents...)
+			}
+		}
+	}
+	//
+	return filenames, nil
+}
+
 // Read the constraints file, whilst optionally including the standard library.
 func readSchema(stdlib bool, debug bool, legacy bool, filenames []string) *hir.Schema {
 	var err error
@@ -230,7 +445,7 @@ func expandSourceFiles(filenames []string) ([]string, error) {
 			return nil, err
 		} else if info.IsDir() {
 			// This a directory, so read its contents
-			if contents, err := expandDirectory(f); err != nil {
+			if contents, err := expandDirectory(f, ".lisp"); err != nil {
 				return nil, err
 			} else {
 				expandedFilenames = append(expandedFilenames, contents...)
@@ -244,12 +459,13 @@ func expandSourceFiles(filenames []string) ([]string, error) {
 	return expandedFilenames, nil
 }
 
-// Recursively search through a given directory looking for any lisp files.
-func expandDirectory(dirname string) ([]string, error) {
+// Recursively search through a given directory looking for any files with one
+// of the given extensions (e.g. lisp files).
+func expandDirectory(dirname string, exts ...string) ([]string, error) {
 	var filenames []string
 	// Recursively walk the given directory.
 	err := filepath.Walk(dirname, func(filename string, info os.FileInfo, err error) error {
-		if !info.IsDir() && path.Ext(filename) == ".lisp" {
+		if !info.IsDir() && slices.Contains(exts, path.Ext(filename)) {
 			filenames = append(filenames, filename)
 		}
 		// Continue.
@@ -283,22 +499,22 @@ func writeHirSchema(schema *hir.Schema, legacy bool, filename string) {
 
 // Print a syntax error with appropriate highlighting.
 func printSyntaxError(err *sexp.SyntaxError) {
-	span := err.Span()
-	line := err.FirstEnclosingLine()
-	lineOffset := span.Start() - line.Start()
-	// Calculate length (ensures don't overflow line)
-	length := min(line.Length()-lineOffset, span.Length())
-	// Print error + line number
-	fmt.Printf("%s:%d:%d-%d %s\n", err.SourceFile().Filename(),
-		line.Number(), 1+lineOffset, 1+lineOffset+length, err.Message())
+	printSourceLocation(err.SourceFile().Location(err.Span()), err.Message())
+}
+
+// Print a given message along with the highlighted source line of a given
+// location.
+func printSourceLocation(loc sexp.Location, msg string) {
+	// Print message + line number
+	fmt.Printf("%s:%d:%d-%d %s\n", loc.Filename, loc.Line, 1+loc.Offset, 1+loc.Offset+loc.Length, msg)
 	// Print separator line
 	fmt.Println()
 	// Print line
-	fmt.Println(line.String())
+	fmt.Println(loc.Text)
 	// Print indent (todo: account for tabs)
-	fmt.Print(strings.Repeat(" ", lineOffset))
+	fmt.Print(strings.Repeat(" ", loc.Offset))
 	// Print highlight
-	fmt.Println(strings.Repeat("^", length))
+	fmt.Println(strings.Repeat("^", loc.Length))
 }
 
 func maxHeightColumns(cols []trace.RawColumn) uint {
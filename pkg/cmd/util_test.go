@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"testing"
+
+	sc "github.com/consensys/go-corset/pkg/schema"
+)
+
+// ============================================================================
+// Ranges
+// ============================================================================
+
+func Test_ParseRange_01(t *testing.T) {
+	checkRange(t, "0", 0, 0)
+}
+
+func Test_ParseRange_02(t *testing.T) {
+	checkRange(t, "3", 3, 3)
+}
+
+func Test_ParseRange_03(t *testing.T) {
+	checkRange(t, "0..4", 0, 4)
+}
+
+func Test_ParseRange_04(t *testing.T) {
+	checkRange(t, "2..2", 2, 2)
+}
+
+func Test_ParseRange_05(t *testing.T) {
+	checkInvalidRange(t, "3..1", "empty range \"3..1\"")
+}
+
+func Test_ParseRange_06(t *testing.T) {
+	for _, str := range []string{"", "a", "-1", "1..", "..1", "1..2..3", "1...2", "1..b"} {
+		checkInvalidRange(t, str, "malformed range \""+str+"\"")
+	}
+}
+
+func checkRange(t *testing.T, str string, lo uint, hi uint) {
+	if r, err := parseRange(str); err != nil {
+		t.Errorf("unexpected error parsing range \"%s\" (%s)", str, err)
+	} else if r.Left != lo || r.Right != hi {
+		t.Errorf("expected range %d..%d for \"%s\", got %d..%d", lo, hi, str, r.Left, r.Right)
+	}
+}
+
+func checkInvalidRange(t *testing.T, str string, msg string) {
+	if _, err := parseRange(str); err == nil {
+		t.Errorf("expected error parsing range \"%s\"", str)
+	} else if err.Error() != msg {
+		t.Errorf("expected error \"%s\" parsing range \"%s\", got \"%s\"", msg, str, err)
+	}
+}
+
+// ============================================================================
+// Spillage
+// ============================================================================
+
+func Test_ParseSpillage_01(t *testing.T) {
+	// No specifications means spillage is inferred
+	if spillage, err := parseSpillage(nil); err != nil || spillage != nil {
+		t.Errorf("expected no spillage override, got %v", err)
+	}
+}
+
+func Test_ParseSpillage_02(t *testing.T) {
+	// Global spillage applies to every module
+	spillage := checkParseSpillage(t, "2")
+	checkSpillage(t, spillage, "", 2, true)
+	checkSpillage(t, spillage, "m", 2, true)
+}
+
+func Test_ParseSpillage_03(t *testing.T) {
+	// Module spillage applies only to the given module
+	spillage := checkParseSpillage(t, "m=3")
+	checkSpillage(t, spillage, "", 0, false)
+	checkSpillage(t, spillage, "m", 3, true)
+}
+
+func Test_ParseSpillage_04(t *testing.T) {
+	// Modules given explicitly take precedence over global spillage (in any
+	// order), and the root module is identified by an empty name.
+	for _, specs := range [][]string{{"1", "m=3", "=0"}, {"m=3", "=0", "1"}} {
+		spillage := checkParseSpillage(t, specs...)
+		checkSpillage(t, spillage, "", 0, true)
+		checkSpillage(t, spillage, "m", 3, true)
+		checkSpillage(t, spillage, "n", 1, true)
+	}
+}
+
+func Test_ParseSpillage_05(t *testing.T) {
+	// Later specifications take precedence
+	spillage := checkParseSpillage(t, "1", "m=3", "2", "m=4")
+	checkSpillage(t, spillage, "", 2, true)
+	checkSpillage(t, spillage, "m", 4, true)
+}
+
+func Test_ParseSpillage_06(t *testing.T) {
+	// Global spillage of -1 means spillage is inferred
+	for _, specs := range [][]string{{"-1"}, {"2", "-1"}} {
+		if spillage, err := parseSpillage(specs); err != nil || spillage != nil {
+			t.Errorf("expected no spillage override for %v, got %v", specs, err)
+		}
+	}
+}
+
+func Test_ParseSpillage_07(t *testing.T) {
+	// Module spillage of -1 means spillage is inferred for that module
+	spillage := checkParseSpillage(t, "2", "m=-1")
+	checkSpillage(t, spillage, "", 2, true)
+	checkSpillage(t, spillage, "m", 0, false)
+	//
+	spillage = checkParseSpillage(t, "-1", "m=2")
+	checkSpillage(t, spillage, "", 0, false)
+	checkSpillage(t, spillage, "m", 2, true)
+}
+
+func Test_ParseSpillage_08(t *testing.T) {
+	for _, spec := range []string{"", "x", "-2", "m=", "m=x", "m=-2", "m=1=2", "1.5"} {
+		if _, err := parseSpillage([]string{spec}); err == nil {
+			t.Errorf("expected error parsing spillage \"%s\"", spec)
+		} else if msg := "malformed spillage \"" + spec + "\""; err.Error() != msg {
+			t.Errorf("expected error \"%s\" parsing spillage \"%s\", got \"%s\"", msg, spec, err)
+		}
+	}
+}
+
+func checkParseSpillage(t *testing.T, specs ...string) sc.SpillageOverride {
+	spillage, err := parseSpillage(specs)
+	//
+	if err != nil {
+		t.Fatalf("unexpected error parsing spillage %v (%s)", specs, err)
+	} else if spillage == nil {
+		t.Fatalf("expected spillage override for %v", specs)
+	}
+	//
+	return spillage
+}
+
+func checkSpillage(t *testing.T, spillage sc.SpillageOverride, module string, expected uint, overridden bool) {
+	if n, ok := spillage(module); ok != overridden {
+		t.Errorf("expected module '%s' overridden to be %t, got %t", module, overridden, ok)
+	} else if ok && n != expected {
+		t.Errorf("expected spillage %d for module '%s', got %d", expected, module, n)
+	}
+}
+
+// ============================================================================
+// Heights
+// ============================================================================
+
+func Test_ParseHeights_01(t *testing.T) {
+	// No specifications means no heights are fixed
+	if heights, err := parseHeights(nil); err != nil || heights != nil {
+		t.Errorf("expected no fixed heights, got %v", err)
+	}
+}
+
+func Test_ParseHeights_02(t *testing.T) {
+	// Global height applies to every module
+	heights := checkParseHeights(t, "2")
+	checkHeight(t, heights, "", 2, true)
+	checkHeight(t, heights, "m", 2, true)
+}
+
+func Test_ParseHeights_03(t *testing.T) {
+	// Modules given explicitly take precedence over global height (in any
+	// order), and a height of zero is permitted.
+	for _, specs := range [][]string{{"1", "m=3", "=0"}, {"m=3", "=0", "1"}} {
+		heights := checkParseHeights(t, specs...)
+		checkHeight(t, heights, "", 0, true)
+		checkHeight(t, heights, "m", 3, true)
+		checkHeight(t, heights, "n", 1, true)
+	}
+	//
+	heights := checkParseHeights(t, "m=3")
+	checkHeight(t, heights, "", 0, false)
+	checkHeight(t, heights, "m", 3, true)
+}
+
+func Test_ParseHeights_04(t *testing.T) {
+	// Unlike spillage, heights cannot be inferred
+	for _, spec := range []string{"", "x", "-1", "m=", "m=x", "m=-1", "m=1=2", "1.5"} {
+		if _, err := parseHeights([]string{spec}); err == nil {
+			t.Errorf("expected error parsing height \"%s\"", spec)
+		} else if msg := "malformed height \"" + spec + "\""; err.Error() != msg {
+			t.Errorf("expected error \"%s\" parsing height \"%s\", got \"%s\"", msg, spec, err)
+		}
+	}
+}
+
+func checkParseHeights(t *testing.T, specs ...string) sc.ModuleHeights {
+	heights, err := parseHeights(specs)
+	//
+	if err != nil {
+		t.Fatalf("unexpected error parsing heights %v (%s)", specs, err)
+	} else if heights == nil {
+		t.Fatalf("expected fixed heights for %v", specs)
+	}
+	//
+	return heights
+}
+
+func checkHeight(t *testing.T, heights sc.ModuleHeights, module string, expected uint, fixed bool) {
+	if n, ok := heights(module); ok != fixed {
+		t.Errorf("expected module '%s' fixed to be %t, got %t", module, fixed, ok)
+	} else if ok && n != expected {
+		t.Errorf("expected height %d for module '%s', got %d", expected, module, n)
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var verifyPaddingCmd = &cobra.Command{
+	Use:   "verify-padding [flags] constraint_file(s)",
+	Short: "check a given set of constraints is sound with respect to padding.",
+	Long: `Check a given set of constraints accepts traces consisting entirely
+	of padding, across a range of padding sizes.  This also checks the spillage
+	inferred for each module is sufficient for such traces.  No trace is
+	required, hence this is suitable for running as part of CI.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			fmt.Println(cmd.UsageString())
+			os.Exit(1)
+		}
+		// Configure log level
+		if GetFlag(cmd, "verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
+		//
+		cfg := checkConfig{hir: GetFlag(cmd, "hir"), mir: GetFlag(cmd, "mir"), air: GetFlag(cmd, "air")}
+		stdlib := !GetFlag(cmd, "no-stdlib")
+		debug := GetFlag(cmd, "debug")
+		legacy := GetFlag(cmd, "legacy")
+		padding := GetRange(cmd, "padding")
+		//
+		if !cfg.hir && !cfg.mir && !cfg.air {
+			// If IR not specified default to running all.
+			cfg.hir, cfg.mir, cfg.air = true, true, true
+		}
+		// Parse constraints
+		hirSchema := readSchema(stdlib, debug, legacy, args)
+		// Go!
+		if !verifyPadding(lowerSchemas(hirSchema, cfg), padding) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyPaddingCmd)
+	verifyPaddingCmd.Flags().Bool("hir", false, "check at HIR level")
+	verifyPaddingCmd.Flags().Bool("mir", false, "check at MIR level")
+	verifyPaddingCmd.Flags().Bool("air", false, "check at AIR level")
+	verifyPaddingCmd.Flags().Bool("debug", false, "enable debugging constraints")
+	verifyPaddingCmd.Flags().String("padding", "0..4", "range of padding sizes to check (e.g. 2 or 0..4)")
+}
+
+// Check padding-only traces are accepted at each of the given IRs, and that
+// the inferred spillage is sufficient for them.  This returns false if any
+// problems are found.
+func verifyPadding(schemas []loweredSchema, padding util.Pair[uint, uint]) bool {
+	var (
+		irs    []string
+		checks [][]sc.SpillageCheck
+	)
+	//
+	ok := true
+	//
+	for _, s := range schemas {
+		builder := sc.NewTraceBuilder(s.schema)
+		// Check constraints
+		failures, errs := sc.CheckPadding(builder, padding)
+		//
+		printPaddingFailures(s.ir, s.schema, failures)
+		// Check spillage
+		spillage, spillage_errs := sc.CheckSpillage(builder, padding)
+		irs, checks = append(irs, s.ir), append(checks, spillage)
+		// Errors arising from both checks are reported once
+		errs = sc.AppendUniqueErrors(errs, spillage_errs...)
+		//
+		for _, err := range errs {
+			fmt.Printf("[%s] %s\n", s.ir, err)
+		}
+		//
+		ok = ok && len(failures) == 0 && len(errs) == 0
+	}
+	//
+	return printSpillageChecks(irs, checks) && ok
+}
+
+// Print the constraints which reject padding-only traces, along with the amounts
+// of padding for which they do so.
+func printPaddingFailures(ir string, schema sc.Schema, failures []sc.PaddingFailure) {
+	var (
+		order    []sc.PaddingFailure
+		paddings = make(map[util.Pair[uint, bool]][]string)
+	)
+	// Group failures by constraint (or assertion)
+	for _, f := range failures {
+		key := util.NewPair(f.Constraint, f.Assertion)
+		//
+		if _, ok := paddings[key]; !ok {
+			order = append(order, f)
+		}
+		//
+		paddings[key] = append(paddings[key], fmt.Sprintf("%d", f.Padding))
+	}
+	//
+	for _, f := range order {
+		constraints := schema.Constraints()
+		//
+		if f.Assertion {
+			constraints = schema.Assertions()
+		}
+		//
+		fmt.Printf("[%s] %s rejects padding-only trace (padding %s)\n", ir,
+			sc.OriginOf(constraints.Nth(f.Constraint), schema),
+			strings.Join(paddings[util.NewPair(f.Constraint, f.Assertion)], ", "))
+	}
+}
+
+// Print a table summarising the outcome of spillage checks at each IR, returning
+// false if the inferred spillage was insufficient for any module.
+func printSpillageChecks(irs []string, checks [][]sc.SpillageCheck) bool {
+	ok := true
+	nrows := uint(1)
+	//
+	for _, c := range checks {
+		nrows += uint(len(c))
+	}
+	//
+	tbl := util.NewTablePrinter(5, nrows)
+	tbl.SetRow(0, "IR", "Module", "Inferred", "Required", "Status")
+	//
+	for i, row := 0, uint(1); i < len(checks); i++ {
+		for _, check := range checks[i] {
+			name, status := check.Module, "ok"
+			//
+			if name == "" {
+				name = "(root)"
+			}
+			//
+			if !check.IsSufficient() {
+				ok, status = false, "insufficient"
+			}
+			//
+			tbl.SetRow(row, irs[i], name, fmt.Sprintf("%d", check.Inferred), fmt.Sprintf("%d", check.Required), status)
+			row++
+		}
+	}
+	//
+	tbl.Print()
+	//
+	return ok
+}
@@ -6,6 +6,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/schema"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	tr "github.com/consensys/go-corset/pkg/trace"
@@ -53,6 +54,46 @@ func (r *Register) IsInput() bool {
 	return !computed
 }
 
+// Display determines how values of this register should be shown to the user.
+// When the source-level columns allocated to this register disagree on their
+// display, the default display is used.
+func (r *Register) Display() sc.Display {
+	display := r.Sources[0].display
+	//
+	for _, ith := range r.Sources {
+		if ith.display != display {
+			return sc.DISPLAY_DEFAULT
+		}
+	}
+	//
+	return display
+}
+
+// Padding determines the value used for padding rows of this register.  When
+// the source-level columns allocated to this register disagree on their
+// padding value (which is reported as an error), zero is used.
+func (r *Register) Padding() fr.Element {
+	if !r.HasUniquePadding() {
+		return fr.NewElement(0)
+	}
+	//
+	return r.Sources[0].padding
+}
+
+// HasUniquePadding checks whether all source-level columns allocated to this
+// register agree on their padding value.
+func (r *Register) HasUniquePadding() bool {
+	padding := r.Sources[0].padding
+	//
+	for _, ith := range r.Sources {
+		if !ith.padding.Equal(&padding) {
+			return false
+		}
+	}
+	//
+	return true
+}
+
 // Merge two registers together.  This means the source-level columns will be
 // allocated to the same underlying HIR column (i.e. register).
 func (r *Register) Merge(other *Register) {
@@ -129,6 +170,10 @@ type RegisterSource struct {
 	mustProve bool
 	// Determines whether this is a computed column.
 	computed bool
+	// Display of the source-level column.
+	display sc.Display
+	// Padding value of the source-level column.
+	padding fr.Element
 }
 
 // IsVirtual indicates whether or not this is a "virtual" column.  That is,
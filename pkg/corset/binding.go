@@ -4,6 +4,8 @@ import (
 	"math"
 	"reflect"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	tr "github.com/consensys/go-corset/pkg/trace"
 	"github.com/consensys/go-corset/pkg/util"
@@ -151,6 +153,10 @@ type ColumnBinding struct {
 	multiplier uint
 	// Column's datatype
 	dataType Type
+	// Column's display (e.g. hex, dec, etc)
+	display sc.Display
+	// Column's padding value
+	padding fr.Element
 }
 
 // NewComputedColumnBinding constructs a new column binding in a given
@@ -160,7 +166,7 @@ type ColumnBinding struct {
 // not immediately available and must be determined from those columns from
 // which it is constructed.
 func NewComputedColumnBinding(context util.Path, path util.Path) *ColumnBinding {
-	return &ColumnBinding{context, path, true, false, 0, nil}
+	return &ColumnBinding{context, path, true, false, 0, nil, sc.DISPLAY_DEFAULT, fr.NewElement(0)}
 }
 
 // AbsolutePath returns the fully resolved (absolute) path of the column in question.
@@ -2,6 +2,7 @@ package corset
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/sexp"
@@ -905,6 +906,83 @@ func (p *DefProperty) Lisp() sexp.SExp {
 		p.Assertion.Lisp()})
 }
 
+// ============================================================================
+// deftest
+// ============================================================================
+
+// DefTest represents a small trace embedded within the constraints, along with
+// whether or not it is expected to be accepted.  Such tests are not
+// constraints, and are never enforced by the prover.  Rather, they allow unit
+// tests to be kept alongside the constraints which they test.  Only input
+// columns can be given, since computed columns are always filled in when the
+// trace is expanded.
+type DefTest struct {
+	// Unique handle given to this test.  This is primarily useful for
+	// reporting (i.e. so we know which test failed, etc).
+	Handle string
+	// Indicates whether the trace should be accepted (true) or rejected
+	// (false).
+	Accepts bool
+	// Columns for which values are given.
+	Columns []Symbol
+	// Values given for each column.
+	Values [][]big.Int
+	// Indicates whether or not the columns have been resolved.
+	finalised bool
+}
+
+// Definitions returns the set of symbols defined by this declaration.  Observe that
+// these may not yet have been finalised.
+func (p *DefTest) Definitions() util.Iterator[SymbolDefinition] {
+	return util.NewArrayIterator[SymbolDefinition](nil)
+}
+
+// Dependencies needed to signal declaration.
+func (p *DefTest) Dependencies() util.Iterator[Symbol] {
+	return util.NewArrayIterator(p.Columns)
+}
+
+// Defines checks whether this declaration defines the given symbol.  The symbol
+// in question needs to have been resolved already for this to make sense.
+func (p *DefTest) Defines(symbol Symbol) bool {
+	return false
+}
+
+// IsFinalised checks whether this declaration has already been finalised.  If
+// so, then we don't need to finalise it again.
+func (p *DefTest) IsFinalised() bool {
+	return p.finalised
+}
+
+// Finalise this test, meaning that all columns have been resolved.
+func (p *DefTest) Finalise() {
+	p.finalised = true
+}
+
+// Lisp converts this node into its lisp representation.  This is primarily used
+// for debugging purposes.
+func (p *DefTest) Lisp() sexp.SExp {
+	kind := ":rejects"
+	//
+	if p.Accepts {
+		kind = ":accepts"
+	}
+	//
+	list := []sexp.SExp{sexp.NewSymbol("deftest"), sexp.NewSymbol(p.Handle), sexp.NewSymbol(kind)}
+	//
+	for i, col := range p.Columns {
+		column := []sexp.SExp{col.Lisp()}
+		//
+		for _, v := range p.Values[i] {
+			column = append(column, sexp.NewSymbol(v.String()))
+		}
+		//
+		list = append(list, sexp.NewList(column))
+	}
+	//
+	return sexp.NewList(list)
+}
+
 // ============================================================================
 // depurefun & defun
 // ============================================================================
@@ -236,7 +236,9 @@ func (p *GlobalEnvironment) allocateUnit(column *ColumnBinding, ctx util.Path, p
 		column.multiplier,
 		datatype,
 		column.mustProve,
-		column.computed}
+		column.computed,
+		column.display,
+		column.padding}
 	// Allocate register
 	p.registers = append(p.registers, Register{
 		tr.NewContext(moduleId, column.multiplier),
@@ -11,6 +11,7 @@ import (
 	"unicode"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	"github.com/consensys/go-corset/pkg/util"
 )
@@ -266,6 +267,8 @@ func (p *Parser) parseDeclaration(module util.Path, s *sexp.List) (Declaration,
 		decl, errors = p.parseDefPerspective(module, s.Elements)
 	} else if s.Len() == 3 && s.MatchSymbols(2, "defproperty") {
 		decl, errors = p.parseDefProperty(s.Elements)
+	} else if s.Len() >= 3 && s.MatchSymbols(3, "deftest") {
+		decl, errors = p.parseDefTest(s.Elements)
 	} else {
 		errors = p.translator.SyntaxErrors(s, "malformed declaration")
 	}
@@ -345,7 +348,7 @@ func (p *Parser) parseColumnDeclaration(context util.Path, path util.Path, compu
 	//
 	var (
 		error   *SyntaxError
-		binding ColumnBinding = ColumnBinding{context, path, computed, false, 0, nil}
+		binding ColumnBinding = ColumnBinding{context, path, computed, false, 0, nil, sc.DISPLAY_DEFAULT, fr.NewElement(0)}
 	)
 	// Set defaults for input columns
 	if !computed {
@@ -364,7 +367,7 @@ func (p *Parser) parseColumnDeclaration(context util.Path, path util.Path, compu
 		// Column name is always first
 		binding.path = *path.Extend(l.Elements[0].String(false))
 		//	Parse type (if applicable)
-		if binding.dataType, binding.mustProve, error = p.parseColumnDeclarationAttributes(l.Elements[1:]); error != nil {
+		if error = p.parseColumnDeclarationAttributes(&binding, l.Elements[1:]); error != nil {
 			return nil, error
 		}
 	} else {
@@ -378,10 +381,14 @@ func (p *Parser) parseColumnDeclaration(context util.Path, path util.Path, compu
 	return def, nil
 }
 
-func (p *Parser) parseColumnDeclarationAttributes(attrs []sexp.SExp) (Type, bool, *SyntaxError) {
+// Parse the attributes of a column declaration (e.g. its type, display,
+// padding, etc), updating the given binding accordingly.
+func (p *Parser) parseColumnDeclarationAttributes(binding *ColumnBinding, attrs []sexp.SExp) *SyntaxError {
 	var (
 		dataType  Type = NewFieldType()
 		mustProve bool = false
+		display   sc.Display
+		padding   fr.Element
 		array_min uint
 		array_max uint
 		err       *SyntaxError
@@ -392,44 +399,55 @@ func (p *Parser) parseColumnDeclarationAttributes(attrs []sexp.SExp) (Type, bool
 		symbol := ith.AsSymbol()
 		// Sanity check
 		if symbol == nil {
-			return nil, false, p.translator.SyntaxError(ith, "unknown column attribute")
+			return p.translator.SyntaxError(ith, "unknown column attribute")
 		}
 		//
 		switch symbol.Value {
 		case ":display":
-			// skip these for now, as they are only relevant to the inspector.
+			var ok bool
+			// Sanity check display definition
 			if i+1 == len(attrs) {
-				return nil, false, p.translator.SyntaxError(ith, "incomplete display definition")
+				return p.translator.SyntaxError(ith, "incomplete display definition")
 			} else if attrs[i+1].AsSymbol() == nil {
-				return nil, false, p.translator.SyntaxError(ith, "malformed display definition")
+				return p.translator.SyntaxError(ith, "malformed display definition")
 			}
 			// Check what display attribute we have
-			switch attrs[i+1].AsSymbol().String(false) {
-			case ":dec", ":hex", ":bytes", ":opcode":
-				// all good
-				i = i + 1
-			default:
-				// not good
-				return nil, false, p.translator.SyntaxError(ith, "unknown display definition")
+			if display, ok = sc.ParseDisplay(attrs[i+1].AsSymbol().String(false)); !ok {
+				return p.translator.SyntaxError(ith, "unknown display definition")
 			}
+			// skip display
+			i++
+		case ":padding":
+			// Sanity check padding definition
+			if i+1 == len(attrs) {
+				return p.translator.SyntaxError(ith, "incomplete padding definition")
+			} else if attrs[i+1].AsSymbol() == nil {
+				return p.translator.SyntaxError(ith, "malformed padding definition")
+			} else if _, err := padding.SetString(attrs[i+1].AsSymbol().Value); err != nil {
+				return p.translator.SyntaxError(attrs[i+1], "invalid padding value")
+			}
+			// skip padding value
+			i++
 		case ":array":
 			if array_min, array_max, err = p.parseArrayDimension(attrs[i+1]); err != nil {
-				return nil, false, err
+				return err
 			}
 			// skip dimension
 			i++
 		default:
 			if dataType, mustProve, err = p.parseType(ith); err != nil {
-				return nil, false, err
+				return err
 			}
 		}
 	}
 	// Done
 	if array_max != 0 {
-		return NewArrayType(dataType, array_min, array_max), mustProve, nil
+		dataType = NewArrayType(dataType, array_min, array_max)
 	}
 	//
-	return dataType, mustProve, nil
+	binding.dataType, binding.mustProve, binding.display, binding.padding = dataType, mustProve, display, padding
+	//
+	return nil
 }
 
 func (p *Parser) parseArrayDimension(s sexp.SExp) (uint, uint, *SyntaxError) {
@@ -780,6 +798,100 @@ func (p *Parser) parseDefProperty(elements []sexp.SExp) (Declaration, []SyntaxEr
 	return &DefProperty{handle.Value, expr, false}, nil
 }
 
+// Parse an inline test declaration
+func (p *Parser) parseDefTest(elements []sexp.SExp) (Declaration, []SyntaxError) {
+	var (
+		errors  []SyntaxError
+		accepts bool
+		columns []Symbol
+		values  [][]big.Int
+	)
+	// Initial sanity checks
+	if !isIdentifier(elements[1]) {
+		errors = p.translator.SyntaxErrors(elements[1], "expected test handle")
+	}
+	// Parse expected outcome
+	if kind := elements[2].AsSymbol(); kind != nil && kind.Value == ":accepts" {
+		accepts = true
+	} else if kind == nil || kind.Value != ":rejects" {
+		errors = append(errors, *p.translator.SyntaxError(elements[2], "expected :accepts or :rejects"))
+	}
+	// Parse columns
+	for _, e := range elements[3:] {
+		name, vals, errs := p.parseDefTestColumn(e)
+		//
+		if len(errs) != 0 {
+			errors = append(errors, errs...)
+		} else if len(values) > 0 && len(vals) != len(values[0]) {
+			// All columns of a test must have the same height
+			msg := fmt.Sprintf("inconsistent test column height (expected %d rows)", len(values[0]))
+			errors = append(errors, *p.translator.SyntaxError(e, msg))
+		} else {
+			columns = append(columns, name)
+			values = append(values, vals)
+		}
+	}
+	// Error Check
+	if len(errors) != 0 {
+		return nil, errors
+	}
+	// Done
+	return &DefTest{elements[1].AsSymbol().Value, accepts, columns, values, false}, nil
+}
+
+// Parse the values given for a single column of an inline test, such as "(X 1 2
+// 3)".
+func (p *Parser) parseDefTestColumn(e sexp.SExp) (*ColumnName, []big.Int, []SyntaxError) {
+	var (
+		errors []SyntaxError
+		values []big.Int
+		l      = e.AsList()
+	)
+	// Sanity check column
+	if l == nil || l.Len() == 0 || l.Get(0).AsSymbol() == nil {
+		return nil, nil, p.translator.SyntaxErrors(e, "malformed test column")
+	}
+	// Parse column name
+	path, err := parseQualifiableName(l.Get(0).AsSymbol().Value)
+	if err != nil {
+		return nil, nil, p.translator.SyntaxErrors(l.Get(0), err.Error())
+	}
+	// Parse column values
+	for _, v := range l.Elements[1:] {
+		if val, ok := parseTestValue(v); !ok {
+			errors = append(errors, *p.translator.SyntaxError(v, "invalid test value"))
+		} else {
+			values = append(values, val)
+		}
+	}
+	// Error Check
+	if len(errors) != 0 {
+		return nil, nil, errors
+	}
+	//
+	name := NewColumnName(path)
+	// Update source mapping
+	p.mapSourceNode(l.Get(0), name)
+	//
+	return name, values, nil
+}
+
+// Parse a value given in an inline test, which is either a (possibly negative)
+// decimal or a hexadecimal constant.
+func parseTestValue(e sexp.SExp) (big.Int, bool) {
+	var num big.Int
+	//
+	if e.AsSymbol() == nil {
+		return num, false
+	} else if symbol := e.AsSymbol().Value; strings.HasPrefix(symbol, "0x") {
+		_, ok := num.SetString(symbol[2:], 16)
+		return num, ok
+	} else {
+		_, ok := num.SetString(symbol, 10)
+		return num, ok
+	}
+}
+
 // Parse a permutation declaration
 func (p *Parser) parseDefFun(module util.Path, pure bool, elements []sexp.SExp) (Declaration, []SyntaxError) {
 	var (
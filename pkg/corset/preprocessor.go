@@ -82,6 +82,8 @@ func (p *preprocessor) preprocessDeclaration(decl Declaration) []SyntaxError {
 		errors = p.preprocessDefPerspective(d)
 	case *DefProperty:
 		errors = p.preprocessDefProperty(d)
+	case *DefTest:
+		// ignore
 	default:
 		// Error handling
 		panic("unknown declaration")
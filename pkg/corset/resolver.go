@@ -27,6 +27,8 @@ func ResolveCircuit(srcmap *sexp.SourceMaps[Node], circuit *Circuit) (*ModuleSco
 	r := resolver{srcmap}
 	// Initialise all columns
 	errs1 := r.initialiseDeclarations(scope, circuit)
+	// Check inline tests are uniquely named
+	errs1 = append(errs1, r.checkDefTestHandles(circuit)...)
 	// Finalise all columns / declarations
 	errs2 := r.resolveDeclarations(scope, circuit)
 	//
@@ -62,6 +64,35 @@ func (r *resolver) initialiseDeclarations(scope *ModuleScope, circuit *Circuit)
 	return errs
 }
 
+// Check that no two inline tests (i.e. deftest declarations) have the same
+// handle, since tests are identified by their handles when reported.
+func (r *resolver) checkDefTestHandles(circuit *Circuit) []SyntaxError {
+	var (
+		errors  []SyntaxError
+		handles = make(map[string]bool)
+		decls   = [][]Declaration{circuit.Declarations}
+	)
+	//
+	for _, m := range circuit.Modules {
+		decls = append(decls, m.Declarations)
+	}
+	//
+	for _, ds := range decls {
+		for _, d := range ds {
+			if test, ok := d.(*DefTest); !ok {
+				continue
+			} else if handles[test.Handle] {
+				msg := fmt.Sprintf("test %s already declared", test.Handle)
+				errors = append(errors, *r.srcmap.SyntaxError(test, msg))
+			} else {
+				handles[test.Handle] = true
+			}
+		}
+	}
+	//
+	return errors
+}
+
 // Initialise all declarations in the given module scope.  That means allocating
 // all bindings into the scope, whilst also ensuring that we never have two
 // bindings for the same symbol, etc.  The key is that, at this stage, all
@@ -307,6 +338,8 @@ func (r *resolver) finaliseDeclaration(scope *ModuleScope, decl Declaration) []S
 		return r.finaliseDefPerspectiveInModule(scope, d)
 	case *DefProperty:
 		return r.finaliseDefPropertyInModule(scope, d)
+	case *DefTest:
+		return r.finaliseDefTestInModule(d)
 	}
 	//
 	return nil
@@ -510,6 +543,29 @@ func (r *resolver) finaliseDefPropertyInModule(enclosing Scope, decl *DefPropert
 	return r.finaliseExpressionInModule(scope, decl.Assertion)
 }
 
+// Finalise an inline test after all columns have been resolved.  This requires
+// checking that values are only given for input columns, since computed columns
+// are always filled in when the trace is expanded.
+func (r *resolver) finaliseDefTestInModule(decl *DefTest) []SyntaxError {
+	var errors []SyntaxError
+	//
+	for _, col := range decl.Columns {
+		binding := col.Binding().(*ColumnBinding)
+		//
+		if binding.computed {
+			errors = append(errors, *r.srcmap.SyntaxError(col, "not an input column"))
+		} else if _, ok := binding.dataType.(*ArrayType); ok {
+			errors = append(errors, *r.srcmap.SyntaxError(col, "array columns not supported"))
+		}
+	}
+	// Error check
+	if len(errors) == 0 {
+		decl.Finalise()
+	}
+	// Done
+	return errors
+}
+
 // Resolve a sequence of zero or more expressions within a given module.  This
 // simply resolves each of the arguments in turn, collecting any errors arising.
 func (r *resolver) finaliseExpressionsInModule(scope LocalScope, args []Expr) []SyntaxError {
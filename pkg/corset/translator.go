@@ -31,6 +31,10 @@ func TranslateCircuit(env Environment, srcmap *sexp.SourceMaps[Node],
 	if errs := t.translateOtherDeclarations(circuit); len(errs) > 0 {
 		return nil, errs
 	}
+	// Record source-level columns of each register
+	if errs := t.translateRegisterSources(circuit); len(errs) > 0 {
+		return nil, errs
+	}
 	// Done
 	return t.schema, nil
 }
@@ -94,7 +98,8 @@ func (t *translator) translateInputColumnsInModule(module string) {
 			panic("inactive register encountered")
 		} else if regInfo.IsInput() {
 			// Declare column at HIR level.
-			cid := t.schema.AddDataColumn(regInfo.Context, regInfo.Name(), regInfo.DataType)
+			cid := t.schema.AddDataColumn(regInfo.Context, regInfo.Name(), regInfo.DataType, regInfo.Display(),
+				regInfo.Padding())
 			// Prove underlying types (as necessary)
 			t.translateTypeConstraints(regIndex)
 			// Sanity check
@@ -162,6 +167,60 @@ func (t *translator) translateTypeConstraints(regIndex uint) {
 	}
 }
 
+// Translate the source-level columns allocated to each register, such that
+// registers can subsequently be mapped back to the columns (and perspectives)
+// from which they were allocated.  For columns declared in a perspective, this
+// includes the perspective's selector so that tooling can determine on which
+// rows a given column is active.
+func (t *translator) translateRegisterSources(circuit *Circuit) []SyntaxError {
+	var errors []SyntaxError
+	// Identify all perspectives in the circuit
+	perspectives := make(map[string]*DefPerspective)
+	modules := []string{""}
+	//
+	collectPerspectives(circuit.Declarations, perspectives)
+	//
+	for _, m := range circuit.Modules {
+		collectPerspectives(m.Declarations, perspectives)
+		modules = append(modules, m.Name)
+	}
+	// Process registers in each module
+	for _, module := range modules {
+		for _, regIndex := range t.env.RegistersOf(module) {
+			for _, source := range t.env.Register(regIndex).Sources {
+				var (
+					selector    hir.Expr
+					perspective string
+				)
+				// Translate selector (if applicable)
+				if persp, ok := perspectives[source.name.Parent().String()]; ok && source.IsVirtual() {
+					var errs []SyntaxError
+					//
+					perspective = persp.Name()
+					selector, errs = t.translateExpressionInModule(persp.Selector, source.context, 0)
+					errors = append(errors, errs...)
+				}
+				//
+				name := source.name.Tail()
+				t.schema.AddRegisterSource(regIndex,
+					hir.NewRegisterSource(source.context.String(), perspective, name, selector))
+			}
+		}
+	}
+	//
+	return errors
+}
+
+// Collect all perspectives declared in a given set of declarations, indexed by
+// their qualified name.
+func collectPerspectives(decls []Declaration, perspectives map[string]*DefPerspective) {
+	for _, d := range decls {
+		if p, ok := d.(*DefPerspective); ok {
+			perspectives[p.Path().String()] = p
+		}
+	}
+}
+
 // Translate all assignment or constraint declarations in the circuit.
 func (t *translator) translateOtherDeclarations(circuit *Circuit) []SyntaxError {
 	rootPath := util.NewAbsolutePath()
@@ -193,6 +252,9 @@ func (t *translator) translateOtherDeclarationsInModule(module util.Path, decls
 // given module.
 func (t *translator) translateDeclaration(decl Declaration, module util.Path) []SyntaxError {
 	var errors []SyntaxError
+	// Record number of constraints (and assertions) prior to translation
+	nconstraints := t.schema.Constraints().Count()
+	nassertions := t.schema.Assertions().Count()
 	//
 	switch d := decl.(type) {
 	case *DefAliases:
@@ -216,16 +278,72 @@ func (t *translator) translateDeclaration(decl Declaration, module util.Path) []
 		errors = t.translateDefPermutation(d, module)
 	case *DefPerspective:
 		// As for defcolumns, nothing generated here.
+		errors = t.checkDefPerspective(d)
 	case *DefProperty:
 		errors = t.translateDefProperty(d, module)
+	case *DefTest:
+		t.translateDefTest(d)
 	default:
 		// Error handling
 		panic("unknown declaration")
 	}
+	// Record source location of any constraints (or assertions) arising
+	t.locateConstraints(decl, nconstraints, nassertions)
 	//
 	return errors
 }
 
+// Check the columns of a "defperspective" declaration can share registers with
+// the columns of other perspectives to which they are allocated.
+// Specifically, columns sharing a register must agree on their padding value.
+func (t *translator) checkDefPerspective(decl *DefPerspective) []SyntaxError {
+	var errors []SyntaxError
+	//
+	for _, col := range decl.Columns {
+		for _, path := range registerPaths(*col.Path(), col.DataType()) {
+			register := t.env.Register(t.env.RegisterOf(&path))
+			//
+			if !register.HasUniquePadding() {
+				msg := fmt.Sprintf("conflicting padding for register %s", register.Name())
+				errors = append(errors, *t.srcmap.SyntaxError(col, msg))
+				// Report each column at most once
+				break
+			}
+		}
+	}
+	//
+	return errors
+}
+
+// Determine the paths of all registers allocated for a column with a given
+// path and type.  For example, an array column X of length 2 is allocated
+// registers X_1 and X_2.
+func registerPaths(path util.Path, datatype Type) []util.Path {
+	if arraytype, ok := datatype.(*ArrayType); ok {
+		var paths []util.Path
+		//
+		for i := arraytype.min; i <= arraytype.max; i++ {
+			ith_path := path.Parent().Extend(fmt.Sprintf("%s_%d", path.Tail(), i))
+			paths = append(paths, registerPaths(*ith_path, arraytype.element)...)
+		}
+		//
+		return paths
+	}
+	//
+	return []util.Path{path}
+}
+
+// Record the source location of a given declaration against all constraints
+// (and assertions) added to the schema since the given number of constraints
+// (and assertions) had been added.  This allows failing constraints to be
+// traced back to the declaration from which they were translated.
+func (t *translator) locateConstraints(decl Declaration, nconstraints uint, nassertions uint) {
+	if loc, ok := t.srcmap.Location(decl); ok {
+		sc.LocateConstraints(t.schema.Constraints(), nconstraints, &loc)
+		sc.LocateConstraints(t.schema.Assertions(), nassertions, &loc)
+	}
+}
+
 // Translate a "defconstraint" declaration.
 func (t *translator) translateDefConstraint(decl *DefConstraint, module util.Path) []SyntaxError {
 	// Translate constraint body
@@ -393,6 +511,27 @@ func (t *translator) translateDefProperty(decl *DefProperty, module util.Path) [
 	return errors
 }
 
+// Translate a "deftest" declaration.  This is not a constraint, but is instead
+// recorded in the schema so that it can be checked later.
+func (t *translator) translateDefTest(decl *DefTest) {
+	columns := make([]hir.InlineColumn, len(decl.Columns))
+	//
+	for i, col := range decl.Columns {
+		binding := col.Binding().(*ColumnBinding)
+		register := t.env.Register(t.env.RegisterOf(binding.AbsolutePath()))
+		module := t.schema.Modules().Nth(register.Context.Module()).Name
+		values := make([]fr.Element, len(decl.Values[i]))
+		//
+		for j := range decl.Values[i] {
+			values[j].SetBigInt(&decl.Values[i][j])
+		}
+		//
+		columns[i] = hir.InlineColumn{Module: module, Name: register.Name(), Values: values}
+	}
+	//
+	t.schema.AddInlineTest(hir.InlineTest{Name: decl.Handle, Accepts: decl.Accepts, Columns: columns})
+}
+
 // Translate an optional expression in a given context.  That is an expression
 // which maybe nil (i.e. doesn't exist).  In such case, nil is returned (i.e.
 // without any errors).
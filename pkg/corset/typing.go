@@ -80,6 +80,8 @@ func (p *typeChecker) typeCheckDeclaration(decl Declaration) []SyntaxError {
 		errors = p.typeCheckDefPerspective(d)
 	case *DefProperty:
 		errors = p.typeCheckDefProperty(d)
+	case *DefTest:
+		// ignore
 	default:
 		// Error handling
 		panic("unknown declaration")
@@ -0,0 +1,182 @@
+package hir
+
+import (
+	tr "github.com/consensys/go-corset/pkg/trace"
+)
+
+// Coverage records how thoroughly a vanishing constraint has been exercised by
+// one or more traces.  Specifically, a row is "live" if the constraint yields
+// at least one value which must vanish on that row, and "vacuous" otherwise
+// (i.e. when every enclosing conditional selected a missing branch).
+// Furthermore, the number of rows on which each branch of each conditional
+// (i.e. IfZero) within the constraint was taken is recorded.
+type Coverage struct {
+	// Conditionals within the constraint, in pre-order.
+	Conditionals []*IfZero
+	// Number of rows on which each conditional took its true branch (i.e.
+	// because the condition was zero).
+	TrueBranch []uint
+	// Number of rows on which each conditional took its false branch (i.e.
+	// because the condition was non-zero).
+	FalseBranch []uint
+	// Number of rows on which the constraint was live.
+	Live uint
+	// Number of rows on which the constraint was vacuously satisfied.
+	Vacuous uint
+	// Maps each conditional to its index.
+	index map[*IfZero]int
+}
+
+// NewCoverage constructs an empty coverage record for a given vanishing
+// constraint.
+func NewCoverage(c VanishingConstraint) *Coverage {
+	conditionals := conditionalsOf(c.Constraint.Expr, nil)
+	index := make(map[*IfZero]int)
+	//
+	for i, e := range conditionals {
+		index[e] = i
+	}
+	//
+	n := len(conditionals)
+	//
+	return &Coverage{conditionals, make([]uint, n), make([]uint, n), 0, 0, index}
+}
+
+// Record the coverage of a given vanishing constraint on a given trace.  This
+// considers every row on which the constraint is checked, except those within
+// the given number of rows at the front of the enclosing module which were not
+// given by the user (i.e. those added as spillage or padding).  Rows of local
+// constraints are always considered, since they are checked on a fixed row.
+func (p *Coverage) Record(c VanishingConstraint, trace tr.Trace, padding uint) {
+	height := trace.Height(c.Context)
+	bounds := c.Constraint.Bounds()
+	// Account for length multiplier
+	start := max(padding*c.Context.LengthMultiplier(), bounds.Start)
+	//
+	if c.Domain.HasValue() {
+		// Local constraint
+		row := c.Domain.Unwrap()
+		// Negative rows calculated from end of trace.
+		if row < 0 {
+			row += int(height)
+		}
+		//
+		p.RecordAt(c, row, trace)
+	} else if bounds.End < height {
+		// Global constraint
+		for k := start; k < height-bounds.End; k++ {
+			p.RecordAt(c, int(k), trace)
+		}
+	}
+}
+
+// RecordAt records the coverage of a given vanishing constraint on a given row
+// of a given trace.
+func (p *Coverage) RecordAt(c VanishingConstraint, row int, trace tr.Trace) {
+	taken := make([]bool, 2*len(p.Conditionals))
+	// Determine branches taken
+	p.recordAt(c.Constraint.Expr, row, trace, taken)
+	//
+	for i := range p.Conditionals {
+		if taken[2*i] {
+			p.TrueBranch[i]++
+		}
+		//
+		if taken[2*i+1] {
+			p.FalseBranch[i]++
+		}
+	}
+	// Determine whether row is live
+	if len(c.Constraint.Expr.EvalAllAt(row, trace)) > 0 {
+		p.Live++
+	} else {
+		p.Vacuous++
+	}
+}
+
+// Record the branches taken when evaluating a given expression on a given row.
+// Branches of conditionals are only considered when the conditional itself is
+// evaluated (e.g. a conditional nested within a branch which was not taken is
+// not considered).
+func (p *Coverage) recordAt(e Expr, row int, trace tr.Trace, taken []bool) {
+	switch e := e.(type) {
+	case *Add:
+		p.recordAllAt(e.Args, row, trace, taken)
+	case *Sub:
+		p.recordAllAt(e.Args, row, trace, taken)
+	case *Mul:
+		p.recordAllAt(e.Args, row, trace, taken)
+	case *List:
+		p.recordAllAt(e.Args, row, trace, taken)
+	case *Exp:
+		p.recordAt(e.Arg, row, trace, taken)
+	case *Normalise:
+		p.recordAt(e.Arg, row, trace, taken)
+	case *IfZero:
+		var trueBranch, falseBranch bool
+		//
+		p.recordAt(e.Condition, row, trace, taken)
+		// Evaluate condition
+		for _, cond := range e.Condition.EvalAllAt(row, trace) {
+			trueBranch = trueBranch || cond.IsZero()
+			falseBranch = falseBranch || !cond.IsZero()
+		}
+		//
+		index := p.index[e]
+		// Record true branch (if taken)
+		if trueBranch && e.TrueBranch != nil {
+			taken[2*index] = true
+			p.recordAt(e.TrueBranch, row, trace, taken)
+		}
+		// Record false branch (if taken)
+		if falseBranch && e.FalseBranch != nil {
+			taken[2*index+1] = true
+			p.recordAt(e.FalseBranch, row, trace, taken)
+		}
+	}
+}
+
+func (p *Coverage) recordAllAt(exprs []Expr, row int, trace tr.Trace, taken []bool) {
+	for _, e := range exprs {
+		p.recordAt(e, row, trace, taken)
+	}
+}
+
+// Determine the conditionals within a given expression, in pre-order.
+func conditionalsOf(e Expr, conditionals []*IfZero) []*IfZero {
+	switch e := e.(type) {
+	case *Add:
+		return conditionalsOfAll(e.Args, conditionals)
+	case *Sub:
+		return conditionalsOfAll(e.Args, conditionals)
+	case *Mul:
+		return conditionalsOfAll(e.Args, conditionals)
+	case *List:
+		return conditionalsOfAll(e.Args, conditionals)
+	case *Exp:
+		return conditionalsOf(e.Arg, conditionals)
+	case *Normalise:
+		return conditionalsOf(e.Arg, conditionals)
+	case *IfZero:
+		conditionals = append(conditionals, e)
+		conditionals = conditionalsOf(e.Condition, conditionals)
+		//
+		if e.TrueBranch != nil {
+			conditionals = conditionalsOf(e.TrueBranch, conditionals)
+		}
+		//
+		if e.FalseBranch != nil {
+			conditionals = conditionalsOf(e.FalseBranch, conditionals)
+		}
+	}
+	//
+	return conditionals
+}
+
+func conditionalsOfAll(exprs []Expr, conditionals []*IfZero) []*IfZero {
+	for _, e := range exprs {
+		conditionals = conditionalsOf(e, conditionals)
+	}
+	//
+	return conditionals
+}
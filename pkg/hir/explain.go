@@ -0,0 +1,107 @@
+package hir
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// ExplainAt explains the evaluation of a column access at a given row in a
+// trace.
+func (e *ColumnAccess) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), "")
+}
+
+// ExplainAt explains the evaluation of a constant at a given row in a trace.
+func (e *Constant) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), "")
+}
+
+// ExplainAt explains the evaluation of a sum at a given row in a trace by
+// explaining each of its arguments.
+func (e *Add) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), "", sc.ExplainAll(k, tr, schema, e.Args)...)
+}
+
+// ExplainAt explains the evaluation of a product at a given row in a trace by
+// explaining each of its arguments.
+func (e *Mul) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), "", sc.ExplainAll(k, tr, schema, e.Args)...)
+}
+
+// ExplainAt explains the evaluation of an exponent at a given row in a trace by
+// explaining its argument.
+func (e *Exp) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), "", e.Arg.ExplainAt(k, tr, schema))
+}
+
+// ExplainAt explains the evaluation of a conditional at a given row in a trace.
+// Specifically, the condition is explained along with whichever branch(es) were
+// taken.  Observe that, since a condition can evaluate to multiple values, both
+// branches may be taken.
+func (e *IfZero) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	var trueBranch, falseBranch bool
+	// Evaluate condition
+	for _, cond := range e.Condition.EvalAllAt(k, tr) {
+		trueBranch = trueBranch || cond.IsZero()
+		falseBranch = falseBranch || !cond.IsZero()
+	}
+	// Explain condition
+	children := []sc.Explanation{e.Condition.ExplainAt(k, tr, schema)}
+	note := "no branch taken"
+	// Explain true branch (if taken)
+	if trueBranch && e.TrueBranch != nil {
+		children = append(children, e.TrueBranch.ExplainAt(k, tr, schema))
+	}
+	// Explain false branch (if taken)
+	if falseBranch && e.FalseBranch != nil {
+		children = append(children, e.FalseBranch.ExplainAt(k, tr, schema))
+	}
+	// Determine note
+	if trueBranch && falseBranch {
+		note = "both branches taken"
+	} else if trueBranch {
+		note = "true branch taken"
+	} else if falseBranch {
+		note = "false branch taken"
+	}
+	//
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), note, children...)
+}
+
+// ExplainAt explains the evaluation of a list at a given row in a trace by
+// explaining each of its arguments.
+func (e *List) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), "", sc.ExplainAll(k, tr, schema, e.Args)...)
+}
+
+// ExplainAt explains the evaluation of a normalisation at a given row in a
+// trace by explaining its argument.
+func (e *Normalise) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), "", e.Arg.ExplainAt(k, tr, schema))
+}
+
+// ExplainAt explains the evaluation of a subtraction at a given row in a trace
+// by explaining each of its arguments.
+func (e *Sub) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), e.EvalAllAt(k, tr), "", sc.ExplainAll(k, tr, schema, e.Args)...)
+}
+
+// ExplainAt explains the evaluation of a zero test at a given row in a trace.
+func (p ZeroArrayTest) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return p.Expr.ExplainAt(k, tr, schema)
+}
+
+// ExplainAt explains the evaluation of a unit expression at a given row in a
+// trace.
+func (e UnitExpr) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return e.Expr.ExplainAt(k, tr, schema)
+}
+
+// ExplainAt explains the evaluation of a max expression at a given row in a
+// trace.
+func (e MaxExpr) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	arg := e.Expr.ExplainAt(k, tr, schema)
+	//
+	return sc.NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(k, tr)}, "max", arg)
+}
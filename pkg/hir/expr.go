@@ -37,6 +37,10 @@ type Expr interface {
 	// Multiplicity returns the number of underlyg expressions that this
 	// expression will expand to.
 	Multiplicity() uint
+
+	// ExplainAt explains the evaluation of this expression at a given row of a
+	// given trace.
+	ExplainAt(int, trace.Trace, sc.Schema) sc.Explanation
 }
 
 // ============================================================================
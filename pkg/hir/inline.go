@@ -0,0 +1,51 @@
+package hir
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// InlineTest represents a small trace embedded in the source of a schema (i.e.
+// via deftest), along with whether or not that trace is expected to be
+// accepted.  Inline tests are not constraints, and are never enforced.
+// Rather, they allow unit tests to be kept alongside the constraints they
+// test.
+type InlineTest struct {
+	// Name of the test.
+	Name string
+	// Indicates whether the trace is expected to be accepted (true) or rejected
+	// (false).
+	Accepts bool
+	// Input columns given by the trace.
+	Columns []InlineColumn
+}
+
+// InlineColumn represents the values given for a single input column of an
+// inline test.
+type InlineColumn struct {
+	// Name of the enclosing module.
+	Module string
+	// Name of the column.
+	Name string
+	// Values of the column.
+	Values []fr.Element
+}
+
+// Trace returns the raw columns of the trace embedded in this test, such that
+// it can be built into a trace (e.g. using a TraceBuilder).
+func (p *InlineTest) Trace() []trace.RawColumn {
+	columns := make([]trace.RawColumn, len(p.Columns))
+	//
+	for i, col := range p.Columns {
+		data := util.NewFrArray(uint(len(col.Values)), 256)
+		//
+		for j, v := range col.Values {
+			data.Set(uint(j), v)
+		}
+		//
+		columns[i] = trace.RawColumn{Module: col.Module, Name: col.Name, Data: data}
+	}
+	//
+	return columns
+}
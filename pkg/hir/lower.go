@@ -2,6 +2,7 @@ package hir
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/mir"
@@ -20,23 +21,36 @@ func (p *Schema) LowerToMir() *mir.Schema {
 	// Lower columns
 	for _, input := range p.inputs {
 		col := input.(DataColumn)
-		mirSchema.AddDataColumn(col.Context(), col.Name(), col.Type())
+		mirSchema.AddDataColumn(col.Context(), col.Name(), col.Type(), col.Display(), col.Padding())
 	}
 	// Lower assignments (nothing to do here)
 	for _, a := range p.assignments {
+		mark := mirSchema.Mark()
 		mirSchema.AddAssignment(a)
+		// Record provenance
+		mirSchema.AttributeOrigin(mark, mirSchema.Mark(), "HIR", sc.OriginOf(a, p), math.MaxUint)
+		mirSchema.AttributeSource(mark, mirSchema.Mark(), sc.Provenance{}, sc.LocationOf(a))
 	}
 	// Lower constraints
-	for _, c := range p.constraints {
+	for i, c := range p.constraints {
+		mark := mirSchema.Mark()
 		lowerConstraintToMir(c, mirSchema)
+		// Propagate source location
+		sc.LocateConstraints(mirSchema.Constraints(), mark.Constraints, sc.LocationOf(c))
+		// Record provenance
+		mirSchema.AttributeOrigin(mark, mirSchema.Mark(), "HIR", sc.OriginOf(c, p), uint(i))
+		mirSchema.AttributeSource(mark, mirSchema.Mark(), sc.Provenance{}, sc.LocationOf(c))
 	}
 	// Copy property assertions.  Observe, these do not require lowering
 	// because they are already MIR-level expressions.
 	for _, c := range p.assertions {
+		n := mirSchema.Assertions().Count()
 		properties := c.Property.Expr.LowerTo(mirSchema)
 		for _, p := range properties {
 			mirSchema.AddPropertyAssertion(c.Handle, c.Context, p)
 		}
+		// Propagate source location
+		sc.LocateConstraints(mirSchema.Assertions(), n, c.Location)
 	}
 	//
 	return mirSchema
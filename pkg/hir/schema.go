@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"io"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/schema"
@@ -51,6 +52,12 @@ type Schema struct {
 	constraints []sc.Constraint
 	// The property assertions for this schema.
 	assertions []PropertyAssertion
+	// Source-level columns allocated to each column (i.e. register) of this
+	// schema, indexed by column.
+	sources [][]RegisterSource
+	// Tests embedded in the source of this schema.  These are not constraints,
+	// and are never enforced.
+	tests []InlineTest
 	// Cache list of columns declared in inputs and assignments.
 	column_cache []sc.Column
 }
@@ -64,6 +71,8 @@ func EmptySchema() *Schema {
 	p.assignments = make([]sc.Assignment, 0)
 	p.constraints = make([]sc.Constraint, 0)
 	p.assertions = make([]PropertyAssertion, 0)
+	p.sources = make([][]RegisterSource, 0)
+	p.tests = make([]InlineTest, 0)
 	p.column_cache = make([]sc.Column, 0)
 	// Done
 	return p
@@ -77,15 +86,17 @@ func (p *Schema) AddModule(name string) uint {
 	return mid
 }
 
-// AddDataColumn appends a new data column with a given type.  Furthermore, the
-// type is enforced by the system when checking is enabled.
-func (p *Schema) AddDataColumn(context trace.Context, name string, base sc.Type) uint {
+// AddDataColumn appends a new data column with a given type, display and
+// padding value.  Furthermore, the type is enforced by the system when checking
+// is enabled.
+func (p *Schema) AddDataColumn(context trace.Context, name string, base sc.Type, display sc.Display,
+	padding fr.Element) uint {
 	if context.Module() >= uint(len(p.modules)) {
 		panic(fmt.Sprintf("invalid module index (%d)", context.Module()))
 	}
 
 	cid := uint(len(p.inputs))
-	col := assignment.NewDataColumn(context, name, base)
+	col := assignment.NewDataColumn(context, name, base, display, padding)
 	p.inputs = append(p.inputs, col)
 	// Update column cache
 	for c := col.Columns(); c.HasNext(); {
@@ -145,6 +156,54 @@ func (p *Schema) AddPropertyAssertion(handle string, context trace.Context, prop
 	p.assertions = append(p.assertions, sc.NewPropertyAssertion[ZeroArrayTest](handle, context, ZeroArrayTest{property}))
 }
 
+// AddInlineTest appends a new test embedded in the source of this schema.
+func (p *Schema) AddInlineTest(test InlineTest) {
+	p.tests = append(p.tests, test)
+}
+
+// InlineTests returns the tests embedded in the source of this schema.
+func (p *Schema) InlineTests() []InlineTest {
+	return p.tests
+}
+
+// AddRegisterSource records that a given source-level column was allocated to a
+// given column (i.e. register) of this schema.
+func (p *Schema) AddRegisterSource(column uint, source RegisterSource) {
+	for uint(len(p.sources)) <= column {
+		p.sources = append(p.sources, nil)
+	}
+	//
+	p.sources[column] = append(p.sources[column], source)
+}
+
+// RegisterSources returns the source-level columns allocated to a given column
+// (i.e. register) of this schema.  This is empty for columns which did not
+// arise from register allocation (e.g. those introduced during lowering).
+// Since lowering preserves the indices of existing columns, this can also be
+// used for columns of the corresponding MIR and AIR schemas.
+func (p *Schema) RegisterSources(column uint) []RegisterSource {
+	if column < uint(len(p.sources)) {
+		return p.sources[column]
+	}
+	//
+	return nil
+}
+
+// ColumnDisplay returns how values of a given column of this schema should be
+// shown to the user.  Columns other than data columns (e.g. those introduced
+// during lowering) are shown using the default display.  Again, since lowering
+// preserves the indices of existing columns, this can also be used for columns
+// of the corresponding MIR and AIR schemas.
+func (p *Schema) ColumnDisplay(column uint) sc.Display {
+	if column < uint(len(p.inputs)) {
+		if col, ok := p.inputs[column].(DataColumn); ok {
+			return col.Display()
+		}
+	}
+	//
+	return sc.DISPLAY_DEFAULT
+}
+
 // ============================================================================
 // Schema Interface
 // ============================================================================
@@ -228,6 +287,14 @@ func (p *Schema) GobEncode() (data []byte, err error) {
 	if err := gobEncoder.Encode(p.assertions); err != nil {
 		return nil, err
 	}
+	// Register sources
+	if err := gobEncoder.Encode(p.sources); err != nil {
+		return nil, err
+	}
+	// Inline tests
+	if err := gobEncoder.Encode(p.tests); err != nil {
+		return nil, err
+	}
 	// Success
 	return buffer.Bytes(), nil
 }
@@ -256,6 +323,14 @@ func (p *Schema) GobDecode(data []byte) error {
 	if err := gobDecoder.Decode(&p.assertions); err != nil {
 		return err
 	}
+	// Register sources (which are absent from older binary files)
+	if err := gobDecoder.Decode(&p.sources); err != nil && err != io.EOF {
+		return err
+	}
+	// Inline tests (which are absent from older binary files)
+	if err := gobDecoder.Decode(&p.tests); err != nil && err != io.EOF {
+		return err
+	}
 	// Rebuild column cache
 	p.rebuildCaches()
 	// Success
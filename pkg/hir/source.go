@@ -0,0 +1,69 @@
+package hir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// RegisterSource identifies a source-level column which was allocated to a
+// given HIR column (a.k.a. register).  Since register allocation can merge
+// columns from different perspectives of the same module into a single
+// register, a register may have several sources.  These are retained in the
+// schema so that tooling can map registers back to the columns that the user
+// actually wrote.
+type RegisterSource struct {
+	// Name of the module in which the source-level column is declared.
+	Module string
+	// Name of the perspective in which the source-level column is declared,
+	// or empty if it is not declared in a perspective.
+	Perspective string
+	// Unqualified name of the source-level column.
+	Name string
+	// Selector for the enclosing perspective, or nil if the source-level column
+	// is not declared in a perspective.
+	Selector Expr
+}
+
+// NewRegisterSource constructs a new register source for a column declared in
+// a given module and (optional) perspective.
+func NewRegisterSource(module string, perspective string, name string, selector Expr) RegisterSource {
+	return RegisterSource{module, perspective, name, selector}
+}
+
+// QualifiedName returns the perspective-qualified name of the source-level
+// column (e.g. "mod/persp.COL").
+func (p RegisterSource) QualifiedName() string {
+	var builder strings.Builder
+	//
+	if p.Module != "" {
+		builder.WriteString(fmt.Sprintf("%s/", p.Module))
+	}
+	//
+	if p.Perspective != "" {
+		builder.WriteString(fmt.Sprintf("%s.", p.Perspective))
+	}
+	//
+	builder.WriteString(p.Name)
+	//
+	return builder.String()
+}
+
+// IsActiveAt determines whether or not this source-level column is active on a
+// given row of a trace.  A column declared within a perspective is active only
+// on those rows where the perspective's selector is non-zero, whilst any other
+// column is always active.
+func (p RegisterSource) IsActiveAt(k int, tr trace.Trace) bool {
+	if p.Selector == nil {
+		return true
+	}
+	//
+	for _, v := range p.Selector.EvalAllAt(k, tr) {
+		if !v.IsZero() {
+			return true
+		}
+	}
+	//
+	return false
+}
@@ -0,0 +1,53 @@
+package mir
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// ExplainAt explains the evaluation of a column access at a given row in a
+// trace.
+func (e *ColumnAccess) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(k, tr)}, "")
+}
+
+// ExplainAt explains the evaluation of a constant at a given row in a trace.
+func (e *Constant) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	return sc.NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(k, tr)}, "")
+}
+
+// ExplainAt explains the evaluation of a sum at a given row in a trace by
+// explaining each of its arguments.
+func (e *Add) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	args := sc.ExplainAll(k, tr, schema, e.Args)
+	return sc.NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(k, tr)}, "", args...)
+}
+
+// ExplainAt explains the evaluation of a product at a given row in a trace by
+// explaining each of its arguments.
+func (e *Mul) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	args := sc.ExplainAll(k, tr, schema, e.Args)
+	return sc.NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(k, tr)}, "", args...)
+}
+
+// ExplainAt explains the evaluation of a subtraction at a given row in a trace
+// by explaining each of its arguments.
+func (e *Sub) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	args := sc.ExplainAll(k, tr, schema, e.Args)
+	return sc.NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(k, tr)}, "", args...)
+}
+
+// ExplainAt explains the evaluation of an exponent at a given row in a trace by
+// explaining its argument.
+func (e *Exp) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	arg := e.Arg.ExplainAt(k, tr, schema)
+	return sc.NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(k, tr)}, "", arg)
+}
+
+// ExplainAt explains the evaluation of a normalisation at a given row in a
+// trace by explaining its argument.
+func (e *Normalise) ExplainAt(k int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	arg := e.Arg.ExplainAt(k, tr, schema)
+	return sc.NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(k, tr)}, "", arg)
+}
@@ -15,6 +15,7 @@ import (
 type Expr interface {
 	util.Boundable
 	sc.Evaluable
+	sc.Explainable
 }
 
 // ============================================================================
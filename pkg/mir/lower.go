@@ -2,6 +2,7 @@ package mir
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/consensys/go-corset/pkg/air"
@@ -22,26 +23,43 @@ func (p *Schema) LowerToAir() *air.Schema {
 	// Add data columns.
 	for _, c := range p.inputs {
 		col := c.(DataColumn)
-		airSchema.AddColumn(col.Context(), col.Name(), col.Type())
+		airSchema.AddColumn(col.Context(), col.Name(), col.Type(), col.Display(), col.Padding())
 	}
 	// Add Assignments. Again this has to be done first for things to work.
 	// Essentially to reflect the fact that these columns have been added above
 	// before others.  Realistically, the overall design of this process is a
 	// bit broken right now.
-	for _, assign := range p.assignments {
+	for i, assign := range p.assignments {
+		mark := airSchema.Mark()
 		airSchema.AddAssignment(assign)
+		// Record provenance
+		airSchema.AttributeOrigin(mark, airSchema.Mark(), "MIR", sc.OriginOf(assign, p), math.MaxUint)
+		airSchema.AttributeSource(mark, airSchema.Mark(), p.AssignmentProvenance(uint(i)), sc.LocationOf(assign))
 	}
 	// Now, lower assignments.
-	for _, assign := range p.assignments {
+	for i, assign := range p.assignments {
+		mark := airSchema.Mark()
 		lowerAssignmentToAir(assign, p, airSchema)
+		// Record provenance
+		airSchema.AttributeOrigin(mark, airSchema.Mark(), "MIR", sc.OriginOf(assign, p), math.MaxUint)
+		airSchema.AttributeSource(mark, airSchema.Mark(), p.AssignmentProvenance(uint(i)), sc.LocationOf(assign))
 	}
 	// Lower vanishing constraints
-	for _, c := range p.constraints {
+	for i, c := range p.constraints {
+		mark := airSchema.Mark()
 		lowerConstraintToAir(c, airSchema)
+		// Propagate source location
+		sc.LocateConstraints(airSchema.Constraints(), mark.Constraints, sc.LocationOf(c))
+		// Record provenance
+		airSchema.AttributeOrigin(mark, airSchema.Mark(), "MIR", sc.OriginOf(c, p), uint(i))
+		airSchema.AttributeSource(mark, airSchema.Mark(), p.ConstraintProvenance(uint(i)), sc.LocationOf(c))
 	}
 	// Add assertions (these do not need to be lowered)
 	for _, assertion := range p.assertions {
+		n := airSchema.Assertions().Count()
 		airSchema.AddPropertyAssertion(assertion.Handle, assertion.Context, assertion.Property)
+		// Propagate source location
+		sc.LocateConstraints(airSchema.Assertions(), n, assertion.Location)
 	}
 	// Done
 	return airSchema
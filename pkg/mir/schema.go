@@ -52,6 +52,8 @@ type Schema struct {
 	assertions []PropertyAssertion
 	// Cache list of columns declared in inputs and assignments.
 	column_cache []schema.Column
+	// Provenance of assignments and constraints arising from lowering.
+	schema.Provenances
 }
 
 // EmptySchema is used to construct a fresh schema onto which new columns and
@@ -68,6 +70,12 @@ func EmptySchema() *Schema {
 	return p
 }
 
+// Mark identifies the current point in the construction of this schema, such
+// that any assignments and constraints added subsequently can be attributed.
+func (p *Schema) Mark() schema.ProvenanceMark {
+	return schema.ProvenanceMark{Assignments: uint(len(p.assignments)), Constraints: uint(len(p.constraints))}
+}
+
 // AddModule adds a new module to this schema, returning its module index.
 func (p *Schema) AddModule(name string) uint {
 	mid := uint(len(p.modules))
@@ -76,13 +84,15 @@ func (p *Schema) AddModule(name string) uint {
 	return mid
 }
 
-// AddDataColumn appends a new data column.
-func (p *Schema) AddDataColumn(context trace.Context, name string, base schema.Type) {
+// AddDataColumn appends a new data column with a given type, display and
+// padding value.
+func (p *Schema) AddDataColumn(context trace.Context, name string, base schema.Type, display schema.Display,
+	padding fr.Element) {
 	if context.Module() >= uint(len(p.modules)) {
 		panic(fmt.Sprintf("invalid module index (%d)", context.Module()))
 	}
 	// Create column
-	col := assignment.NewDataColumn(context, name, base)
+	col := assignment.NewDataColumn(context, name, base, display, padding)
 	p.inputs = append(p.inputs, col)
 	// Update column cache
 	for c := col.Columns(); c.HasNext(); {
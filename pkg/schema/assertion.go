@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/consensys/go-corset/pkg/sexp"
 	tr "github.com/consensys/go-corset/pkg/trace"
@@ -14,25 +15,45 @@ type AssertionFailure struct {
 	Handle string
 	// Constraint expression
 	Constraint Testable
-	// Row on which the constraint failed
-	Row uint
+	// Rows on which the constraint failed
+	Rows []uint
+	// Location of the failing constraint in the original source file(s), or
+	// nil if this is unknown.
+	Location *sexp.Location
 }
 
 // Message provides a suitable error message
 func (p *AssertionFailure) Message() string {
 	// Construct useful error message
-	return fmt.Sprintf("assertion \"%s\" does not hold (row %d)", p.Handle, p.Row)
+	return fmt.Sprintf("assertion \"%s\" does not hold (%s)", p.Handle, RowsToString(p.Rows))
 }
 
-// RequiredCells identifies the cells required to evaluate the failing constraint at the failing row.
+// RequiredCells identifies the cells required to evaluate the failing constraint at the failing rows.
 func (p *AssertionFailure) RequiredCells(trace tr.Trace) *util.AnySortedSet[tr.CellRef] {
-	return p.Constraint.RequiredCells(int(p.Row), trace)
+	cells := util.NewAnySortedSet[tr.CellRef]()
+	//
+	for _, row := range p.Rows {
+		cells.InsertSorted(p.Constraint.RequiredCells(int(row), trace))
+	}
+	//
+	return cells
 }
 
 func (p *AssertionFailure) String() string {
 	return p.Message()
 }
 
+// SourceLocation returns the location from which the failing assertion was
+// compiled, or nil if this is unknown.
+func (p *AssertionFailure) SourceLocation() *sexp.Location {
+	return p.Location
+}
+
+// SetSourceLocation updates the location from which the failing assertion was compiled.
+func (p *AssertionFailure) SetSourceLocation(loc *sexp.Location) {
+	p.Location = loc
+}
+
 // PropertyAssertion is similar to a vanishing constraint but is used only for
 // debugging / testing / verification.  Unlike vanishing constraints, property
 // assertions do not represent something that the prover can enforce.  Rather,
@@ -53,28 +74,82 @@ type PropertyAssertion[T Testable] struct {
 	// on a given trace --- we are not restricted to expressions
 	// which can be arithmetised.
 	Property T
+	// Location in the original source file(s) from which this assertion was
+	// compiled, or nil if this is unknown.
+	Location *sexp.Location
 }
 
 // NewPropertyAssertion constructs a new property assertion!
 func NewPropertyAssertion[T Testable](handle string, ctx tr.Context, property T) *PropertyAssertion[T] {
-	return &PropertyAssertion[T]{handle, ctx, property}
+	return &PropertyAssertion[T]{handle, ctx, property, nil}
+}
+
+// SourceLocation returns the location from which this assertion was compiled, or
+// nil if this is unknown.
+func (p *PropertyAssertion[T]) SourceLocation() *sexp.Location {
+	return p.Location
+}
+
+// SetSourceLocation updates the location from which this assertion was compiled.
+func (p *PropertyAssertion[T]) SetSourceLocation(loc *sexp.Location) {
+	p.Location = loc
+}
+
+// Name returns the handle of this assertion.
+func (p *PropertyAssertion[T]) Name() string {
+	return p.Handle
+}
+
+// Modules returns the module to which this assertion applies.
+//
+//nolint:revive
+func (p *PropertyAssertion[T]) Modules(schema Schema) []uint {
+	return []uint{p.Context.Module()}
+}
+
+// RequiredColumns returns the set of columns on which this assertion depends.
+func (p *PropertyAssertion[T]) RequiredColumns() *util.SortedSet[uint] {
+	return p.Property.RequiredColumns()
+}
+
+// Bounds determines the well-definedness bounds of this assertion which, in
+// particular, identifies how many rows beyond any given row it accesses.
+func (p *PropertyAssertion[T]) Bounds() util.Bounds {
+	return p.Property.Bounds()
 }
 
 // Accepts checks whether a vanishing constraint evaluates to zero on every row
-// of a table. If so, return nil otherwise return an error.
+// of a table. If so, return nil otherwise return a failure identifying at most
+// limit failing rows.
+func (p *PropertyAssertion[T]) Accepts(tr tr.Trace, limit uint) Failure {
+	return p.AcceptsWithin(tr, util.NewPair[uint, uint](0, math.MaxUint), limit)
+}
+
+// AcceptsWithin checks whether a vanishing constraint evaluates to zero on
+// every row of a table within a given (inclusive) window. If so, return nil
+// otherwise return a failure identifying at most limit failing rows.
 //
 //nolint:revive
-func (p *PropertyAssertion[T]) Accepts(tr tr.Trace) Failure {
+func (p *PropertyAssertion[T]) AcceptsWithin(tr tr.Trace, window util.Pair[uint, uint], limit uint) Failure {
+	var rows []uint
 	// Determine height of enclosing module
 	height := tr.Height(p.Context)
+	// Restrict to window
+	if window.Right < height {
+		height = window.Right + 1
+	}
 	// Iterate every row in the module
-	for k := uint(0); k < height; k++ {
+	for k := window.Left; k < height && uint(len(rows)) < limit; k++ {
 		// Check whether property holds (or was undefined)
 		if !p.Property.TestAt(int(k), tr) {
 			// Evaluation failure
-			return &AssertionFailure{p.Handle, p.Property, k}
+			rows = append(rows, k)
 		}
 	}
+	// Check for failures
+	if len(rows) > 0 {
+		return &AssertionFailure{p.Handle, p.Property, rows, nil}
+	}
 	// All good
 	return nil
 }
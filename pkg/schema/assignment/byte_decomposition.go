@@ -99,6 +99,12 @@ func (p *ByteDecomposition) RequiredSpillage() uint {
 	return uint(0)
 }
 
+// Bounds determines the well-definedness bounds of this computation.  Since each
+// row is decomposed independently, no other rows are accessed.
+func (p *ByteDecomposition) Bounds() util.Bounds {
+	return util.EMPTY_BOUND
+}
+
 // Dependencies returns the set of columns that this assignment depends upon.
 // That can include both input columns, as well as other computed columns.
 func (p *ByteDecomposition) Dependencies() []uint {
@@ -70,6 +70,12 @@ func (p *ComputedColumn[E]) RequiredSpillage() uint {
 	return p.expr.Bounds().End
 }
 
+// Bounds determines the well-definedness bounds of this computation which, in
+// particular, identifies how many rows beyond any given row it accesses.
+func (p *ComputedColumn[E]) Bounds() util.Bounds {
+	return p.expr.Bounds()
+}
+
 // ComputeColumns computes the values of columns defined by this assignment.
 // Specifically, this creates a new column which contains the result of
 // evaluating a given expression on each row.
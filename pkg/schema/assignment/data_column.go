@@ -4,6 +4,7 @@ import (
 	"encoding/gob"
 	"fmt"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	"github.com/consensys/go-corset/pkg/trace"
@@ -20,11 +21,16 @@ type DataColumn struct {
 	// true for the input columns for any valid trace and, furthermore, every
 	// computed column should have values of this type.
 	DataType sc.Type
+	// Determines how values of this column should be shown to the user.
+	DisplayFormat sc.Display
+	// Value used for any padding rows of this column.
+	PaddingValue fr.Element
 }
 
 // NewDataColumn constructs a new data column with a given name.
-func NewDataColumn(context trace.Context, name string, base sc.Type) *DataColumn {
-	return &DataColumn{context, name, base}
+func NewDataColumn(context trace.Context, name string, base sc.Type, display sc.Display,
+	padding fr.Element) *DataColumn {
+	return &DataColumn{context, name, base, display, padding}
 }
 
 // Context returns the evaluation context for this column.
@@ -47,6 +53,16 @@ func (p *DataColumn) Type() sc.Type {
 	return p.DataType
 }
 
+// Display returns how values of this column should be shown to the user.
+func (p *DataColumn) Display() sc.Display {
+	return p.DisplayFormat
+}
+
+// Padding returns the value used for any padding rows of this column.
+func (p *DataColumn) Padding() fr.Element {
+	return p.PaddingValue
+}
+
 // ============================================================================
 // Declaration Interface
 // ============================================================================
@@ -77,6 +93,16 @@ func (p *DataColumn) Lisp(schema sc.Schema) sexp.SExp {
 	datatype := sexp.NewSymbol(p.DataType.String())
 	multiplier := sexp.NewSymbol(fmt.Sprintf("x%d", p.TraceContext.LengthMultiplier()))
 	def := sexp.NewList([]sexp.SExp{name, datatype, multiplier})
+	// Display is only shown when one was declared
+	if p.DisplayFormat != sc.DISPLAY_DEFAULT {
+		def.Append(sexp.NewSymbol(":display"))
+		def.Append(sexp.NewSymbol(fmt.Sprintf(":%s", p.DisplayFormat.String())))
+	}
+	// Padding is only shown when it differs from the default
+	if !p.PaddingValue.IsZero() {
+		def.Append(sexp.NewSymbol(":padding"))
+		def.Append(sexp.NewSymbol(p.PaddingValue.String()))
+	}
 	//
 	return sexp.NewList([]sexp.SExp{col, def})
 }
@@ -104,7 +104,7 @@ func (p *Interleaving) ComputeColumns(trace tr.Trace) ([]tr.ArrayColumn, error)
 	}
 	// Padding for the entire column is determined by the padding for the first
 	// column in the interleaving.
-	padding := trace.Column(0).Padding()
+	padding := trace.Column(p.Sources[0]).Padding()
 	// Colunm needs to be expanded.
 	col := tr.NewArrayColumn(ctx, p.Target.Name, data, padding)
 	//
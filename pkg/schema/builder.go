@@ -22,73 +22,126 @@ type TraceBuilder struct {
 	// constraints correctly rejects it.
 	expand bool
 	// Determines the amount of padding to apply to each module in the trace.
-	// At the moment, this is applied uniformly across all modules.  This is
-	// somewhat cumbersome, and it would make sense to support different
-	// protocols.  For example, one obvious protocol is to expand a module's
-	// length upto a power-of-two.
-	padding uint
+	// By default, this is applied uniformly across all modules.  However,
+	// other protocols can be used (e.g. to expand a module's length upto a
+	// power-of-two).
+	padding PaddingProtocol
 	// Determines whether or not trace expansion should be performed in
 	// parallel.  This should be the default, but a sequential option is
 	// retained for debugging purposes.
 	parallel bool
 	// Specify the maximum size of any dispatched batch.
 	batchSize uint
+	// Determines the amount of spillage to apply to each module in the trace,
+	// overriding the inferred spillage.  When this is nil, the inferred
+	// spillage is always used.
+	spillage SpillageOverride
+	// Determines the columns which must be computed during trace expansion.
+	// Only those assignments on which these columns (transitively) depend are
+	// computed, whilst the remaining computed columns are left empty.  When
+	// this is nil, all assignments are computed.
+	columns []uint
+	// Determines the maximum height of any module in the input trace, such that
+	// rows beyond this are discarded before expansion.
+	height uint
 }
 
 // NewTraceBuilder constructs a default trace builder.  The idea is that this
 // could then be customized as needed following the builder pattern.
 func NewTraceBuilder(schema Schema) TraceBuilder {
-	return TraceBuilder{schema, true, 0, true, math.MaxUint}
+	return TraceBuilder{schema, true, FixedPadding(0), true, math.MaxUint, nil, nil, math.MaxUint}
 }
 
 // Expand updates a given builder configuration to perform trace expansion (or
 // not).
 func (tb TraceBuilder) Expand(flag bool) TraceBuilder {
-	return TraceBuilder{tb.schema, flag, tb.padding, tb.parallel, tb.batchSize}
+	return TraceBuilder{tb.schema, flag, tb.padding, tb.parallel, tb.batchSize, tb.spillage, tb.columns, tb.height}
 }
 
 // Padding updates a given builder configuration to use a given amount of padding
+// uniformly across all modules.
 func (tb TraceBuilder) Padding(padding uint) TraceBuilder {
-	return TraceBuilder{tb.schema, tb.expand, padding, tb.parallel, tb.batchSize}
+	return TraceBuilder{tb.schema, tb.expand, FixedPadding(padding), tb.parallel, tb.batchSize, tb.spillage, tb.columns,
+		tb.height}
+}
+
+// PaddingProtocol updates a given builder configuration to use a given protocol
+// for determining the amount of padding to apply to each module.
+func (tb TraceBuilder) PaddingProtocol(protocol PaddingProtocol) TraceBuilder {
+	return TraceBuilder{tb.schema, tb.expand, protocol, tb.parallel, tb.batchSize, tb.spillage, tb.columns, tb.height}
 }
 
 // Parallel updates a given builder configuration to allow trace expansion to be
 // performed concurrently (or not).
 func (tb TraceBuilder) Parallel(parallel bool) TraceBuilder {
-	return TraceBuilder{tb.schema, tb.expand, tb.padding, parallel, tb.batchSize}
+	return TraceBuilder{tb.schema, tb.expand, tb.padding, parallel, tb.batchSize, tb.spillage, tb.columns, tb.height}
 }
 
 // BatchSize sets the maximum number of batches to run in parallel during trace
 // expansion.
 func (tb TraceBuilder) BatchSize(batchSize uint) TraceBuilder {
-	return TraceBuilder{tb.schema, tb.expand, tb.padding, tb.parallel, batchSize}
+	return TraceBuilder{tb.schema, tb.expand, tb.padding, tb.parallel, batchSize, tb.spillage, tb.columns, tb.height}
+}
+
+// Spillage updates a given builder configuration to override the spillage
+// applied to modules.  Observe that spillage lower than that inferred from the
+// schema may lead to valid traces being rejected (see SpillageWarnings).
+func (tb TraceBuilder) Spillage(spillage SpillageOverride) TraceBuilder {
+	return TraceBuilder{tb.schema, tb.expand, tb.padding, tb.parallel, tb.batchSize, spillage, tb.columns, tb.height}
+}
+
+// Columns updates a given builder configuration to compute only those
+// assignments required for the given columns during trace expansion.  This is
+// useful when only a subset of constraints is to be checked.  Observe that
+// other computed columns are left empty in the resulting trace.
+func (tb TraceBuilder) Columns(columns []uint) TraceBuilder {
+	return TraceBuilder{tb.schema, tb.expand, tb.padding, tb.parallel, tb.batchSize, tb.spillage, columns, tb.height}
+}
+
+// Truncate updates a given builder configuration to discard all rows of the input
+// trace beyond a given height in each module (taking into account length
+// multipliers) before expansion.  This is useful when only an initial window of
+// rows is to be checked.  Observe that checking constraints which access rows
+// beyond this height (see SelectedReach) may then give incorrect results.
+func (tb TraceBuilder) Truncate(height uint) TraceBuilder {
+	return TraceBuilder{tb.schema, tb.expand, tb.padding, tb.parallel, tb.batchSize, tb.spillage, tb.columns, height}
 }
 
 // Build takes the given builder configuration, along with a given set of input
 // columns and constructs a trace.
 func (tb TraceBuilder) Build(columns []trace.RawColumn) (trace.Trace, []error) {
+	if tb.height != math.MaxUint {
+		columns = truncateColumns(tb.schema, tb.height, columns)
+	}
+	//
 	tr, errs := tb.initialiseTrace(columns)
 
 	if tr == nil {
 		// Critical failure
 		return nil, errs
 	} else if tb.expand {
+		// Determine required assignments (if applicable)
+		var required []bool
+		//
+		if tb.columns != nil {
+			required = RequiredAssignments(tb.schema, tb.columns)
+		}
 		// Apply spillage
-		applySpillage(tr, tb.schema)
+		applySpillage(tr, tb.schema, tb.spillage)
 		// Expand trace
 		if tb.parallel {
 			// Run (parallel) trace expansion
-			if err := parallelTraceExpansion(tb.batchSize, tb.schema, tr); err != nil {
+			if err := parallelTraceExpansion(tb.batchSize, tb.schema, required, tr); err != nil {
 				return nil, append(errs, err)
 			}
-		} else if err := sequentialTraceExpansion(tb.schema, tr); err != nil {
+		} else if err := sequentialTraceExpansion(tb.schema, required, tr); err != nil {
 			// Expansion errors are fatal as well
 			return nil, append(errs, err)
 		}
 	}
 	// Padding
-	if tb.padding > 0 {
-		padColumns(tr, tb.padding)
+	if err := padColumns(tr, tb.padding); err != nil {
+		return nil, append(errs, err)
 	}
 
 	return tr, errs
@@ -107,10 +160,12 @@ func (tb TraceBuilder) initialiseTrace(cols []trace.RawColumn) (*trace.ArrayTrac
 	columns, colmap := tb.initialiseTraceColumns()
 	// Construct (empty) trace
 	tr := trace.NewArrayTrace(modules, columns)
+	// Determine padding values
+	padding := inputPadding(tb.schema)
 	// Fill trace.
-	warnings1 := fillTraceColumns(modmap, colmap, cols, tr)
+	warnings1 := fillTraceColumns(modmap, colmap, padding, cols, tr)
 	// Validation
-	err, warnings2 := validateTraceColumns(tb.schema, tr)
+	err, warnings2 := validateTraceColumns(tb.schema, padding, tr)
 	// Combine warnings together
 	warnings := append(warnings1, warnings2...)
 	//
@@ -167,8 +222,36 @@ func (tb TraceBuilder) initialiseTraceColumns() ([]trace.ArrayColumn, map[column
 	return columns, colmap
 }
 
-// Fill columns in the corresponding trace from the given input columns
-func fillTraceColumns(modmap map[string]uint, colmap map[columnKey]uint,
+// Determine the padding value for each input column of a given schema, indexed
+// by column.  Input columns which do not designate a padding value are padded
+// with zero.
+func inputPadding(schema Schema) []fr.Element {
+	var padding []fr.Element
+	//
+	for iter := schema.Declarations(); iter.HasNext(); {
+		decl := iter.Next()
+		// Input columns always precede computed columns
+		if decl.IsComputed() {
+			break
+		}
+		//
+		value := fr.NewElement(0)
+		//
+		if p, ok := decl.(Padded); ok {
+			value = p.Padding()
+		}
+		//
+		for c := decl.Columns(); c.HasNext(); c.Next() {
+			padding = append(padding, value)
+		}
+	}
+	//
+	return padding
+}
+
+// Fill columns in the corresponding trace from the given input columns, using
+// the given padding values for input columns.
+func fillTraceColumns(modmap map[string]uint, colmap map[columnKey]uint, padding []fr.Element,
 	cols []trace.RawColumn, tr *trace.ArrayTrace) []error {
 	var zero fr.Element = fr.NewElement(0)
 	// Errs contains the set of filling errors which are accumulated
@@ -188,8 +271,13 @@ func fillTraceColumns(modmap map[string]uint, colmap map[columnKey]uint,
 			} else if tr.Column(cid).Data() != nil {
 				errs = append(errs, fmt.Errorf("duplicate column '%s' in trace", c.QualifiedName()))
 			} else {
-				// Assign data
-				tr.FillColumn(cid, c.Data, zero)
+				// Assign data (where computed columns given in the trace are
+				// padded with zero).
+				if cid < uint(len(padding)) {
+					tr.FillColumn(cid, c.Data, padding[cid])
+				} else {
+					tr.FillColumn(cid, c.Data, zero)
+				}
 			}
 		}
 	}
@@ -197,8 +285,7 @@ func fillTraceColumns(modmap map[string]uint, colmap map[columnKey]uint,
 	return errs
 }
 
-func validateTraceColumns(schema Schema, tr *trace.ArrayTrace) (error, []error) {
-	var zero fr.Element = fr.NewElement(0)
+func validateTraceColumns(schema Schema, padding []fr.Element, tr *trace.ArrayTrace) (error, []error) {
 	// Determine how many input columns to expect
 	ninputs := schema.InputColumns().Count()
 	warnings := []error{}
@@ -219,36 +306,94 @@ func validateTraceColumns(schema Schema, tr *trace.ArrayTrace) (error, []error)
 			// Ok, treat as warning
 			warnings = append(warnings, err)
 			// Fill with a column of height zero.
-			tr.FillColumn(i, util.NewFrArray(0, 256), zero)
+			tr.FillColumn(i, util.NewFrArray(0, 256), padding[i])
 		}
 	}
 	// Done
 	return nil, warnings
 }
 
-// applySpillage pads each module with its given level of spillage
-func applySpillage(tr *trace.ArrayTrace, schema Schema) {
+// Discard all rows beyond a given height from each of the given input columns,
+// taking into account the length multiplier of the column.  Columns which are
+// not input columns of the given schema are left as is.
+func truncateColumns(schema Schema, height uint, columns []trace.RawColumn) []trace.RawColumn {
+	var (
+		modules     = make(map[string]uint)
+		multipliers = make(map[columnKey]uint)
+		truncated   = make([]trace.RawColumn, len(columns))
+	)
+	//
+	for i, iter := uint(0), schema.Modules(); iter.HasNext(); i++ {
+		modules[iter.Next().Name] = i
+	}
+	//
+	for iter := schema.InputColumns(); iter.HasNext(); {
+		col := iter.Next()
+		multipliers[columnKey{col.Context.Module(), col.Name}] = col.Context.LengthMultiplier()
+	}
+	//
+	for i, col := range columns {
+		truncated[i] = col
+		//
+		if mid, ok := modules[col.Module]; !ok {
+			continue
+		} else if multiplier, ok := multipliers[columnKey{mid, col.Name}]; ok && col.Data.Len() > height*multiplier {
+			truncated[i].Data = col.Data.Slice(0, height*multiplier)
+		}
+	}
+	//
+	return truncated
+}
+
+// applySpillage pads each module with its given level of spillage, which is
+// either inferred from the schema or determined by the given override (if
+// applicable).
+func applySpillage(tr *trace.ArrayTrace, schema Schema, override SpillageOverride) {
 	n := tr.Modules().Count()
 	// Iterate over modules
 	for i := uint(0); i < n; i++ {
 		spillage := RequiredSpillage(i, schema)
+		// Check for override
+		if override != nil {
+			if m, ok := override(tr.Modules().Nth(i).Name()); ok {
+				spillage = m
+			}
+		}
+		//
 		tr.Pad(i, spillage)
 	}
 }
 
-// PadColumns pads every column in a given trace with a given amount of padding.
-func padColumns(tr *trace.ArrayTrace, padding uint) {
+// PadColumns pads every column in a given trace according to a given padding
+// protocol.  Modules whose height remains unspecified (i.e. because they have no
+// columns) are not padded.
+func padColumns(tr *trace.ArrayTrace, protocol PaddingProtocol) error {
 	n := tr.Modules().Count()
 	// Iterate over modules
 	for i := uint(0); i < n; i++ {
-		tr.Pad(i, padding)
+		mod := tr.Modules().Nth(i)
+		//
+		if mod.Height() == math.MaxUint {
+			continue
+		}
+		// Determine padding required
+		padding, err := protocol(mod.Name(), mod.Height())
+		//
+		if err != nil {
+			return err
+		} else if padding > 0 {
+			tr.Pad(i, padding)
+		}
 	}
+	//
+	return nil
 }
 
 // sequentialTraceExpansion expands a given trace according to a given schema.
-// More specifically, that means computing the actual values for any
-// assignments.  This is done using a straightforward sequential algorithm.
-func sequentialTraceExpansion(schema Schema, trace *tr.ArrayTrace) error {
+// More specifically, that means computing the actual values for any required
+// assignments (where nil indicates all are required).  This is done using a
+// straightforward sequential algorithm.
+func sequentialTraceExpansion(schema Schema, required []bool, trace *tr.ArrayTrace) error {
 	var err error
 	// Column identifiers for computed columns start immediately following the
 	// designated input columns.
@@ -258,6 +403,11 @@ func sequentialTraceExpansion(schema Schema, trace *tr.ArrayTrace) error {
 		var cols []tr.ArrayColumn
 		// Get ith assignment
 		ith := i.Next()
+		// Skip assignments which are not required
+		if required != nil && !required[j] {
+			cid += ith.Columns().Count()
+			continue
+		}
 		// Compute ith assignment(s)
 		if cols, err = ith.ComputeColumns(trace); err != nil {
 			return err
@@ -275,8 +425,9 @@ func sequentialTraceExpansion(schema Schema, trace *tr.ArrayTrace) error {
 // algorithm operates in waves, rather than using an continuous approach.  This
 // is for two reasons: firstly, the latter would require locks that would slow
 // down evaluation performance; secondly, the vast majority of jobs are run in
-// the very first wave.
-func parallelTraceExpansion(batchsize uint, schema Schema, trace *tr.ArrayTrace) error {
+// the very first wave.  Only required assignments are computed (where nil
+// indicates all are required).
+func parallelTraceExpansion(batchsize uint, schema Schema, required []bool, trace *tr.ArrayTrace) error {
 	batch := 0
 	// Construct a communication channel for errors.
 	ch := make(chan columnBatch, 1024)
@@ -284,11 +435,21 @@ func parallelTraceExpansion(batchsize uint, schema Schema, trace *tr.ArrayTrace)
 	ninputs := schema.InputColumns().Count()
 	// Determine number of columns to compute
 	ntodo := schema.Assignments().Count()
+	//
+	if required != nil {
+		ntodo = 0
+		//
+		for _, r := range required {
+			if r {
+				ntodo++
+			}
+		}
+	}
 	// Iterate until all columns completed.
 	for ntodo > 0 {
 		stats := util.NewPerfStats()
 		// Dispatch next batch of assignments.
-		n := dispatchReadyAssignments(batchsize, ninputs, schema, trace, ch)
+		n := dispatchReadyAssignments(batchsize, ninputs, schema, required, trace, ch)
 		//
 		batches := make([]columnBatch, n)
 		// Collect all the results
@@ -320,15 +481,15 @@ func parallelTraceExpansion(batchsize uint, schema Schema, trace *tr.ArrayTrace)
 // results being fed back into the shared channel.  This returns the number of
 // jobs which have been dispatched (i.e. so the caller knows how many results to
 // expect).
-func dispatchReadyAssignments(batchsize uint, ninputs uint, schema Schema,
+func dispatchReadyAssignments(batchsize uint, ninputs uint, schema Schema, required []bool,
 	trace *tr.ArrayTrace, ch chan columnBatch) uint {
 	count := uint(0)
 	//
-	for iter, cid := schema.Assignments(), ninputs; iter.HasNext() && count < batchsize; {
+	for iter, cid, j := schema.Assignments(), ninputs, 0; iter.HasNext() && count < batchsize; j++ {
 		ith := iter.Next()
-		// Check whether this assignment has already been computed and, if not,
-		// whether or not it is ready.
-		if trace.Column(cid).Data() == nil && isReady(ith, trace) {
+		// Check whether this assignment is required and has already been
+		// computed and, if not, whether or not it is ready.
+		if (required == nil || required[j]) && trace.Column(cid).Data() == nil && isReady(ith, trace) {
 			// Dispatch!
 			go func(index uint) {
 				cols, err := ith.ComputeColumns(trace)
@@ -3,7 +3,10 @@ package constraint
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
+	"slices"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/schema"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
@@ -13,16 +16,129 @@ import (
 
 // LookupFailure provides structural information about a failing lookup constraint.
 type LookupFailure struct {
-	Msg string
+	// Handle of the failing constraint
+	Handle string
+	// Source expressions of the failing lookup
+	Sources []sc.Evaluable
+	// Target expressions of the failing lookup
+	Targets []sc.Evaluable
+	// Context in which all target expressions are evaluated.
+	TargetContext trace.Context
+	// Window of source rows which were checked
+	Window util.Pair[uint, uint]
+	// Rows on which the constraint failed
+	Rows []uint
+	// Location of the failing constraint in the original source file(s), or
+	// nil if this is unknown.
+	Location *sexp.Location
 }
 
 // Message provides a suitable error message
 func (p *LookupFailure) Message() string {
-	return p.Msg
+	return fmt.Sprintf("lookup \"%s\" failed (%s)", p.Handle, sc.RowsToString(p.Rows))
+}
+
+// RequiredCells identifies the cells required to evaluate the source
+// expressions of the failing lookup at the failing rows.
+func (p *LookupFailure) RequiredCells(tr trace.Trace) *util.AnySortedSet[trace.CellRef] {
+	res := util.NewAnySortedSet[trace.CellRef]()
+	//
+	for _, row := range p.Rows {
+		for _, e := range p.Sources {
+			res.InsertSorted(e.RequiredCells(int(row), tr))
+		}
+	}
+	//
+	return res
+}
+
+// Missing identifies every distinct source tuple (within the window of source
+// rows checked) which is not found in the target, along with all source rows
+// on which it arises.  Since this requires rechecking all source rows, it
+// should only be used when reporting failures.
+func (p *LookupFailure) Missing(tr trace.Trace) []Tuple {
+	var tuples []Tuple
+	// Maps missing tuples to their index in tuples
+	missing := make(map[string]int)
+	rows := targetRows(p.Targets, p.TargetContext, tr)
+	//
+	for i := int(p.Window.Left); i <= int(p.Window.Right); i++ {
+		ith_bytes := evalExprsAt(i, p.Sources, tr)
+		// Check whether contained.
+		if rows.Contains(util.NewBytesKey(ith_bytes)) {
+			continue
+		} else if index, ok := missing[string(ith_bytes)]; ok {
+			tuples[index].Multiplicity++
+			tuples[index].Rows = append(tuples[index].Rows, uint(i))
+		} else {
+			missing[string(ith_bytes)] = len(tuples)
+			tuples = append(tuples, Tuple{evalTupleAt(i, p.Sources, tr), 1, []uint{uint(i)}})
+		}
+	}
+	//
+	return tuples
+}
+
+// NearestTargets identifies (at most) n target rows whose tuples are closest to
+// a given (missing) source tuple.  Here, closeness is measured by the number of
+// matching values, such that those rows with the most matching values are
+// returned first.  Ties are broken in favour of earlier rows.  Since the tuple
+// is missing, no row can match on every value and, hence, the search stops
+// early once n rows matching on all but one value are found.
+func (p *LookupFailure) NearestTargets(values []fr.Element, n uint, tr trace.Trace) []uint {
+	var (
+		height = tr.Height(p.TargetContext)
+		// Closest rows found so far, and their number of matches (most first)
+		rows    []uint
+		matches []uint
+		// Most matches possible for a missing tuple
+		best = uint(max(1, len(p.Targets)) - 1)
+	)
+	//
+	for i := uint(0); i < height && n > 0; i++ {
+		count := uint(0)
+		// Count matches for ith target row
+		for j, e := range p.Targets {
+			jth := e.EvalAt(int(i), tr)
+			if jth.Equal(&values[j]) {
+				count++
+			}
+		}
+		// Determine position amongst the closest rows (if any)
+		k := len(rows)
+		for k > 0 && matches[k-1] < count {
+			k--
+		}
+		//
+		if uint(k) < n {
+			rows = slices.Insert(rows, k, i)[:min(n, uint(len(rows)+1))]
+			matches = slices.Insert(matches, k, count)[:len(rows)]
+		}
+		// Check whether any closer rows are possible
+		if uint(len(rows)) == n && matches[n-1] >= best {
+			break
+		}
+	}
+	//
+	return rows
+}
+
+// ExplainAt explains the evaluation of the source expressions of the failing
+// lookup at a given row of a given trace.
+func (p *LookupFailure) ExplainAt(row int, tr trace.Trace, schema sc.Schema) sc.Explanation {
+	sources := make([]sexp.SExp, len(p.Sources))
+	children := make([]sc.Explanation, len(p.Sources))
+	//
+	for i, e := range p.Sources {
+		sources[i] = e.Lisp(schema)
+		children[i] = sc.ExplainEvaluable(row, tr, schema, e)
+	}
+	//
+	return sc.NewExplanation(sexp.NewList(sources), evalTupleAt(row, p.Sources, tr), "missing from target", children...)
 }
 
 func (p *LookupFailure) String() string {
-	return p.Msg
+	return p.Message()
 }
 
 // LookupConstraint (sometimes also called an inclusion constraint) constrains
@@ -54,6 +170,9 @@ type LookupConstraint[E schema.Evaluable] struct {
 	// Targets returns the target expressions which are used to lookup into the
 	// target expressions.
 	Targets []E
+	// Location in the original source file(s) from which this constraint was
+	// compiled, or nil if this is unknown.
+	Location *sexp.Location
 }
 
 // NewLookupConstraint creates a new lookup constraint with a given handle.
@@ -63,36 +182,110 @@ func NewLookupConstraint[E schema.Evaluable](handle string, source trace.Context
 		panic("differeng number of target / source lookup columns")
 	}
 
-	return &LookupConstraint[E]{handle, source, target, sources, targets}
+	return &LookupConstraint[E]{handle, source, target, sources, targets, nil}
+}
+
+// SourceLocation returns the location from which this constraint was compiled, or
+// nil if this is unknown.
+func (p *LookupConstraint[E]) SourceLocation() *sexp.Location {
+	return p.Location
+}
+
+// SetSourceLocation updates the location from which this constraint was compiled.
+func (p *LookupConstraint[E]) SetSourceLocation(loc *sexp.Location) {
+	p.Location = loc
+}
+
+// Name returns the handle of this constraint.
+func (p *LookupConstraint[E]) Name() string {
+	return p.Handle
+}
+
+// Modules returns the source and target modules of this constraint.
+//
+//nolint:revive
+func (p *LookupConstraint[E]) Modules(schema sc.Schema) []uint {
+	if p.SourceContext.Module() == p.TargetContext.Module() {
+		return []uint{p.SourceContext.Module()}
+	}
+	//
+	return []uint{p.SourceContext.Module(), p.TargetContext.Module()}
+}
+
+// RequiredColumns returns the set of columns on which this constraint depends,
+// which includes both source and target columns.
+func (p *LookupConstraint[E]) RequiredColumns() *util.SortedSet[uint] {
+	return util.UnionSortedSets(append(slices.Clone(p.Sources), p.Targets...), func(e E) *util.SortedSet[uint] {
+		return e.RequiredColumns()
+	})
 }
 
 // Accepts checks whether a lookup constraint into the target columns holds for
-// all rows of the source columns.
+// all rows of the source columns.  If not, a failure is returned which
+// identifies at most limit failing rows.
+func (p *LookupConstraint[E]) Accepts(tr trace.Trace, limit uint) schema.Failure {
+	return p.AcceptsWithin(tr, util.NewPair[uint, uint](0, math.MaxUint), limit)
+}
+
+// AcceptsWithin checks whether a lookup constraint into the target columns
+// holds for all rows of the source columns within a given (inclusive) window.
+// Observe that the window does not restrict the target rows being looked up.
+// If not, a failure is returned which identifies at most limit failing rows.
 //
 //nolint:revive
-func (p *LookupConstraint[E]) Accepts(tr trace.Trace) schema.Failure {
+func (p *LookupConstraint[E]) AcceptsWithin(tr trace.Trace, window util.Pair[uint, uint],
+	limit uint) schema.Failure {
+	var failures []uint
 	// Determine height of enclosing module for source columns
 	src_height := tr.Height(p.SourceContext)
-	tgt_height := tr.Height(p.TargetContext)
-	//
-	rows := util.NewHashSet[util.BytesKey](tgt_height)
-	// Add all target columns to the set
-	for i := 0; i < int(tgt_height); i++ {
-		ith_bytes := evalExprsAt(i, p.Targets, tr)
-		rows.Insert(util.NewBytesKey(ith_bytes))
+	rows := targetRows(p.Targets, p.TargetContext, tr)
+	// Restrict source rows to window
+	if window.Right < src_height {
+		src_height = window.Right + 1
 	}
-	// Check all source columns are contained
-	for i := 0; i < int(src_height); i++ {
+	// Check all source columns are contained (stopping at the limit)
+	for i := int(window.Left); i < int(src_height) && uint(len(failures)) < limit; i++ {
 		ith_bytes := evalExprsAt(i, p.Sources, tr)
 		// Check whether contained.
 		if !rows.Contains(util.NewBytesKey(ith_bytes)) {
-			return &LookupFailure{fmt.Sprintf("lookup \"%s\" failed (row %d)", p.Handle, i)}
+			failures = append(failures, uint(i))
 		}
 	}
+	// Check for failures
+	if len(failures) > 0 {
+		sources, targets := toEvaluables(p.Sources), toEvaluables(p.Targets)
+		checked := util.NewPair(window.Left, src_height-1)
+		//
+		return &LookupFailure{p.Handle, sources, targets, p.TargetContext, checked, failures, nil}
+	}
 	//
 	return nil
 }
 
+// Construct the set of all tuples arising in the target expressions of a
+// lookup.
+func targetRows[E schema.Evaluable](targets []E, ctx trace.Context, tr trace.Trace) *util.HashSet[util.BytesKey] {
+	height := tr.Height(ctx)
+	rows := util.NewHashSet[util.BytesKey](height)
+	//
+	for i := 0; i < int(height); i++ {
+		rows.Insert(util.NewBytesKey(evalExprsAt(i, targets, tr)))
+	}
+	//
+	return rows
+}
+
+// Convert an array of expressions into an array of evaluables.
+func toEvaluables[E schema.Evaluable](exprs []E) []sc.Evaluable {
+	evaluables := make([]sc.Evaluable, len(exprs))
+	//
+	for i, e := range exprs {
+		evaluables[i] = e
+	}
+	//
+	return evaluables
+}
+
 func evalExprsAt[E schema.Evaluable](k int, sources []E, tr trace.Trace) []byte {
 	// Each fr.Element is 4 x 64bit words.
 	bytes := make([]byte, 32*len(sources))
@@ -2,6 +2,7 @@ package constraint
 
 import (
 	"fmt"
+	"strings"
 
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
@@ -11,7 +12,19 @@ import (
 
 // PermutationFailure provides structural information about a failing permutation constraint.
 type PermutationFailure struct {
-	Msg string
+	// Handle of the failing permutation
+	Handle string
+	Msg    string
+	// Target columns of the failing permutation
+	Targets []uint
+	// Source columns of the failing permutation
+	Sources []uint
+	// Tuples occurring in the source columns which are missing from the
+	// target columns (or occur there less often).
+	MissingFromTarget []Tuple
+	// Tuples occurring in the target columns which are missing from the
+	// source columns (or occur there less often).
+	MissingFromSource []Tuple
 }
 
 // Message provides a suitable error message
@@ -19,6 +32,16 @@ func (p *PermutationFailure) Message() string {
 	return p.Msg
 }
 
+// Explain explains the failing permutation by breaking down each tuple missing
+// from one side into the values of the columns on the other side from which it
+// arises.
+func (p *PermutationFailure) Explain(schema sc.Schema) []sc.Explanation {
+	targets := explainTuples("target", p.MissingFromTarget, p.Sources, schema)
+	sources := explainTuples("source", p.MissingFromSource, p.Targets, schema)
+	//
+	return append(targets, sources...)
+}
+
 func (p *PermutationFailure) String() string {
 	return p.Msg
 }
@@ -26,6 +49,10 @@ func (p *PermutationFailure) String() string {
 // PermutationConstraint declares a constraint that one (or more) columns are a permutation
 // of another.
 type PermutationConstraint struct {
+	// A unique identifier for this constraint.  Since permutations are not
+	// named, this is determined by the columns involved (see
+	// PermutationHandle).
+	Handle string
 	// Targets returns the indices of the columns composing the "left" table of the
 	// permutation.
 	Targets []uint
@@ -35,12 +62,19 @@ type PermutationConstraint struct {
 }
 
 // NewPermutationConstraint creates a new permutation
-func NewPermutationConstraint(targets []uint, sources []uint) *PermutationConstraint {
+func NewPermutationConstraint(handle string, targets []uint, sources []uint) *PermutationConstraint {
 	if len(targets) != len(sources) {
 		panic("differeng number of target / source permutation columns")
 	}
 
-	return &PermutationConstraint{targets, sources}
+	return &PermutationConstraint{handle, targets, sources}
+}
+
+// PermutationHandle constructs a handle for a permutation between the given
+// target and source columns of a given schema, such as "(X,Y)=(A,B)", using the
+// qualified names of the columns involved.
+func PermutationHandle(targets []uint, sources []uint, schema sc.Schema) string {
+	return fmt.Sprintf("(%s)=(%s)", qualifiedColumnNames(targets, schema), qualifiedColumnNames(sources, schema))
 }
 
 // RequiredSpillage returns the minimum amount of spillage required to ensure
@@ -49,9 +83,42 @@ func (p *PermutationConstraint) RequiredSpillage() uint {
 	return uint(0)
 }
 
+// Name returns the handle of this constraint which, since permutation
+// constraints are not named, is determined by the columns involved.
+func (p *PermutationConstraint) Name() string {
+	return p.Handle
+}
+
+// Modules returns the module constrained by this constraint (i.e. that of its
+// target columns).  A permutation without columns constrains no modules.
+func (p *PermutationConstraint) Modules(schema sc.Schema) []uint {
+	if len(p.Targets) == 0 {
+		return nil
+	}
+	//
+	return []uint{schema.Columns().Nth(p.Targets[0]).Context.Module()}
+}
+
+// RequiredColumns returns the set of columns on which this constraint depends,
+// which includes both source and target columns.
+func (p *PermutationConstraint) RequiredColumns() *util.SortedSet[uint] {
+	columns := util.NewSortedSet[uint]()
+	//
+	for _, c := range p.Targets {
+		columns.Insert(c)
+	}
+	//
+	for _, c := range p.sources {
+		columns.Insert(c)
+	}
+	//
+	return columns
+}
+
 // Accepts checks whether a permutation holds between the source and
-// target columns.
-func (p *PermutationConstraint) Accepts(tr trace.Trace) sc.Failure {
+// target columns.  Since permutations fail as a whole (rather than on specific
+// rows), the limit is ignored.
+func (p *PermutationConstraint) Accepts(tr trace.Trace, _ uint) sc.Failure {
 	// Slice out data
 	src := sliceColumns(p.sources, tr)
 	dst := sliceColumns(p.Targets, tr)
@@ -66,8 +133,11 @@ func (p *PermutationConstraint) Accepts(tr trace.Trace) sc.Failure {
 	//
 	msg := fmt.Sprintf("Target columns (%s) not permutation of source columns (%s)",
 		dst_names, src_names)
+	// Compute multiset difference
+	missingFromTarget := multisetDifference(p.sources, p.Targets, tr)
+	missingFromSource := multisetDifference(p.Targets, p.sources, tr)
 	// Done
-	return &PermutationFailure{msg}
+	return &PermutationFailure{p.Handle, msg, p.Targets, p.sources, missingFromTarget, missingFromSource}
 }
 
 // Lisp converts this schema element into a simple S-Expression, for example
@@ -93,6 +163,30 @@ func (p *PermutationConstraint) Lisp(schema sc.Schema) sexp.SExp {
 	})
 }
 
+// Explain a set of tuples which arise in a given set of columns, but which are
+// missing from a given side of a permutation.
+func explainTuples(side string, tuples []Tuple, columns []uint, schema sc.Schema) []sc.Explanation {
+	explanations := make([]sc.Explanation, len(tuples))
+	names := make([]sexp.SExp, len(columns))
+	//
+	for i, c := range columns {
+		names[i] = sexp.NewSymbol(schema.Columns().Nth(c).QualifiedName(schema))
+	}
+	//
+	for i, t := range tuples {
+		children := make([]sc.Explanation, len(columns))
+		//
+		for j := range columns {
+			children[j] = sc.NewExplanation(names[j], t.Values[j:j+1], "")
+		}
+		//
+		note := fmt.Sprintf("missing from %s %d time(s) (%s)", side, t.Multiplicity, sc.RowsToString(t.Rows))
+		explanations[i] = sc.NewExplanation(sexp.NewList(names), t.Values, note, children...)
+	}
+	//
+	return explanations
+}
+
 func sliceColumns(columns []uint, tr trace.Trace) []util.FrArray {
 	// Allocate return array
 	cols := make([]util.FrArray, len(columns))
@@ -105,3 +199,15 @@ func sliceColumns(columns []uint, tr trace.Trace) []util.FrArray {
 	// Done
 	return cols
 }
+
+// Construct a comma-separated list of the qualified names of the given columns
+// of a given schema.
+func qualifiedColumnNames(columns []uint, schema sc.Schema) string {
+	names := make([]string, len(columns))
+	//
+	for i, c := range columns {
+		names[i] = schema.Columns().Nth(c).QualifiedName(schema)
+	}
+	//
+	return strings.Join(names, ",")
+}
@@ -2,12 +2,14 @@ package constraint
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/schema"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
 )
 
 // RangeFailure provides structural information about a failing type constraint.
@@ -16,20 +18,45 @@ type RangeFailure struct {
 	Handle string
 	// Constraint expression
 	Expr sc.Evaluable
-	// Row on which the constraint failed
-	Row uint
+	// Rows on which the constraint failed
+	Rows []uint
+	// Location of the failing constraint in the original source file(s), or
+	// nil if this is unknown.
+	Location *sexp.Location
 }
 
 // Message provides a suitable error message
 func (p *RangeFailure) Message() string {
 	// Construct useful error message
-	return fmt.Sprintf("expression \"%s\" out-of-bounds (row %d)", p.Handle, p.Row)
+	return fmt.Sprintf("expression \"%s\" out-of-bounds (%s)", p.Handle, sc.RowsToString(p.Rows))
+}
+
+// RequiredCells identifies the cells required to evaluate the failing constraint at the failing rows.
+func (p *RangeFailure) RequiredCells(tr trace.Trace) *util.AnySortedSet[trace.CellRef] {
+	cells := util.NewAnySortedSet[trace.CellRef]()
+	//
+	for _, row := range p.Rows {
+		cells.InsertSorted(p.Expr.RequiredCells(int(row), tr))
+	}
+	//
+	return cells
 }
 
 func (p *RangeFailure) String() string {
 	return p.Message()
 }
 
+// SourceLocation returns the location from which the failing constraint was
+// compiled, or nil if this is unknown.
+func (p *RangeFailure) SourceLocation() *sexp.Location {
+	return p.Location
+}
+
+// SetSourceLocation updates the location from which the failing constraint was compiled.
+func (p *RangeFailure) SetSourceLocation(loc *sexp.Location) {
+	p.Location = loc
+}
+
 // RangeConstraint restricts all values for a given expression to be within a
 // range [0..n) for some bound n.  Any bound is supported, and the system will
 // choose the best underlying implementation as needed.
@@ -48,12 +75,26 @@ type RangeConstraint[E sc.Evaluable] struct {
 	// an fr.Element is used here to store the Bound simply to make the
 	// necessary comparison against table data more direct.
 	Bound fr.Element
+	// Location in the original source file(s) from which this constraint was
+	// compiled, or nil if this is unknown.
+	Location *sexp.Location
 }
 
 // NewRangeConstraint constructs a new Range constraint!
 func NewRangeConstraint[E sc.Evaluable](handle string, context trace.Context,
 	expr E, bound fr.Element) *RangeConstraint[E] {
-	return &RangeConstraint[E]{handle, context, expr, bound}
+	return &RangeConstraint[E]{handle, context, expr, bound, nil}
+}
+
+// SourceLocation returns the location from which this constraint was compiled, or
+// nil if this is unknown.
+func (p *RangeConstraint[E]) SourceLocation() *sexp.Location {
+	return p.Location
+}
+
+// SetSourceLocation updates the location from which this constraint was compiled.
+func (p *RangeConstraint[E]) SetSourceLocation(loc *sexp.Location) {
+	p.Location = loc
 }
 
 // BoundedAtMost determines whether the bound for this constraint is at most a given bound.
@@ -62,23 +103,62 @@ func (p *RangeConstraint[E]) BoundedAtMost(bound uint) bool {
 	return p.Bound.Cmp(&n) <= 0
 }
 
+// Name returns the handle of this constraint.
+func (p *RangeConstraint[E]) Name() string {
+	return p.Handle
+}
+
+// Modules returns the module constrained by this constraint.
+//
+//nolint:revive
+func (p *RangeConstraint[E]) Modules(schema sc.Schema) []uint {
+	return []uint{p.Context.Module()}
+}
+
+// RequiredColumns returns the set of columns on which this constraint depends.
+func (p *RangeConstraint[E]) RequiredColumns() *util.SortedSet[uint] {
+	return p.Expr.RequiredColumns()
+}
+
+// Bounds determines the well-definedness bounds of this constraint which, in
+// particular, identifies how many rows beyond any given row it accesses.
+func (p *RangeConstraint[E]) Bounds() util.Bounds {
+	return p.Expr.Bounds()
+}
+
 // Accepts checks whether a range constraint holds on every row of a table. If so, return
-// nil otherwise return an error.
+// nil otherwise return a failure identifying at most limit failing rows.
+func (p *RangeConstraint[E]) Accepts(tr trace.Trace, limit uint) schema.Failure {
+	return p.AcceptsWithin(tr, util.NewPair[uint, uint](0, math.MaxUint), limit)
+}
+
+// AcceptsWithin checks whether a range constraint holds on every row of a table
+// within a given (inclusive) window. If so, return nil otherwise return a
+// failure identifying at most limit failing rows.
 //
 //nolint:revive
-func (p *RangeConstraint[E]) Accepts(tr trace.Trace) schema.Failure {
+func (p *RangeConstraint[E]) AcceptsWithin(tr trace.Trace, window util.Pair[uint, uint], limit uint) schema.Failure {
+	var rows []uint
 	// Determine height of enclosing module
 	height := tr.Height(p.Context)
+	// Restrict to window
+	if window.Right < height {
+		height = window.Right + 1
+	}
 	// Iterate every row
-	for k := 0; k < int(height); k++ {
+	for k := int(window.Left); k < int(height) && uint(len(rows)) < limit; k++ {
 		// Get the value on the kth row
 		kth := p.Expr.EvalAt(k, tr)
 		// Perform the range check
 		if kth.Cmp(&p.Bound) >= 0 {
 			// Evaluation failure
-			return &RangeFailure{p.Handle, p.Expr, uint(k)}
+			rows = append(rows, uint(k))
 		}
 	}
+	// Check for failures
+	if len(rows) > 0 {
+		return &RangeFailure{p.Handle, p.Expr, rows, nil}
+	}
 	// All good
 	return nil
 }
@@ -0,0 +1,110 @@
+package constraint
+
+import (
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+// Tuple represents a tuple of values arising in a failing constraint (e.g. a
+// source tuple missing from the target of a lookup), along with the rows on
+// which it occurs.
+type Tuple struct {
+	// Values making up this tuple
+	Values []fr.Element
+	// Multiplicity of this tuple.  For example, the number of times it is
+	// missing from the target of a lookup or permutation.
+	Multiplicity uint
+	// Rows on which this tuple occurs
+	Rows []uint
+}
+
+// Evaluate a given set of expressions at a given row, producing a tuple of
+// values.
+func evalTupleAt[E schema.Evaluable](k int, exprs []E, tr trace.Trace) []fr.Element {
+	values := make([]fr.Element, len(exprs))
+	//
+	for i, e := range exprs {
+		values[i] = e.EvalAt(k, tr)
+	}
+	//
+	return values
+}
+
+// Evaluate a given set of columns at a given row, producing a tuple of values.
+func columnTupleAt(k int, columns []uint, tr trace.Trace) []fr.Element {
+	values := make([]fr.Element, len(columns))
+	//
+	for i, c := range columns {
+		values[i] = tr.Column(c).Get(k)
+	}
+	//
+	return values
+}
+
+// Construct a key for a given tuple of values, suitable for use in a map.
+func tupleKey(values []fr.Element) string {
+	var builder strings.Builder
+	//
+	for _, v := range values {
+		bytes := v.Bytes()
+		builder.Write(bytes[:])
+	}
+	//
+	return builder.String()
+}
+
+// Compute the multiset difference between the rows of two sets of columns.
+// Specifically, this identifies those tuples which occur more often in the lhs
+// columns than in the rhs columns.  For each such tuple, its multiplicity
+// indicates how many more times it occurs, and its rows identify all rows of
+// the lhs columns on which it occurs.
+func multisetDifference(lhs []uint, rhs []uint, tr trace.Trace) []Tuple {
+	var (
+		tuples     []Tuple
+		difference []Tuple
+		// Maps tuples to their index in tuples
+		index = make(map[string]int)
+		// Counts occurrences of each tuple in the rhs
+		counts = make(map[string]uint)
+	)
+	// Count rhs tuples
+	for i := 0; i < int(columnsHeight(rhs, tr)); i++ {
+		counts[tupleKey(columnTupleAt(i, rhs, tr))]++
+	}
+	// Collect lhs tuples
+	for i := 0; i < int(columnsHeight(lhs, tr)); i++ {
+		ith := columnTupleAt(i, lhs, tr)
+		key := tupleKey(ith)
+		//
+		if j, ok := index[key]; ok {
+			tuples[j].Rows = append(tuples[j].Rows, uint(i))
+		} else {
+			index[key] = len(tuples)
+			tuples = append(tuples, Tuple{ith, 0, []uint{uint(i)}})
+		}
+	}
+	// Determine those tuples which occur more often
+	for _, t := range tuples {
+		n := uint(len(t.Rows))
+		//
+		if count := counts[tupleKey(t.Values)]; n > count {
+			t.Multiplicity = n - count
+			difference = append(difference, t)
+		}
+	}
+	//
+	return difference
+}
+
+// Determine the height of a given set of columns, which is zero if the set is
+// empty.
+func columnsHeight(columns []uint, tr trace.Trace) uint {
+	if len(columns) == 0 {
+		return 0
+	}
+	//
+	return tr.Column(columns[0]).Data().Len()
+}
@@ -2,7 +2,9 @@ package constraint
 
 import (
 	"fmt"
+	"math"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	sc "github.com/consensys/go-corset/pkg/schema"
 	"github.com/consensys/go-corset/pkg/sexp"
 	tr "github.com/consensys/go-corset/pkg/trace"
@@ -54,6 +56,17 @@ func (p ZeroTest[E]) String() string {
 	return fmt.Sprintf("%s", any(p.Expr))
 }
 
+// ExplainAt explains the evaluation of this zero test at a given row of a given
+// trace.  If the underlying expression cannot be explained, then only its value
+// is reported.
+func (p ZeroTest[E]) ExplainAt(row int, tr tr.Trace, schema sc.Schema) sc.Explanation {
+	if e, ok := any(p.Expr).(sc.Explainable); ok {
+		return e.ExplainAt(row, tr, schema)
+	}
+	//
+	return sc.NewExplanation(p.Expr.Lisp(schema), []fr.Element{p.Expr.EvalAt(row, tr)}, "")
+}
+
 // Lisp converts this schema element into a simple S-Expression, for example
 // so it can be printed.
 func (p ZeroTest[E]) Lisp(schema sc.Schema) sexp.SExp {
@@ -66,25 +79,45 @@ type VanishingFailure struct {
 	Handle string
 	// Constraint expression
 	Constraint sc.Testable
-	// Row on which the constraint failed
-	Row uint
+	// Rows on which the constraint failed
+	Rows []uint
+	// Location of the failing constraint in the original source file(s), or
+	// nil if this is unknown.
+	Location *sexp.Location
 }
 
 // Message provides a suitable error message
 func (p *VanishingFailure) Message() string {
 	// Construct useful error message
-	return fmt.Sprintf("constraint \"%s\" does not hold (row %d)", p.Handle, p.Row)
+	return fmt.Sprintf("constraint \"%s\" does not hold (%s)", p.Handle, sc.RowsToString(p.Rows))
 }
 
-// RequiredCells identifies the cells required to evaluate the failing constraint at the failing row.
+// RequiredCells identifies the cells required to evaluate the failing constraint at the failing rows.
 func (p *VanishingFailure) RequiredCells(trace tr.Trace) *util.AnySortedSet[tr.CellRef] {
-	return p.Constraint.RequiredCells(int(p.Row), trace)
+	cells := util.NewAnySortedSet[tr.CellRef]()
+	//
+	for _, row := range p.Rows {
+		cells.InsertSorted(p.Constraint.RequiredCells(int(row), trace))
+	}
+	//
+	return cells
 }
 
 func (p *VanishingFailure) String() string {
 	return p.Message()
 }
 
+// SourceLocation returns the location from which the failing constraint was
+// compiled, or nil if this is unknown.
+func (p *VanishingFailure) SourceLocation() *sexp.Location {
+	return p.Location
+}
+
+// SetSourceLocation updates the location from which the failing constraint was compiled.
+func (p *VanishingFailure) SetSourceLocation(loc *sexp.Location) {
+	p.Location = loc
+}
+
 // VanishingConstraint specifies a constraint which should hold on every row of the
 // table.  The only exception is when the constraint is undefined (e.g. because
 // it references a non-existent table cell).  In such case, the constraint is
@@ -105,22 +138,67 @@ type VanishingConstraint[T sc.Testable] struct {
 	// The actual Constraint itself (e.g. an expression which
 	// should evaluate to zero, etc)
 	Constraint T
+	// Location in the original source file(s) from which this constraint was
+	// compiled, or nil if this is unknown.
+	Location *sexp.Location
 }
 
 // NewVanishingConstraint constructs a new vanishing constraint!
 func NewVanishingConstraint[T sc.Testable](handle string, context tr.Context,
 	domain util.Option[int], constraint T) *VanishingConstraint[T] {
-	return &VanishingConstraint[T]{handle, context, domain, constraint}
+	return &VanishingConstraint[T]{handle, context, domain, constraint, nil}
+}
+
+// SourceLocation returns the location from which this constraint was compiled, or
+// nil if this is unknown.
+func (p *VanishingConstraint[T]) SourceLocation() *sexp.Location {
+	return p.Location
+}
+
+// SetSourceLocation updates the location from which this constraint was compiled.
+func (p *VanishingConstraint[T]) SetSourceLocation(loc *sexp.Location) {
+	p.Location = loc
+}
+
+// Name returns the handle of this constraint.
+func (p *VanishingConstraint[T]) Name() string {
+	return p.Handle
+}
+
+// Modules returns the module constrained by this constraint.
+//
+//nolint:revive
+func (p *VanishingConstraint[T]) Modules(schema sc.Schema) []uint {
+	return []uint{p.Context.Module()}
+}
+
+// RequiredColumns returns the set of columns on which this constraint depends.
+func (p *VanishingConstraint[T]) RequiredColumns() *util.SortedSet[uint] {
+	return p.Constraint.RequiredColumns()
+}
+
+// Bounds determines the well-definedness bounds of this constraint which, in
+// particular, identifies how many rows beyond any given row it accesses.
+func (p *VanishingConstraint[T]) Bounds() util.Bounds {
+	return p.Constraint.Bounds()
 }
 
 // Accepts checks whether a vanishing constraint evaluates to zero on every row
-// of a table.  If so, return nil otherwise return an error.
+// of a table.  If so, return nil otherwise return a failure identifying at most
+// limit failing rows.
+func (p *VanishingConstraint[T]) Accepts(tr tr.Trace, limit uint) sc.Failure {
+	return p.AcceptsWithin(tr, util.NewPair[uint, uint](0, math.MaxUint), limit)
+}
+
+// AcceptsWithin checks whether a vanishing constraint evaluates to zero on every
+// row of a table within a given (inclusive) window.  If so, return nil otherwise
+// return a failure identifying at most limit failing rows.
 //
 //nolint:revive
-func (p *VanishingConstraint[T]) Accepts(tr tr.Trace) sc.Failure {
+func (p *VanishingConstraint[T]) AcceptsWithin(tr tr.Trace, rows util.Pair[uint, uint], limit uint) sc.Failure {
 	if p.Domain.IsEmpty() {
 		// Global Constraint
-		return HoldsGlobally(p.Handle, p.Context, p.Constraint, tr)
+		return HoldsWithin(p.Handle, p.Context, p.Constraint, rows, tr, limit)
 	}
 	// Extract domain
 	domain := p.Domain.Unwrap()
@@ -135,26 +213,49 @@ func (p *VanishingConstraint[T]) Accepts(tr tr.Trace) sc.Failure {
 	} else {
 		start = uint(domain)
 	}
+	// Check whether row is within window
+	if start < rows.Left || start > rows.Right {
+		return nil
+	}
 	// Check specific row
 	return HoldsLocally(start, p.Handle, p.Constraint, tr)
 }
 
 // HoldsGlobally checks whether a given expression vanishes (i.e. evaluates to
-// zero) for all rows of a trace.  If not, report an appropriate error.
-func HoldsGlobally[T sc.Testable](handle string, ctx tr.Context, constraint T, tr tr.Trace) sc.Failure {
+// zero) for all rows of a trace.  If not, report an appropriate error which
+// identifies at most limit failing rows.
+func HoldsGlobally[T sc.Testable](handle string, ctx tr.Context, constraint T, tr tr.Trace, limit uint) sc.Failure {
+	return HoldsWithin(handle, ctx, constraint, util.NewPair[uint, uint](0, math.MaxUint), tr, limit)
+}
+
+// HoldsWithin checks whether a given expression vanishes (i.e. evaluates to
+// zero) for all rows of a trace within a given (inclusive) window.  If not,
+// report an appropriate error which identifies at most limit failing rows.
+func HoldsWithin[T sc.Testable](handle string, ctx tr.Context, constraint T, window util.Pair[uint, uint],
+	tr tr.Trace, limit uint) sc.Failure {
+	var rows []uint
 	// Determine height of enclosing module
 	height := tr.Height(ctx)
 	// Determine well-definedness bounds for this constraint
 	bounds := constraint.Bounds()
 	// Sanity check enough rows
 	if bounds.End < height {
+		start, end := max(bounds.Start, window.Left), height-bounds.End
+		// Restrict to window
+		if window.Right < end {
+			end = window.Right + 1
+		}
 		// Check all in-bounds values
-		for k := bounds.Start; k < (height - bounds.End); k++ {
-			if err := HoldsLocally(k, handle, constraint, tr); err != nil {
-				return err
+		for k := start; k < end && uint(len(rows)) < limit; k++ {
+			if !constraint.TestAt(int(k), tr) {
+				rows = append(rows, k)
 			}
 		}
 	}
+	// Check for failures
+	if len(rows) > 0 {
+		return &VanishingFailure{handle, constraint, rows, nil}
+	}
 	// Success
 	return nil
 }
@@ -165,7 +266,7 @@ func HoldsLocally[T sc.Testable](k uint, handle string, constraint T, tr tr.Trac
 	// Check whether it holds or not
 	if !constraint.TestAt(int(k), tr) {
 		// Evaluation failure
-		return &VanishingFailure{handle, constraint, k}
+		return &VanishingFailure{handle, constraint, []uint{k}, nil}
 	}
 	// Success
 	return nil
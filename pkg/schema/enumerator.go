@@ -11,29 +11,87 @@ import (
 // TraceEnumerator
 // ============================================================================
 
-// TraceEnumerator is an adaptor which surrounds an enumerator and, essentially,
-// converts flat sequences of elements into traces.
+// TraceEnumerator is an adaptor which surrounds a column enumerator and,
+// essentially, builds each set of columns into a trace.
 type TraceEnumerator struct {
-	// Schema for which traces are being generated
+	// Builder used to construct each trace
+	builder TraceBuilder
+	// Enumerate sets of input columns
+	enumerator util.Enumerator[[]tr.RawColumn]
+}
+
+// NewTraceEnumerator constructs an enumerator for all traces matching the
+// given column specifications using elements sourced from the given pool.  Each
+// trace is constructed using the given builder, whose schema determines the
+// columns being enumerated.  Every module has the given number of lines.
+func NewTraceEnumerator(lines uint, builder TraceBuilder, pool []fr.Element) util.Enumerator[tr.Trace] {
+	heights := make([]uint, builder.schema.Modules().Count())
+	//
+	for i := range heights {
+		heights[i] = lines
+	}
+	//
+	return &TraceEnumerator{builder, NewColumnEnumerator(builder.schema, heights, pool)}
+}
+
+// Next returns the next trace in the enumeration
+func (p *TraceEnumerator) Next() tr.Trace {
+	// Build the trace.
+	trace, errs := p.builder.Build(p.enumerator.Next())
+	// Handle errors
+	if errs != nil {
+		// Should be unreachable, since control the trace!
+		for _, err := range errs {
+			log.Error(err)
+		}
+		// Fail
+		panic("invalid trace constructed")
+	}
+	// Done
+	return trace
+}
+
+// HasNext checks whether the enumeration has more elements (or not).
+func (p *TraceEnumerator) HasNext() bool {
+	return p.enumerator.HasNext()
+}
+
+// ============================================================================
+// ColumnEnumerator
+// ============================================================================
+
+// ColumnEnumerator is an adaptor which surrounds an enumerator and,
+// essentially, converts flat sequences of elements into the input columns of a
+// given schema.
+type ColumnEnumerator struct {
+	// Schema for which columns are being generated
 	schema Schema
-	// Number of lines
-	lines uint
+	// Height of each module (excluding length multipliers)
+	heights []uint
 	// Enumerate sequences of elements
 	enumerator util.Enumerator[[]fr.Element]
 }
 
-// NewTraceEnumerator constructs an enumerator for all traces matching the
-// given column specifications using elements sourced from the given pool.
-func NewTraceEnumerator(lines uint, schema Schema, pool []fr.Element) util.Enumerator[tr.Trace] {
-	ncells := schema.InputColumns().Count() * lines
+// NewColumnEnumerator constructs an enumerator for all sets of input columns of
+// a given schema using elements sourced from the given pool.  Each module has
+// the given height, which is multiplied by the length multiplier of each
+// column.  Observe the number of sets enumerated grows exponentially with the
+// number of cells.
+func NewColumnEnumerator(schema Schema, heights []uint, pool []fr.Element) util.Enumerator[[]tr.RawColumn] {
+	ncells := uint(0)
+	//
+	for iter := schema.InputColumns(); iter.HasNext(); {
+		col := iter.Next()
+		ncells += heights[col.Context.Module()] * col.Context.LengthMultiplier()
+	}
 	// Construct the enumerator
 	enumerator := util.EnumerateElements[fr.Element](ncells, pool)
 	// Done
-	return &TraceEnumerator{schema, lines, enumerator}
+	return &ColumnEnumerator{schema, heights, enumerator}
 }
 
-// Next returns the next trace in the enumeration
-func (p *TraceEnumerator) Next() tr.Trace {
+// Next returns the next set of columns in the enumeration
+func (p *ColumnEnumerator) Next() []tr.RawColumn {
 	ncols := p.schema.InputColumns().Count()
 	elems := p.enumerator.Next()
 	cols := make([]tr.RawColumn, ncols)
@@ -42,9 +100,10 @@ func (p *TraceEnumerator) Next() tr.Trace {
 	// Construct each column from the sequence
 	for iter := p.schema.InputColumns(); iter.HasNext(); {
 		col := iter.Next()
-		data := util.NewFrArray(p.lines, 256)
-		// Slice nrows values from elems
-		for k := uint(0); k < p.lines; k++ {
+		lines := p.heights[col.Context.Module()] * col.Context.LengthMultiplier()
+		data := util.NewFrArray(lines, 256)
+		// Slice lines values from elems
+		for k := uint(0); k < lines; k++ {
 			data.Set(k, elems[j])
 			// Consume element from generated sequence
 			j++
@@ -54,24 +113,11 @@ func (p *TraceEnumerator) Next() tr.Trace {
 		cols[i] = tr.RawColumn{Module: modName, Name: col.Name, Data: data}
 		i++
 	}
-	// Finally, build the trace.
-	builder := NewTraceBuilder(p.schema).Expand(true).Parallel(false).Padding(0)
-	// Build the trace
-	trace, errs := builder.Build(cols)
-	// Handle errors
-	if errs != nil {
-		// Should be unreachable, since control the trace!
-		for _, err := range errs {
-			log.Error(err)
-		}
-		// Fail
-		panic("invalid trace constructed")
-	}
 	// Done
-	return trace
+	return cols
 }
 
 // HasNext checks whether the enumeration has more elements (or not).
-func (p *TraceEnumerator) HasNext() bool {
+func (p *ColumnEnumerator) HasNext() bool {
 	return p.enumerator.HasNext()
 }
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/sexp"
+	tr "github.com/consensys/go-corset/pkg/trace"
+)
+
+// Explainable captures something whose evaluation on a given row of a trace
+// can be explained.  That is, broken down into the evaluation of its
+// constituent subterms.  This is primarily useful for debugging failing
+// constraints.
+type Explainable interface {
+	// ExplainAt explains the evaluation of this term at a given row of a given
+	// trace.
+	ExplainAt(int, tr.Trace, Schema) Explanation
+}
+
+// Explanation records the outcome of evaluating a given term on a given row of
+// a trace, along with explanations for each of its subterms.
+type Explanation struct {
+	// Term being explained.
+	Term sexp.SExp
+	// Values produced by evaluating the term.  Observe that, at the HIR level,
+	// a term may produce multiple values (e.g. for lists).
+	Values []fr.Element
+	// Note provides additional information about the evaluation (e.g. which
+	// branch of a conditional was taken).  This may be empty.
+	Note string
+	// Explanations for the subterms of this term.
+	Children []Explanation
+}
+
+// NewExplanation constructs a new explanation for a given term.
+func NewExplanation(term sexp.SExp, values []fr.Element, note string, children ...Explanation) Explanation {
+	return Explanation{term, values, note, children}
+}
+
+// ExplainAll explains the evaluation of each term in a given slice at a given
+// row of a given trace.
+func ExplainAll[E Explainable](row int, trace tr.Trace, schema Schema, terms []E) []Explanation {
+	explanations := make([]Explanation, len(terms))
+	//
+	for i, e := range terms {
+		explanations[i] = e.ExplainAt(row, trace, schema)
+	}
+	//
+	return explanations
+}
+
+// ExplainEvaluable explains the evaluation of a given expression at a given row
+// of a given trace.  If the expression cannot itself be explained, then only its
+// value is given.
+func ExplainEvaluable(row int, trace tr.Trace, schema Schema, e Evaluable) Explanation {
+	if ex, ok := e.(Explainable); ok {
+		return ex.ExplainAt(row, trace, schema)
+	}
+	//
+	return NewExplanation(e.Lisp(schema), []fr.Element{e.EvalAt(row, trace)}, "")
+}
+
+// Lines produces a human-readable rendering of this explanation, where each
+// subterm is on its own line and indented beneath its enclosing term.
+func (p Explanation) Lines() []string {
+	var lines []string
+	//
+	p.lines(0, &lines)
+	//
+	return lines
+}
+
+func (p Explanation) lines(indent int, lines *[]string) {
+	var builder strings.Builder
+	//
+	builder.WriteString(strings.Repeat("  ", indent))
+	builder.WriteString(p.Term.String(false))
+	builder.WriteString(" = ")
+	// Write out values
+	if len(p.Values) != 1 {
+		builder.WriteString("{")
+	}
+	//
+	for i, v := range p.Values {
+		if i != 0 {
+			builder.WriteString(",")
+		}
+		//
+		builder.WriteString(fmt.Sprintf("0x%s", v.Text(16)))
+	}
+	//
+	if len(p.Values) != 1 {
+		builder.WriteString("}")
+	}
+	// Write out note (if applicable)
+	if p.Note != "" {
+		builder.WriteString(fmt.Sprintf("  [%s]", p.Note))
+	}
+	//
+	*lines = append(*lines, builder.String())
+	// Explain children
+	for _, child := range p.Children {
+		child.lines(indent+1, lines)
+	}
+}
@@ -0,0 +1,143 @@
+package schema
+
+import (
+	"math/big"
+	"math/rand/v2"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ============================================================================
+// TraceGenerator
+// ============================================================================
+
+// ModuleHeights determines the fixed height of a given module.  This returns
+// false when the height of the given module is not fixed.
+type ModuleHeights = func(module string) (uint, bool)
+
+// TraceGenerator generates a given number of random (raw) traces for a given
+// schema.  The height of each module is chosen independently (unless fixed),
+// within a given range, and the values of
+// each input column are drawn from its declared type.  Boundary values of the
+// type (e.g. 0, 1 and 2^n-1) are chosen more often than other values, since
+// these are more likely to uncover problems.  Generation is deterministic for a
+// given seed.
+type TraceGenerator struct {
+	// Schema for which traces are being generated
+	schema Schema
+	// Source of randomness
+	rng *rand.Rand
+	// (Inclusive) range of module heights (excluding length multipliers)
+	heights util.Pair[uint, uint]
+	// Fixed heights of modules (if any)
+	fixed ModuleHeights
+	// Number of traces remaining to be generated
+	remaining uint
+}
+
+// NewTraceGenerator constructs a generator for a given number of random traces
+// of a given schema, using a given seed.  Modules have heights between 1 and
+// maxHeight (inclusive) before their length multiplier is applied.
+func NewTraceGenerator(schema Schema, seed uint64, iterations uint, maxHeight uint) *TraceGenerator {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	//
+	return &TraceGenerator{schema, rng, util.NewPair[uint, uint](1, max(maxHeight, 1)), nil, iterations}
+}
+
+// Heights configures the (inclusive) range of heights from which the height of
+// each module is chosen (before its length multiplier is applied).
+func (p *TraceGenerator) Heights(heights util.Pair[uint, uint]) *TraceGenerator {
+	p.heights = util.NewPair(heights.Left, max(heights.Left, heights.Right))
+	return p
+}
+
+// FixedHeights configures the heights of some (or all) modules to be fixed,
+// rather than chosen at random.
+func (p *TraceGenerator) FixedHeights(fixed ModuleHeights) *TraceGenerator {
+	p.fixed = fixed
+	return p
+}
+
+// Next returns the next trace generated.
+func (p *TraceGenerator) Next() []tr.RawColumn {
+	var (
+		nmodules = p.schema.Modules().Count()
+		heights  = make([]uint, nmodules)
+		cols     []tr.RawColumn
+	)
+	// Choose height for each module (unless fixed)
+	for i := range heights {
+		if p.fixed == nil {
+			heights[i] = p.randomHeight()
+		} else if h, ok := p.fixed(p.schema.Modules().Nth(uint(i)).Name); ok {
+			heights[i] = h
+		} else {
+			heights[i] = p.randomHeight()
+		}
+	}
+	// Generate each input column
+	for iter := p.schema.InputColumns(); iter.HasNext(); {
+		col := iter.Next()
+		height := heights[col.Context.Module()] * col.Context.LengthMultiplier()
+		data := util.NewFrArray(height, col.DataType.BitWidth())
+		//
+		for k := uint(0); k < height; k++ {
+			data.Set(k, p.randomValue(col.DataType))
+		}
+		//
+		modName := p.schema.Modules().Nth(col.Context.Module()).Name
+		cols = append(cols, tr.RawColumn{Module: modName, Name: col.Name, Data: data})
+	}
+	//
+	p.remaining--
+	//
+	return cols
+}
+
+// HasNext checks whether the generator has more traces to generate (or not).
+func (p *TraceGenerator) HasNext() bool {
+	return p.remaining > 0
+}
+
+// Choose a random module height from the configured range.
+func (p *TraceGenerator) randomHeight() uint {
+	return p.heights.Left + p.rng.UintN(1+p.heights.Right-p.heights.Left)
+}
+
+// Generate a random value of a given type.  With equal probability, this is
+// either one of the type's boundary values or drawn uniformly from the type.
+func (p *TraceGenerator) randomValue(datatype Type) fr.Element {
+	var (
+		val     fr.Element
+		bigval  big.Int
+		nbits   = datatype.BitWidth()
+		nbytes  = datatype.ByteWidth()
+		bytes   = make([]byte, nbytes)
+		uintype = datatype.AsUint()
+	)
+	//
+	if p.rng.UintN(2) == 0 {
+		boundaries := BoundaryValues(datatype)
+		return boundaries[p.rng.UintN(uint(len(boundaries)))]
+	}
+	// Generate random bytes
+	for i := range bytes {
+		bytes[i] = byte(p.rng.UintN(256))
+	}
+	//
+	bigval.SetBytes(bytes)
+	// Discard any excess bits
+	if uintype != nil && nbits < 8*nbytes {
+		var mask big.Int
+		//
+		mask.Lsh(big.NewInt(1), nbits)
+		mask.Sub(&mask, big.NewInt(1))
+		bigval.And(&bigval, &mask)
+	}
+	// Field elements are reduced modulo the prime
+	val.SetBigInt(&bigval)
+	//
+	return val
+}
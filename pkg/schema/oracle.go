@@ -0,0 +1,64 @@
+package schema
+
+import (
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// TraceOracle determines whether or not a given set of input columns exhibits
+// some property of interest (e.g. that a given constraint rejects the trace
+// built from them).
+type TraceOracle = func([]tr.RawColumn) bool
+
+// AcceptedBy constructs an oracle which holds when the trace built from a given
+// set of input columns can be built without errors (or warnings), and is
+// accepted by every constraint of the builder's schema.  Thus, the schema itself
+// acts as the oracle.
+func AcceptedBy(builder TraceBuilder) TraceOracle {
+	return func(columns []tr.RawColumn) bool {
+		trace, errs := builder.Build(columns)
+		//
+		return trace != nil && len(errs) == 0 && len(AcceptsUpto(builder.batchSize, 1, 1, builder.schema, trace)) == 0
+	}
+}
+
+// RejectedBy constructs an oracle which holds when the trace built from a given
+// set of input columns can be built without errors (or warnings), but is
+// rejected by every one of the given constraints.
+func RejectedBy(builder TraceBuilder, constraints ...Constraint) TraceOracle {
+	return func(columns []tr.RawColumn) bool {
+		trace, errs := builder.Build(columns)
+		//
+		if trace == nil || len(errs) > 0 {
+			return false
+		}
+		//
+		for _, c := range constraints {
+			if c.Accepts(trace, 1) == nil {
+				return false
+			}
+		}
+		//
+		return true
+	}
+}
+
+// ClassifyTraces splits the traces produced by a given enumerator into those
+// for which a given oracle holds (e.g. accepted traces) and those for which it
+// does not (e.g. rejected traces).  At most limit traces are considered.
+func ClassifyTraces(traces util.Enumerator[[]tr.RawColumn], oracle TraceOracle,
+	limit uint) ([][]tr.RawColumn, [][]tr.RawColumn) {
+	var holds, fails [][]tr.RawColumn
+	//
+	for n := uint(0); n < limit && traces.HasNext(); n++ {
+		trace := traces.Next()
+		// Check whether trace is valid or not (according to the oracle)
+		if oracle(trace) {
+			holds = append(holds, trace)
+		} else {
+			fails = append(fails, trace)
+		}
+	}
+	//
+	return holds, fails
+}
@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// PaddingProtocol determines how many padding rows to add to a given module of
+// a trace, given the module's name and its height (i.e. number of rows)
+// before padding.  Padding rows are always added at the front of a module.  An
+// error is returned if the module cannot be padded as required by the protocol
+// (e.g. because it already exceeds some fixed height).
+type PaddingProtocol = func(module string, height uint) (uint, error)
+
+// FixedPadding constructs a padding protocol which adds a fixed number of
+// padding rows to every module, irrespective of its height.
+func FixedPadding(n uint) PaddingProtocol {
+	return func(module string, height uint) (uint, error) {
+		return n, nil
+	}
+}
+
+// PowerOfTwoPadding constructs a padding protocol which pads each module upto
+// the next power of two.  Modules whose height is already a power of two
+// receive no padding, as do empty modules.
+func PowerOfTwoPadding() PaddingProtocol {
+	return func(module string, height uint) (uint, error) {
+		if height == 0 {
+			return 0, nil
+		}
+		//
+		return (uint(1) << bits.Len(height-1)) - height, nil
+	}
+}
+
+// FixedHeightPadding constructs a padding protocol which pads each of the given
+// modules upto a given height.  Modules which are not given are not padded,
+// whilst it is an error for a module to exceed its given height.
+func FixedHeightPadding(heights map[string]uint) PaddingProtocol {
+	return func(module string, height uint) (uint, error) {
+		if target, ok := heights[module]; !ok {
+			return 0, nil
+		} else if height > target {
+			return 0, fmt.Errorf("module '%s' has height %d which exceeds fixed height %d", module, height, target)
+		} else {
+			return target - height, nil
+		}
+	}
+}
+
+// SequentialPadding constructs a padding protocol which applies each of the
+// given protocols in turn, such that each protocol sees the height produced by
+// those before it.  For example, this can be used to add a fixed number of
+// padding rows to each module, before padding it upto the next power of two.
+func SequentialPadding(protocols ...PaddingProtocol) PaddingProtocol {
+	return func(module string, height uint) (uint, error) {
+		total := uint(0)
+		//
+		for _, protocol := range protocols {
+			n, err := protocol(module, height+total)
+			//
+			if err != nil {
+				return 0, err
+			}
+			//
+			total += n
+		}
+		//
+		return total, nil
+	}
+}
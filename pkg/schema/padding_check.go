@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"slices"
+
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// PaddingFailure identifies a constraint (or assertion) which rejects a trace
+// consisting entirely of padding.  Since any valid trace can be padded, such a
+// constraint is almost certainly unsound with respect to padding.
+type PaddingFailure struct {
+	// Index of the failing constraint (or assertion) within the schema.
+	Constraint uint
+	// Indicates whether the failing constraint is an assertion.
+	Assertion bool
+	// Amount of padding applied to every module of the failing trace.
+	Padding uint
+	// Failure reported by the constraint.
+	Failure Failure
+}
+
+// SpillageCheck records whether or not the spillage inferred for a given
+// module is sufficient for traces consisting entirely of padding.
+type SpillageCheck struct {
+	// Name of the module being checked.
+	Module string
+	// Spillage inferred for the module from its assignments.
+	Inferred uint
+	// Least amount of spillage for which padding-only traces are rejected by
+	// no more constraints than with ample spillage.
+	Required uint
+}
+
+// IsSufficient determines whether or not the inferred spillage for the module
+// was found to be sufficient.
+func (p SpillageCheck) IsSufficient() bool {
+	return p.Required <= p.Inferred
+}
+
+// PaddingTrace constructs a set of input columns for a given schema where every
+// column is empty.  Thus, after expansion and padding, every row of the trace
+// consists entirely of padding.
+func PaddingTrace(schema Schema) []tr.RawColumn {
+	var columns []tr.RawColumn
+	//
+	for iter := schema.InputColumns(); iter.HasNext(); {
+		col := iter.Next()
+		mod := schema.Modules().Nth(col.Context.Module())
+		data := util.NewFrArray(0, col.DataType.BitWidth())
+		columns = append(columns, tr.RawColumn{Module: mod.Name, Name: col.Name, Data: data})
+	}
+	//
+	return columns
+}
+
+// CheckPadding checks all constraints and assertions of a given schema against
+// traces consisting entirely of padding, for every amount of padding in a given
+// (inclusive) range.  Failures are reported for each constraint rejecting such a
+// trace, whilst errors are reported for any trace which could not be built.
+func CheckPadding(builder TraceBuilder, padding util.Pair[uint, uint]) ([]PaddingFailure, []error) {
+	var (
+		failures []PaddingFailure
+		errs     []error
+		columns  = PaddingTrace(builder.schema)
+	)
+	//
+	for n := padding.Left; n <= padding.Right; n++ {
+		trace, terrs := builder.Padding(n).Build(columns)
+		//
+		if trace == nil {
+			errs = AppendUniqueErrors(errs, terrs...)
+			continue
+		}
+		//
+		failures = append(failures, paddingFailures(builder.schema, trace, n)...)
+	}
+	//
+	return failures, errs
+}
+
+// CheckSpillage checks whether the spillage inferred for each module of a given
+// schema is sufficient for traces consisting entirely of padding, for every
+// amount of padding in a given (inclusive) range.  Specifically, the set of
+// constraints (and assertions) rejecting such traces is compared against that
+// when ample spillage (i.e. more than the inferred spillage and any amount of
+// padding) is given.  The least spillage which matches this is then reported
+// as the required spillage for the module.  Each distinct error arising is
+// reported once.
+func CheckSpillage(builder TraceBuilder, padding util.Pair[uint, uint]) ([]SpillageCheck, []error) {
+	var (
+		checks []SpillageCheck
+		errs   []error
+	)
+	//
+	for i, iter := uint(0), builder.schema.Modules(); iter.HasNext(); i++ {
+		mod := iter.Next()
+		inferred := RequiredSpillage(i, builder.schema)
+		ample := inferred + padding.Right + 1
+		// Determine failing constraints given ample spillage
+		expected, ample_errs := paddingFailureSet(builder, mod.Name, ample, padding, nil)
+		errs = AppendUniqueErrors(errs, ample_errs...)
+		// Find least spillage matching this, stopping at the first found.
+		required := uint(0)
+		//
+		for ; required < ample; required++ {
+			actual, actual_errs := paddingFailureSet(builder, mod.Name, required, padding, expected)
+			errs = AppendUniqueErrors(errs, actual_errs...)
+			//
+			if slices.Equal(actual, expected) {
+				break
+			}
+		}
+		//
+		checks = append(checks, SpillageCheck{mod.Name, inferred, required})
+	}
+	//
+	return checks, errs
+}
+
+// Determine the constraints and assertions of a given schema which reject a
+// given padding-only trace.
+func paddingFailures(schema Schema, trace tr.Trace, padding uint) []PaddingFailure {
+	var failures []PaddingFailure
+	//
+	for i, iter := uint(0), schema.Constraints(); iter.HasNext(); i++ {
+		if failure := iter.Next().Accepts(trace, 1); failure != nil {
+			failures = append(failures, PaddingFailure{i, false, padding, failure})
+		}
+	}
+	//
+	for i, iter := uint(0), schema.Assertions(); iter.HasNext(); i++ {
+		if failure := iter.Next().Accepts(trace, 1); failure != nil {
+			failures = append(failures, PaddingFailure{i, true, padding, failure})
+		}
+	}
+	//
+	return failures
+}
+
+// Determine the set of constraints (and assertions) rejecting a padding-only
+// trace for some amount of padding in a given range, when a given amount of
+// spillage is applied to a given module.  The set is returned as an array of
+// flags indexed by constraint, followed by assertion.  When an expected set is
+// given, this stops at the first failure outside of it (since the sets cannot
+// then match).
+func paddingFailureSet(builder TraceBuilder, module string, spillage uint, padding util.Pair[uint, uint],
+	expected []bool) ([]bool, []error) {
+	var (
+		errs         []error
+		schema       = builder.schema
+		columns      = PaddingTrace(schema)
+		nconstraints = schema.Constraints().Count()
+		set          = make([]bool, nconstraints+schema.Assertions().Count())
+	)
+	//
+	builder = builder.Spillage(ModuleSpillage(map[string]uint{module: spillage}))
+	//
+	for n := padding.Left; n <= padding.Right; n++ {
+		trace, terrs := builder.Padding(n).Build(columns)
+		//
+		if trace == nil {
+			errs = AppendUniqueErrors(errs, terrs...)
+			continue
+		}
+		//
+		for _, f := range paddingFailures(schema, trace, n) {
+			index := f.Constraint
+			//
+			if f.Assertion {
+				index += nconstraints
+			}
+			//
+			set[index] = true
+			//
+			if expected != nil && !expected[index] {
+				return set, errs
+			}
+		}
+	}
+	//
+	return set, errs
+}
+
+// AppendUniqueErrors appends those errors not already present (i.e. with the
+// same message) to a given array of errors.
+func AppendUniqueErrors(errs []error, others ...error) []error {
+	for _, err := range others {
+		if !slices.ContainsFunc(errs, func(e error) bool { return e.Error() == err.Error() }) {
+			errs = append(errs, err)
+		}
+	}
+	//
+	return errs
+}
@@ -0,0 +1,205 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+// Provenance records how a given assignment or constraint arose during
+// lowering.  Specifically, it identifies the item (at the higher IR) from which
+// it was lowered, along with the gadget(s) (if any) responsible for creating
+// it.  When that item was itself lowered, its provenance is chained such that
+// (for example) an AIR item can be traced back to the HIR item and source
+// location from which it ultimately arose.  This is useful for attributing the
+// size of a lowered schema to the items from which it originated.
+type Provenance struct {
+	// IR at which the originating item was defined (e.g. "HIR" or "MIR").
+	IR string
+	// Summary of the originating item (e.g. "(vanish c1 ...)").
+	Origin string
+	// Index of the originating item amongst the constraints of the schema at
+	// the higher IR, or math.MaxUint if it was not a constraint (e.g. it was an
+	// assignment).
+	Constraint uint
+	// Gadget(s) responsible for creating this item, from outermost to
+	// innermost, or empty if it was lowered directly.
+	Gadgets []string
+	// Provenance of the originating item, or nil if it was not itself lowered
+	// (e.g. it was defined at the HIR).
+	Source *Provenance
+	// Location of the originating item in the original source file(s), or nil
+	// if this is unknown.
+	Location *sexp.Location
+}
+
+// IsEmpty checks whether or not anything is known about this provenance.
+func (p Provenance) IsEmpty() bool {
+	return p.Origin == "" && len(p.Gadgets) == 0
+}
+
+// OriginConstraint returns the index of the constraint (at the higher IR) from
+// which this item was lowered, or false if it was not lowered from a
+// constraint.
+func (p Provenance) OriginConstraint() (uint, bool) {
+	return p.Constraint, p.Origin != "" && p.Constraint != math.MaxUint
+}
+
+// Root returns the provenance of the item from which this item ultimately
+// arose, by following the chain of originating items.
+func (p Provenance) Root() Provenance {
+	for p.Source != nil {
+		p = *p.Source
+	}
+	//
+	return p
+}
+
+// String returns a human-readable description of this provenance, including
+// that of the originating item (if it was itself lowered).  Otherwise, the
+// source location of the originating item is included (if known).
+func (p Provenance) String() string {
+	var builder strings.Builder
+	//
+	if p.Origin != "" {
+		builder.WriteString(fmt.Sprintf("from %s %s", p.IR, p.Origin))
+	}
+	//
+	if len(p.Gadgets) > 0 {
+		if p.Origin != "" {
+			builder.WriteString(" ")
+		}
+		//
+		builder.WriteString(fmt.Sprintf("via %s", strings.Join(p.Gadgets, " > ")))
+	}
+	//
+	if p.Source != nil && !p.Source.IsEmpty() {
+		builder.WriteString(fmt.Sprintf(", %s", p.Source.String()))
+	} else if p.Location != nil {
+		builder.WriteString(fmt.Sprintf(" at %s:%d", p.Location.Filename, p.Location.Line))
+	}
+	//
+	return builder.String()
+}
+
+// ProvenanceMark identifies a point during the construction of a schema, such
+// that all assignments and constraints added subsequently can be attributed.
+type ProvenanceMark struct {
+	// Number of assignments at this point.
+	Assignments uint
+	// Number of constraints at this point.
+	Constraints uint
+}
+
+// Attributed captures a schema which records the provenance of its assignments
+// and constraints.
+type Attributed interface {
+	// AssignmentProvenance returns the provenance of the nth assignment of
+	// this schema.
+	AssignmentProvenance(uint) Provenance
+	// ConstraintProvenance returns the provenance of the nth constraint of
+	// this schema.
+	ConstraintProvenance(uint) Provenance
+}
+
+// Provenances records the provenance of the assignments and constraints of a
+// schema, indexed by their position within the schema.  This is intended to be
+// embedded within a schema implementation.
+type Provenances struct {
+	assignments []Provenance
+	constraints []Provenance
+}
+
+// AssignmentProvenance returns the provenance of the nth assignment, which is
+// empty if nothing is known.
+func (p *Provenances) AssignmentProvenance(n uint) Provenance {
+	if n < uint(len(p.assignments)) {
+		return p.assignments[n]
+	}
+	//
+	return Provenance{}
+}
+
+// ConstraintProvenance returns the provenance of the nth constraint, which is
+// empty if nothing is known.
+func (p *Provenances) ConstraintProvenance(n uint) Provenance {
+	if n < uint(len(p.constraints)) {
+		return p.constraints[n]
+	}
+	//
+	return Provenance{}
+}
+
+// AttributeOrigin attributes all assignments and constraints between two marks
+// to a given originating item, unless they have already been attributed to one.
+// When the originating item is a constraint, its index is also given (otherwise
+// this should be math.MaxUint).
+func (p *Provenances) AttributeOrigin(from ProvenanceMark, to ProvenanceMark, ir string, origin string,
+	constraint uint) {
+	p.attribute(from, to, func(item *Provenance) {
+		if item.Origin == "" {
+			item.IR, item.Origin, item.Constraint = ir, origin, constraint
+		}
+	})
+}
+
+// AttributeSource attributes all assignments and constraints between two marks
+// to an originating item with a given provenance (which may be empty) and
+// source location (which may be nil), unless already attributed to one.  This
+// should accompany AttributeOrigin, such that lowered items can be traced back
+// through several IRs.
+func (p *Provenances) AttributeSource(from ProvenanceMark, to ProvenanceMark, source Provenance,
+	location *sexp.Location) {
+	p.attribute(from, to, func(item *Provenance) {
+		if item.Source == nil && item.Location == nil {
+			if !source.IsEmpty() {
+				item.Source = &source
+			}
+			//
+			item.Location = location
+		}
+	})
+}
+
+// AttributeGadget attributes all assignments and constraints between two marks
+// to a given gadget.  Since gadgets can be nested, an item may be attributed to
+// several gadgets, in which case the outermost is listed first.
+func (p *Provenances) AttributeGadget(from ProvenanceMark, to ProvenanceMark, gadget string) {
+	p.attribute(from, to, func(item *Provenance) {
+		item.Gadgets = append([]string{gadget}, item.Gadgets...)
+	})
+}
+
+func (p *Provenances) attribute(from ProvenanceMark, to ProvenanceMark, fn func(*Provenance)) {
+	// Ensure enough space
+	for uint(len(p.assignments)) < to.Assignments {
+		p.assignments = append(p.assignments, Provenance{})
+	}
+	//
+	for uint(len(p.constraints)) < to.Constraints {
+		p.constraints = append(p.constraints, Provenance{})
+	}
+	// Apply attribution
+	for i := from.Assignments; i < to.Assignments; i++ {
+		fn(&p.assignments[i])
+	}
+	//
+	for i := from.Constraints; i < to.Constraints; i++ {
+		fn(&p.constraints[i])
+	}
+}
+
+// OriginOf produces a short summary of a given item (e.g. a constraint or
+// assignment) suitable for identifying it as the origin of lowered items.  For
+// example, a vanishing constraint "c1" is summarised as "(vanish c1 ...)".
+func OriginOf(item Lispifiable, schema Schema) string {
+	lisp := item.Lisp(schema)
+	//
+	if l := lisp.AsList(); l != nil && l.Len() > 2 {
+		return fmt.Sprintf("(%s %s ...)", l.Get(0).String(false), l.Get(1).String(false))
+	}
+	//
+	return lisp.String(false)
+}
@@ -59,6 +59,14 @@ type Declaration interface {
 	IsComputed() bool
 }
 
+// Padded captures a declaration whose columns use a designated value for any
+// padding rows, rather than zero.  For example, a data column can specify the
+// value used to pad it.
+type Padded interface {
+	// Padding returns the value used for padding rows.
+	Padding() fr.Element
+}
+
 // Assignment represents a schema element which declares one or more columns
 // whose values are "assigned" from the results of a computation.  An assignment
 // is a column group which, additionally, can provide information about the
@@ -87,7 +95,46 @@ type Assignment interface {
 // with an error (or eventually perhaps report a warning).
 type Constraint interface {
 	Lispifiable
-	Accepts(tr.Trace) Failure
+	// Accepts determines whether or not this constraint holds on the given
+	// trace.  If not, a failure is returned identifying (at most) the given
+	// number of failing rows.
+	Accepts(tr.Trace, uint) Failure
+}
+
+// Locatable is implemented by constraints (and their failures) which record
+// the location in the original source file(s) from which they were compiled.
+type Locatable interface {
+	// SourceLocation returns the location from which this item was compiled,
+	// or nil if this is unknown.
+	SourceLocation() *sexp.Location
+	// SetSourceLocation updates the location from which this item was
+	// compiled.
+	SetSourceLocation(*sexp.Location)
+}
+
+// Selectable is implemented by constraints which can be identified by name and
+// by the module(s) they constrain.  This allows checking to be restricted to a
+// subset of constraints (and the columns on which they depend).
+type Selectable interface {
+	// Name returns the handle of this constraint, which is empty for
+	// constraints without a handle.
+	Name() string
+	// Modules returns the module(s) constrained by this constraint.
+	Modules(Schema) []uint
+	// RequiredColumns returns the set of columns on which this constraint
+	// depends.  That is, columns whose values may be accessed when checking
+	// this constraint on a given trace.
+	RequiredColumns() *util.SortedSet[uint]
+}
+
+// Windowed is implemented by constraints which can be checked on a given
+// (inclusive) window of rows, rather than on all rows of a trace.  Constraints
+// which must be checked as a whole (e.g. permutations) do not implement this.
+type Windowed interface {
+	// AcceptsWithin determines whether or not this constraint holds on the
+	// given rows of the given trace.  If not, a failure is returned
+	// identifying (at most) the given number of failing rows.
+	AcceptsWithin(tr.Trace, util.Pair[uint, uint], uint) Failure
 }
 
 // Failure embodies structured information about a failing constraint.
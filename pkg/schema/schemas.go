@@ -2,7 +2,10 @@ package schema
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
+	"github.com/consensys/go-corset/pkg/sexp"
 	tr "github.com/consensys/go-corset/pkg/trace"
 	"github.com/consensys/go-corset/pkg/util"
 )
@@ -72,44 +75,93 @@ func ContextOfColumns(cols []uint, schema Schema) tr.Context {
 // whether or not the given trace adheres to the schema constraints.  A trace
 // can fail to adhere to the schema for a variety of reasons, such as having a
 // constraint which does not hold.  Observe that this does not check assertions
-// within the schema hold.
+// within the schema hold.  At most one failing row is reported for each
+// failing constraint.
 //
 //nolint:revive
 func Accepts(batchsize uint, schema Schema, trace tr.Trace) []Failure {
-	errors := make([]Failure, 0)
-	// Initialise batch number (for debugging purposes)
-	batch := uint(0)
-	// Process constraints in batches
-	for iter := schema.Constraints(); iter.HasNext(); {
-		errs := processConstraintBatch("Constraint", batch, batchsize, iter, trace)
-		errors = append(errors, errs...)
-		// Increment batch number
-		batch++
-	}
-	// Success
-	return errors
+	return AcceptsUpto(batchsize, 1, math.MaxUint, schema, trace)
+}
+
+// AcceptsUpto determines whether this schema will accept a given trace, whilst
+// collecting up failures.  Specifically, at most perConstraint failing rows are
+// collected for any given constraint, and at most overall failures (i.e.
+// failing constraints) are collected in total.  A limit of zero indicates there
+// is no limit.
+func AcceptsUpto(batchsize uint, perConstraint uint, overall uint, schema Schema, trace tr.Trace) []Failure {
+	return processConstraints("Constraint", batchsize, perConstraint, overall, allRows, schema.Constraints(), trace)
+}
+
+// AcceptsSelected determines whether the selected constraints of this schema
+// hold on the given (inclusive) window of rows of a given trace, whilst
+// collecting up failures within the given limits (as for AcceptsUpto).
+// Constraints which cannot be checked on a window of rows (e.g. permutations)
+// are checked on all rows.
+func AcceptsSelected(batchsize uint, perConstraint uint, overall uint, filter ConstraintFilter,
+	rows util.Pair[uint, uint], schema Schema, trace tr.Trace) []Failure {
+	constraints := SelectConstraints(schema.Constraints(), filter)
+	return processConstraints("Constraint", batchsize, perConstraint, overall, rows, constraints, trace)
 }
 
 // Asserts determines whether or not this schema will "assert" a given trace.
 // That is, whether or not the given trace adheres to the schema assertions.
 func Asserts(batchsize uint, schema Schema, trace tr.Trace) []Failure {
+	return AssertsUpto(batchsize, 1, math.MaxUint, schema, trace)
+}
+
+// AssertsUpto determines whether or not this schema will "assert" a given
+// trace, whilst collecting up failures.  Specifically, at most perConstraint
+// failing rows are collected for any given assertion, and at most overall
+// failures (i.e. failing assertions) are collected in total.
+func AssertsUpto(batchsize uint, perConstraint uint, overall uint, schema Schema, trace tr.Trace) []Failure {
+	return processConstraints("Assertion", batchsize, perConstraint, overall, allRows, schema.Assertions(), trace)
+}
+
+// AssertsSelected determines whether or not the selected assertions of this
+// schema hold on the given (inclusive) window of rows of a given trace, whilst
+// collecting up failures within the given limits (as for AssertsUpto).
+func AssertsSelected(batchsize uint, perConstraint uint, overall uint, filter ConstraintFilter,
+	rows util.Pair[uint, uint], schema Schema, trace tr.Trace) []Failure {
+	assertions := SelectConstraints(schema.Assertions(), filter)
+	return processConstraints("Assertion", batchsize, perConstraint, overall, rows, assertions, trace)
+}
+
+// Window covering every row of a trace.
+var allRows = util.NewPair[uint, uint](0, math.MaxUint)
+
+// Process a given set of constraints in batches, whilst collecting up failures
+// within the given limits (where a limit of zero indicates no limit).
+func processConstraints(logtitle string, batchsize uint, perConstraint uint, overall uint,
+	rows util.Pair[uint, uint], iter util.Iterator[Constraint], trace tr.Trace) []Failure {
 	errors := make([]Failure, 0)
+	// Sanity check limits
+	if perConstraint == 0 {
+		perConstraint = math.MaxUint
+	}
+	//
+	if overall == 0 {
+		overall = math.MaxUint
+	}
 	// Initialise batch number (for debugging purposes)
 	batch := uint(0)
-	// Process assertions in batches
-	for iter := schema.Assertions(); iter.HasNext(); {
-		errs := processConstraintBatch("Assertion", batch, batchsize, iter, trace)
+	// Process constraints in batches
+	for iter.HasNext() && uint(len(errors)) < overall {
+		errs := processConstraintBatch(logtitle, batch, batchsize, perConstraint, rows, iter, trace)
 		errors = append(errors, errs...)
 		// Increment batch number
 		batch++
 	}
-	// Success
+	// Apply overall limit
+	if uint(len(errors)) > overall {
+		errors = errors[:overall]
+	}
+	// Done
 	return errors
 }
 
 // Process a given set of constraints in a single batch whilst recording all constraint failures.
-func processConstraintBatch(logtitle string, batch uint, batchsize uint, iter util.Iterator[Constraint],
-	trace tr.Trace) []Failure {
+func processConstraintBatch(logtitle string, batch uint, batchsize uint, limit uint, rows util.Pair[uint, uint],
+	iter util.Iterator[Constraint], trace tr.Trace) []Failure {
 	n := uint(0)
 	c := make(chan Failure, 1024)
 	errors := make([]Failure, 0)
@@ -120,8 +172,13 @@ func processConstraintBatch(logtitle string, batch uint, batchsize uint, iter ut
 		ith := iter.Next()
 		// Launch checker for constraint
 		go func() {
+			failure := acceptsWithin(ith, rows, trace, limit)
+			// Record source location of failing constraint (if known)
+			if f, ok := failure.(Locatable); ok {
+				f.SetSourceLocation(LocationOf(ith))
+			}
 			// Send outcome back
-			c <- ith.Accepts(trace)
+			c <- failure
 		}()
 	}
 	//
@@ -137,6 +194,64 @@ func processConstraintBatch(logtitle string, batch uint, batchsize uint, iter ut
 	return errors
 }
 
+// Check whether a given constraint holds on a given window of rows, falling back
+// to checking all rows for constraints which cannot be windowed.
+func acceptsWithin(constraint Constraint, rows util.Pair[uint, uint], trace tr.Trace, limit uint) Failure {
+	if c, ok := constraint.(Windowed); ok && rows != allRows {
+		return c.AcceptsWithin(trace, rows, limit)
+	}
+	//
+	return constraint.Accepts(trace, limit)
+}
+
+// LocationOf returns the source location from which a given item (e.g. a
+// constraint) was compiled, or nil if this is unknown.
+func LocationOf(item any) *sexp.Location {
+	if l, ok := item.(Locatable); ok {
+		return l.SourceLocation()
+	}
+	//
+	return nil
+}
+
+// LocateConstraints assigns a given source location to every constraint from a
+// given position onwards.  This is useful when a single constraint is
+// translated (or lowered) into several constraints, all of which originate from
+// the same source location.  Observe that constraints before the given
+// position are not visited, hence the cost is proportional only to the number
+// of constraints being located.
+func LocateConstraints(constraints util.Iterator[Constraint], start uint, loc *sexp.Location) {
+	n := constraints.Count()
+	//
+	for i := start; i < n; i++ {
+		if l, ok := constraints.Nth(i).(Locatable); ok {
+			l.SetSourceLocation(loc)
+		}
+	}
+}
+
+// RowsToString produces a human-readable description of a set of failing
+// rows, for use in error messages.
+func RowsToString(rows []uint) string {
+	var builder strings.Builder
+	//
+	if len(rows) == 1 {
+		return fmt.Sprintf("row %d", rows[0])
+	}
+	//
+	builder.WriteString("rows ")
+	//
+	for i, row := range rows {
+		if i != 0 {
+			builder.WriteString(",")
+		}
+		//
+		builder.WriteString(fmt.Sprintf("%d", row))
+	}
+	//
+	return builder.String()
+}
+
 // ColumnIndexOf returns the column index of the column with the given name, or
 // returns false if no matching column exists.
 func ColumnIndexOf(schema Schema, module uint, name string) (uint, bool) {
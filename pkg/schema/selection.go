@@ -0,0 +1,176 @@
+package schema
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ConstraintFilter determines whether or not a given constraint should be
+// checked.  This allows checking to be restricted to a subset of constraints
+// (e.g. those of a given module).
+type ConstraintFilter = func(Constraint) bool
+
+// ModuleFilter constructs a constraint filter which selects only those
+// constraints which constrain (at least) one of the given modules.  Constraints
+// which are not Selectable are never selected.
+func ModuleFilter(schema Schema, modules []string) ConstraintFilter {
+	return func(c Constraint) bool {
+		if s, ok := c.(Selectable); ok {
+			for _, mid := range s.Modules(schema) {
+				if slices.Contains(modules, schema.Modules().Nth(mid).Name) {
+					return true
+				}
+			}
+		}
+		//
+		return false
+	}
+}
+
+// NameFilter constructs a constraint filter which selects only those
+// constraints whose handle matches a given regular expression.  Constraints
+// which are not Selectable are never selected.
+func NameFilter(pattern *regexp.Regexp) ConstraintFilter {
+	return func(c Constraint) bool {
+		if s, ok := c.(Selectable); ok {
+			return pattern.MatchString(s.Name())
+		}
+		//
+		return false
+	}
+}
+
+// ConjunctFilter constructs a constraint filter which selects only those
+// constraints selected by all of the given filters.
+func ConjunctFilter(filters ...ConstraintFilter) ConstraintFilter {
+	return func(c Constraint) bool {
+		for _, filter := range filters {
+			if !filter(c) {
+				return false
+			}
+		}
+		//
+		return true
+	}
+}
+
+// SelectConstraints returns an iterator over those constraints selected by a
+// given filter.  A nil filter selects every constraint.
+func SelectConstraints(constraints util.Iterator[Constraint], filter ConstraintFilter) util.Iterator[Constraint] {
+	var selected []Constraint
+	//
+	if filter == nil {
+		return constraints
+	}
+	//
+	for constraints.HasNext() {
+		if ith := constraints.Next(); filter(ith) {
+			selected = append(selected, ith)
+		}
+	}
+	//
+	return util.NewArrayIterator(selected)
+}
+
+// SelectedColumns returns the columns on which the constraints and assertions of
+// a given schema selected by a given filter depend.  Constraints which are not
+// Selectable are assumed to depend on every column.
+func SelectedColumns(schema Schema, filter ConstraintFilter) []uint {
+	columns := util.NewSortedSet[uint]()
+	constraints := SelectConstraints(schema.Constraints(), filter)
+	assertions := SelectConstraints(schema.Assertions(), filter)
+	//
+	for iter := constraints.Append(assertions); iter.HasNext(); {
+		if s, ok := iter.Next().(Selectable); ok {
+			columns.InsertSorted(s.RequiredColumns())
+		} else {
+			return allColumns(schema)
+		}
+	}
+	//
+	return *columns
+}
+
+// SelectedReach determines how many rows beyond any given row are accessed when
+// checking the constraints and assertions of a given schema selected by a given
+// filter on that row, including those accessed when computing the assignments on
+// which they depend.  This returns false if this is unbounded (e.g. for lookups
+// or permutations), in which case any row may be accessed.
+func SelectedReach(schema Schema, filter ConstraintFilter) (uint, bool) {
+	reach := uint(0)
+	constraints := SelectConstraints(schema.Constraints(), filter)
+	assertions := SelectConstraints(schema.Assertions(), filter)
+	//
+	for iter := constraints.Append(assertions); iter.HasNext(); {
+		if b, ok := iter.Next().(util.Boundable); ok {
+			reach = max(reach, b.Bounds().End)
+		} else {
+			return 0, false
+		}
+	}
+	// Assignments can depend on each other, hence their reach accumulates.
+	required := RequiredAssignments(schema, SelectedColumns(schema, filter))
+	//
+	for i, iter := 0, schema.Assignments(); iter.HasNext(); i++ {
+		ith := iter.Next()
+		//
+		if !required[i] {
+			continue
+		} else if b, ok := ith.(util.Boundable); ok {
+			reach += b.Bounds().End
+		} else {
+			return 0, false
+		}
+	}
+	//
+	return reach, true
+}
+
+// RequiredAssignments determines which assignments of a given schema must be
+// computed in order to obtain the values of the given columns.  This includes
+// any assignments on which those assignments (transitively) depend.  The result
+// is indexed by assignment.
+func RequiredAssignments(schema Schema, columns []uint) []bool {
+	var (
+		ninputs  = schema.InputColumns().Count()
+		required = make([]bool, schema.Assignments().Count())
+		// Maps each computed column to its assignment
+		owners   []uint
+		worklist = slices.Clone(columns)
+	)
+	//
+	for i, iter := uint(0), schema.Assignments(); iter.HasNext(); i++ {
+		for n := iter.Next().Columns().Count(); n > 0; n-- {
+			owners = append(owners, i)
+		}
+	}
+	//
+	for len(worklist) > 0 {
+		column := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		// Input columns have no dependencies
+		if column < ninputs {
+			continue
+		}
+		// Otherwise, mark owning assignment as required
+		if index := owners[column-ninputs]; !required[index] {
+			required[index] = true
+			worklist = append(worklist, schema.Assignments().Nth(index).Dependencies()...)
+		}
+	}
+	//
+	return required
+}
+
+// Return the indices of all columns in a given schema.
+func allColumns(schema Schema) []uint {
+	columns := make([]uint, schema.Columns().Count())
+	//
+	for i := range columns {
+		columns[i] = uint(i)
+	}
+	//
+	return columns
+}
@@ -0,0 +1,313 @@
+package schema
+
+import (
+	"math/big"
+	"slices"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	tr "github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// InputPaddingOf constructs a function which determines the padding value for
+// a given (raw) input column of a given schema.  Columns which are not input
+// columns of the schema are padded with zero.
+func InputPaddingOf(schema Schema) func(tr.RawColumn) fr.Element {
+	padding := make(map[string]fr.Element)
+	values := inputPadding(schema)
+	//
+	for i, iter := 0, schema.InputColumns(); iter.HasNext(); i++ {
+		col := iter.Next()
+		mod := schema.Modules().Nth(col.Context.Module()).Name
+		padding[tr.QualifiedColumnName(mod, col.Name)] = values[i]
+	}
+	//
+	return func(col tr.RawColumn) fr.Element {
+		return padding[col.QualifiedName()]
+	}
+}
+
+// InputColumnsOf constructs a predicate which determines whether a given (raw)
+// column is an input column of a given schema.
+func InputColumnsOf(schema Schema) func(tr.RawColumn) bool {
+	inputs := make(map[string]bool)
+	//
+	for iter := schema.InputColumns(); iter.HasNext(); {
+		col := iter.Next()
+		mod := schema.Modules().Nth(col.Context.Module()).Name
+		inputs[tr.QualifiedColumnName(mod, col.Name)] = true
+	}
+	//
+	return func(col tr.RawColumn) bool {
+		return inputs[col.QualifiedName()]
+	}
+}
+
+// ShrinkTrace minimises a given set of input columns whilst preserving a given
+// property (e.g. that a given constraint rejects the trace).  This repeatedly
+// removes ranges of rows from each module, drops unused columns (i.e. those
+// which are not required, if given) and simplifies values towards zero (or the
+// padding value of the column, if given), until a local minimum is reached.
+// That is, until no single step preserves the property.  The given columns are
+// assumed to exhibit the property already, and are not modified.
+func ShrinkTrace(columns []tr.RawColumn, padding func(tr.RawColumn) fr.Element, required func(tr.RawColumn) bool,
+	oracle TraceOracle) []tr.RawColumn {
+	for progress := true; progress; {
+		var rowsRemoved, columnsDropped, valuesSimplified bool
+		//
+		columns, rowsRemoved = shrinkRows(columns, oracle)
+		columns, columnsDropped = shrinkColumns(columns, required, oracle)
+		columns, valuesSimplified = shrinkValues(columns, padding, oracle)
+		//
+		progress = rowsRemoved || columnsDropped || valuesSimplified
+	}
+	//
+	return columns
+}
+
+// Attempt to remove ranges of rows from each module, starting with all rows and
+// halving the size of the ranges considered each time.  Rows are removed from
+// all columns of a module together, taking account of any length multipliers.
+func shrinkRows(columns []tr.RawColumn, oracle TraceOracle) ([]tr.RawColumn, bool) {
+	progress := false
+	//
+	for _, module := range rawModules(columns) {
+		height := rawModuleHeight(module, columns)
+		//
+		for size := height; size > 0; size = size / 2 {
+			for start := uint(0); start < height; {
+				end := min(start+size, height)
+				candidate := removeRows(module, start, end, height, columns)
+				//
+				if oracle(candidate) {
+					columns, height, progress = candidate, height-(end-start), true
+				} else {
+					start = end
+				}
+			}
+			// Ensure size remains sensible
+			size = min(size, height)
+		}
+	}
+	//
+	return columns, progress
+}
+
+// Attempt to drop each column which is not required entirely.  Typically, this
+// is only possible for columns which are not used by the schema.
+func shrinkColumns(columns []tr.RawColumn, required func(tr.RawColumn) bool,
+	oracle TraceOracle) ([]tr.RawColumn, bool) {
+	progress := false
+	//
+	for i := len(columns) - 1; i >= 0; i-- {
+		if required != nil && required(columns[i]) {
+			continue
+		}
+		//
+		candidate := make([]tr.RawColumn, 0, len(columns)-1)
+		candidate = append(candidate, columns[:i]...)
+		candidate = append(candidate, columns[i+1:]...)
+		//
+		if oracle(candidate) {
+			columns, progress = candidate, true
+		}
+	}
+	//
+	return columns, progress
+}
+
+// Attempt to simplify the values of each column.  For each column, this first
+// attempts to replace all values at once before attempting to simplify values
+// individually.  Individual values are simplified towards zero by repeated
+// halving.  Values are never replaced by less simple values (see simplicity),
+// which ensures shrinking terminates.
+func shrinkValues(columns []tr.RawColumn, padding func(tr.RawColumn) fr.Element,
+	oracle TraceOracle) ([]tr.RawColumn, bool) {
+	progress := false
+	zero := fr.NewElement(0)
+	//
+	for i := range columns {
+		targets := []fr.Element{zero}
+		// Include padding value (if applicable)
+		if padding != nil {
+			if pad := padding(columns[i]); !pad.IsZero() {
+				targets = append(targets, pad)
+			}
+		}
+		// Attempt to replace all values at once
+		for k, target := range targets {
+			if minSimplicity(columns[i].Data, targets) < k {
+				break
+			} else if candidate, ok := fillColumn(columns, i, target); ok && oracle(candidate) {
+				columns, progress = candidate, true
+				break
+			}
+		}
+		// Attempt to simplify individual values
+		for row := uint(0); row < columns[i].Data.Len(); row++ {
+			for _, target := range simplifications(columns[i].Data.Get(row), targets) {
+				if candidate := setValue(columns, i, row, target); oracle(candidate) {
+					columns, progress = candidate, true
+					break
+				}
+			}
+		}
+	}
+	//
+	return columns, progress
+}
+
+// Determine the simpler values with which a given value could be replaced, from
+// simplest to least simple.  This includes the target values simpler than the
+// given value followed, for values which are not targets, by successive
+// halvings of the value (for values which are not too large).
+func simplifications(value fr.Element, targets []fr.Element) []fr.Element {
+	var (
+		rank       = simplicity(value, targets)
+		candidates = slices.Clone(targets[:rank])
+		val        big.Int
+	)
+	// Target values are not halved
+	if rank < len(targets) {
+		return candidates
+	}
+	//
+	value.BigInt(&val)
+	// Halve towards zero (for values upto 64 bits).
+	if val.IsUint64() {
+		for v := val.Uint64() >> 1; v > 0; v = v >> 1 {
+			candidates = append(candidates, fr.NewElement(v))
+		}
+	}
+	//
+	return candidates
+}
+
+// Determine the simplicity of a given value with respect to the given target
+// values (from simplest to least simple), where lower is simpler.  Values which
+// are not targets are less simple than all targets.
+func simplicity(value fr.Element, targets []fr.Element) int {
+	if i := slices.Index(targets, value); i >= 0 {
+		return i
+	}
+	//
+	return len(targets)
+}
+
+// Determine the simplicity of the simplest value in a given array with respect
+// to the given target values.
+func minSimplicity(data util.FrArray, targets []fr.Element) int {
+	simplest := len(targets)
+	//
+	for i := uint(0); i < data.Len() && simplest > 0; i++ {
+		simplest = min(simplest, simplicity(data.Get(i), targets))
+	}
+	//
+	return simplest
+}
+
+// Determine the distinct modules of a given set of columns.
+func rawModules(columns []tr.RawColumn) []string {
+	var modules []string
+	//
+	for _, col := range columns {
+		if !slices.Contains(modules, col.Module) {
+			modules = append(modules, col.Module)
+		}
+	}
+	//
+	return modules
+}
+
+// Determine the height of a given module, as the smallest height of any of its
+// columns.  Thus, columns with a length multiplier are taller than their
+// enclosing module.
+func rawModuleHeight(module string, columns []tr.RawColumn) uint {
+	var height *uint
+	//
+	for _, col := range columns {
+		if col.Module == module && (height == nil || col.Data.Len() < *height) {
+			n := col.Data.Len()
+			height = &n
+		}
+	}
+	//
+	if height == nil {
+		return 0
+	}
+	//
+	return *height
+}
+
+// Remove a given range of rows from all columns of a given module, producing an
+// updated set of columns.  Columns which are a multiple of the module's height
+// (i.e. because they have a length multiplier) have the corresponding multiple
+// of rows removed.
+func removeRows(module string, start uint, end uint, height uint, columns []tr.RawColumn) []tr.RawColumn {
+	ncolumns := make([]tr.RawColumn, len(columns))
+	//
+	for i, col := range columns {
+		ncolumns[i] = col
+		//
+		if col.Module != module || height == 0 {
+			continue
+		}
+		// Determine length multiplier
+		multiplier := uint(1)
+		//
+		if col.Data.Len()%height == 0 {
+			multiplier = col.Data.Len() / height
+		}
+		//
+		s, e, n := start*multiplier, end*multiplier, col.Data.Len()
+		data := util.NewFrArray(n-(e-s), col.Data.BitWidth())
+		// Copy retained rows
+		for j := uint(0); j < s; j++ {
+			data.Set(j, col.Data.Get(j))
+		}
+		//
+		for j := e; j < n; j++ {
+			data.Set(j-(e-s), col.Data.Get(j))
+		}
+		//
+		ncolumns[i] = tr.RawColumn{Module: col.Module, Name: col.Name, Data: data}
+	}
+	//
+	return ncolumns
+}
+
+// Replace every value of a given column with a given value, producing an
+// updated set of columns.  This fails if the column already holds only that
+// value.
+func fillColumn(columns []tr.RawColumn, index int, value fr.Element) ([]tr.RawColumn, bool) {
+	col := columns[index]
+	changed := false
+	data := util.NewFrArray(col.Data.Len(), max(col.Data.BitWidth(), util.FrElementBitWidth(value)))
+	//
+	for j := uint(0); j < data.Len(); j++ {
+		changed = changed || col.Data.Get(j) != value
+		data.Set(j, value)
+	}
+	//
+	return replaceColumn(columns, index, data), changed
+}
+
+// Replace a single value of a given column, producing an updated set of
+// columns.  The column is widened as necessary to hold the new value.
+func setValue(columns []tr.RawColumn, index int, row uint, value fr.Element) []tr.RawColumn {
+	data := columns[index].Data.Clone()
+	data = util.WidenFrArray(data, util.FrElementBitWidth(value))
+	data.Set(row, value)
+	//
+	return replaceColumn(columns, index, data)
+}
+
+// Replace the data of a given column, producing an updated set of columns.
+func replaceColumn(columns []tr.RawColumn, index int, data util.FrArray) []tr.RawColumn {
+	ncolumns := make([]tr.RawColumn, len(columns))
+	copy(ncolumns, columns)
+	//
+	ncolumns[index] = tr.RawColumn{Module: columns[index].Module, Name: columns[index].Name, Data: data}
+	//
+	return ncolumns
+}
@@ -0,0 +1,64 @@
+package schema
+
+import "fmt"
+
+// SpillageOverride determines the amount of spillage to apply to a given
+// module, overriding the spillage inferred from the schema.  This returns false
+// when the inferred spillage should be used for the given module.
+type SpillageOverride = func(module string) (uint, bool)
+
+// GlobalSpillage constructs a spillage override which applies the same amount
+// of spillage to every module.
+func GlobalSpillage(n uint) SpillageOverride {
+	return func(module string) (uint, bool) {
+		return n, true
+	}
+}
+
+// ModuleSpillage constructs a spillage override which applies a given amount of
+// spillage to each of the given modules.  Modules which are not given use the
+// inferred spillage.
+func ModuleSpillage(spillage map[string]uint) SpillageOverride {
+	return func(module string) (uint, bool) {
+		n, ok := spillage[module]
+		return n, ok
+	}
+}
+
+// SpillageDriver returns the assignment which determines the required spillage
+// for a given module (i.e. that requiring the most spillage).  This returns nil
+// when no assignment requires more than the initial padding row.
+func SpillageDriver(module uint, schema Schema) Assignment {
+	var driver Assignment
+	// Initial padding row is always required
+	mx := uint(1)
+	//
+	for i := schema.Assignments(); i.HasNext(); {
+		ith := i.Next()
+		//
+		if ith.Context().Module() == module && ith.RequiredSpillage() > mx {
+			driver, mx = ith, ith.RequiredSpillage()
+		}
+	}
+	//
+	return driver
+}
+
+// SpillageWarnings checks the spillage given by an override for each module of
+// a given schema against the spillage inferred for that module, producing a
+// warning for any module where the former is lower.  Such modules may reject
+// valid traces in the presence of padding.
+func SpillageWarnings(schema Schema, override SpillageOverride) []error {
+	var warnings []error
+	//
+	for i, iter := uint(0), schema.Modules(); iter.HasNext(); i++ {
+		mod := iter.Next()
+		//
+		if n, ok := override(mod.Name); ok && n < RequiredSpillage(i, schema) {
+			warnings = append(warnings, fmt.Errorf("spillage %d for module '%s' is below inferred spillage %d", n,
+				mod.Name, RequiredSpillage(i, schema)))
+		}
+	}
+	//
+	return warnings
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/gob"
 	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 )
@@ -194,6 +195,40 @@ func Join(lhs Type, rhs Type) Type {
 	return uRhs
 }
 
+// BoundaryValues determines the "interesting" values of a given type, such as
+// zero, one and the largest value of the type.  For example, the boundary
+// values of u8 are 0, 1, 127, 128 and 255.  Values are returned in ascending
+// order without duplicates.
+func BoundaryValues(datatype Type) []fr.Element {
+	var (
+		values  = []fr.Element{fr.NewElement(0), fr.NewElement(1)}
+		one     = fr.NewElement(1)
+		largest fr.Element
+	)
+	//
+	if t := datatype.AsUint(); t != nil {
+		if t.NumOfBits > 1 {
+			var mid, midMinusOne fr.Element
+			// Compute 2^(n-1)
+			mid.Exp(fr.NewElement(2), big.NewInt(int64(t.NumOfBits-1)))
+			midMinusOne.Sub(&mid, &one)
+			//
+			values = append(values, midMinusOne, mid)
+		}
+		//
+		largest.Sub(&t.ValueBound, &one)
+	} else {
+		// Largest field element (i.e. -1)
+		largest.Neg(&one)
+	}
+	//
+	values = append(values, largest)
+	// Sort and remove duplicates
+	slices.SortFunc(values, func(l, r fr.Element) int { return l.Cmp(&r) })
+	//
+	return slices.Compact(values)
+}
+
 // ============================================================================
 // Encoding / Decoding
 // ============================================================================
@@ -0,0 +1,43 @@
+package sexp
+
+// Location provides a self-contained description of a span within a given
+// source file.  In particular, the text of the first line enclosing the span is
+// retained.  Thus, unlike a span, a location remains meaningful even when the
+// original source file is no longer available (e.g. after it has been compiled
+// into a binary file).
+type Location struct {
+	// Name of the enclosing source file.
+	Filename string
+	// Number of the first enclosing line (counting from 1).
+	Line int
+	// Text of the first enclosing line.
+	Text string
+	// Offset of the span from the start of the first enclosing line.
+	Offset int
+	// Length of the span within the first enclosing line.  Observe that this
+	// is truncated for spans which cross multiple lines.
+	Length int
+}
+
+// Location constructs the location of a given span within this source file.
+func (s *SourceFile) Location(span Span) Location {
+	line := s.FindFirstEnclosingLine(span)
+	offset := span.Start() - line.Start()
+	// Calculate length (ensures don't overflow line)
+	length := min(line.Length()-offset, span.Length())
+	//
+	return Location{s.Filename(), line.Number(), line.String(), offset, length}
+}
+
+// Location determines the location of a given node contained within one of the
+// source files managed by this set of source maps.  If the node is not present
+// in any source map, then false is returned.
+func (p *SourceMaps[T]) Location(node T) (Location, bool) {
+	for _, m := range p.maps {
+		if m.Has(node) {
+			return m.srcfile.Location(m.Get(node)), true
+		}
+	}
+	//
+	return Location{}, false
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/util"
 )
 
@@ -46,6 +47,58 @@ func Test_RemoveMatching_10(t *testing.T) {
 	check_RemoveMatching(t, []int{1, 1, 2, 3}, 1, []int{2, 3})
 }
 
+func Test_FrElementBitWidth_01(t *testing.T) {
+	check_FrElementBitWidth(t, fr.NewElement(0), 0)
+	check_FrElementBitWidth(t, fr.NewElement(1), 1)
+	check_FrElementBitWidth(t, fr.NewElement(255), 8)
+	check_FrElementBitWidth(t, fr.NewElement(256), 9)
+	check_FrElementBitWidth(t, fr.NewElement(1<<63), 64)
+}
+
+func Test_FrElementBitWidth_02(t *testing.T) {
+	var minusOne fr.Element
+	// Largest element of the field
+	minusOne.SetInt64(-1)
+	check_FrElementBitWidth(t, minusOne, uint(fr.Modulus().BitLen()))
+}
+
+func Test_WidenFrArray_01(t *testing.T) {
+	arr := util.NewFrArray(3, 8)
+	// Arrays which are wide enough are unchanged
+	if util.WidenFrArray(arr, 8) != arr || util.WidenFrArray(arr, 0) != arr {
+		t.Errorf("expected array of bitwidth %d to be unchanged", arr.BitWidth())
+	}
+}
+
+func Test_WidenFrArray_02(t *testing.T) {
+	arr := util.NewFrArray(3, 8)
+	arr.Set(1, fr.NewElement(255))
+	widened := util.WidenFrArray(arr, 16)
+	// Elements are retained
+	if widened.BitWidth() < 16 || widened.Len() != 3 {
+		t.Fatalf("expected array of length 3 and bitwidth 16, got length %d and bitwidth %d", widened.Len(),
+			widened.BitWidth())
+	}
+	//
+	for i := uint(0); i < arr.Len(); i++ {
+		if arr.Get(i) != widened.Get(i) {
+			t.Errorf("expected element %d retained after widening", i)
+		}
+	}
+	// Widened array can hold wider elements
+	widened.Set(2, fr.NewElement(65535))
+	//
+	if v := widened.Get(2); v != fr.NewElement(65535) {
+		t.Errorf("expected 65535, got %s", v.String())
+	}
+}
+
+func check_FrElementBitWidth(t *testing.T, element fr.Element, expected uint) {
+	if actual := util.FrElementBitWidth(element); actual != expected {
+		t.Errorf("bitwidth of %s is %d, expected %d", element.String(), actual, expected)
+	}
+}
+
 func check_RemoveMatching(t *testing.T, original []int, item int, expected []int) {
 	actual := util.RemoveMatching(original, func(ith int) bool { return ith == item })
 	if !reflect.DeepEqual(actual, expected) {
@@ -0,0 +1,969 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ===================================================================
+// Check (JSON Reports)
+// ===================================================================
+
+func Test_Cmd_JsonReport_01(t *testing.T) {
+	// Vanishing constraint failure
+	stdout, _, code := RunCorset(t, "check", "--report-format=json",
+		WriteTempFile(t, "trace.json", `{"X": [1]}`), TestDir+"/basic_01.lisp")
+	//
+	records := checkJsonRecords(t, stdout)
+	//
+	if code != 1 || len(records) != 3 {
+		t.Fatalf("expected three failure records and exit code 1, got %d records and exit code %d", len(records), code)
+	}
+	//
+	for _, r := range records {
+		if r["kind"] != "vanishing" || r["handle"] != "heartbeat" {
+			t.Errorf("unexpected failure record %v", r)
+		}
+	}
+}
+
+func Test_Cmd_JsonReport_02(t *testing.T) {
+	// Validation failure
+	stdout, _, code := RunCorset(t, "check", "--report-format=json",
+		WriteTempFile(t, "trace.json", `{"X": [256]}`), WriteTempFile(t, "test.lisp", "(defcolumns (X :i8))"))
+	//
+	records := checkJsonRecords(t, stdout)
+	//
+	if code != 1 || len(records) != 3 {
+		t.Fatalf("expected three failure records and exit code 1, got %d records and exit code %d", len(records), code)
+	}
+	//
+	for _, r := range records {
+		if r["kind"] != "validation" || len(r["cells"].([]any)) != 1 {
+			t.Errorf("unexpected failure record %v", r)
+		}
+	}
+}
+
+// Check every line of the given output is a failure record with the expected
+// fields, returning the records found.
+func checkJsonRecords(t *testing.T, output string) []map[string]any {
+	var records []map[string]any
+	//
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var record map[string]any
+		//
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON record \"%s\" (%s)", line, err)
+		}
+		// Check required fields
+		for _, field := range []string{"ir", "handle", "kind", "message"} {
+			if _, ok := record[field].(string); !ok {
+				t.Errorf("missing (or invalid) field \"%s\" in record %s", field, line)
+			}
+		}
+		//
+		if _, ok := record["rows"].([]any); !ok {
+			t.Errorf("missing (or invalid) field \"rows\" in record %s", line)
+		}
+		//
+		if cells, ok := record["cells"].([]any); !ok {
+			t.Errorf("missing (or invalid) field \"cells\" in record %s", line)
+		} else {
+			for _, cell := range cells {
+				c, ok := cell.(map[string]any)
+				if !ok || c["column"] == nil || c["row"] == nil || c["value"] == nil {
+					t.Errorf("invalid cell %v in record %s", cell, line)
+				}
+			}
+		}
+		//
+		records = append(records, record)
+	}
+	//
+	return records
+}
+
+// ===================================================================
+// Check (Baselines)
+// ===================================================================
+
+// Constraint c1 fails when X is non-zero, and assertion p1 when Y is non-zero.
+const baselineSource = "(defcolumns (X :byte@loob) (Y :byte@loob))\n(defconstraint c1 () X)\n(defproperty p1 Y)"
+
+func Test_Cmd_Baseline_01(t *testing.T) {
+	// Failing constraints and assertions are both recorded
+	baseline, trace, constraints := setupBaseline(t, `{"X": [1], "Y": [1]}`)
+	//
+	checkBaseline(t, baseline, trace, "HIR:c1", "HIR:p1", "MIR:c1", "MIR:p1", "AIR:c1", "AIR:p1")
+	// Known failures are expected
+	stdout, stderr, code := RunCorset(t, "check", "--baseline", baseline, trace, constraints)
+	//
+	if code != 0 || !strings.Contains(stderr, "trace rejected by 2 expected failure(s) (HIR)") {
+		t.Errorf("expected known failures with exit code 0, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+}
+
+func Test_Cmd_Baseline_02(t *testing.T) {
+	// New assertion failures are reported, despite known constraint failures.
+	baseline, trace, constraints := setupBaseline(t, `{"X": [1], "Y": [0]}`)
+	checkBaseline(t, baseline, trace, "HIR:c1", "MIR:c1", "AIR:c1")
+	writeFile(t, trace, `{"X": [1], "Y": [1]}`)
+	//
+	stdout, stderr, code := RunCorset(t, "check", "--hir", "--baseline", baseline, trace, constraints)
+	//
+	if code != 1 || !strings.Contains(stdout+stderr, `assertion "p1" does not hold`) ||
+		strings.Contains(stdout+stderr, `constraint "c1" does not hold`) {
+		t.Errorf("expected only p1 reported with exit code 1, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+}
+
+func Test_Cmd_Baseline_03(t *testing.T) {
+	// Known failures which no longer arise are reported as fixed
+	baseline, trace, constraints := setupBaseline(t, `{"X": [1], "Y": [1]}`)
+	writeFile(t, trace, `{"X": [0], "Y": [1]}`)
+	//
+	stdout, stderr, code := RunCorset(t, "check", "--hir", "--baseline", baseline, trace, constraints)
+	//
+	if code != 0 || !strings.Contains(stderr, `constraint \"c1\" no longer fails (HIR)`) ||
+		strings.Contains(stderr, `constraint \"p1\" no longer fails`) {
+		t.Errorf("expected c1 reported as fixed with exit code 0, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+}
+
+func Test_Cmd_Baseline_04(t *testing.T) {
+	// Updating a baseline replaces it
+	baseline, trace, constraints := setupBaseline(t, `{"X": [1], "Y": [1]}`)
+	writeFile(t, trace, `{"X": [0], "Y": [1]}`)
+	//
+	if _, _, code := RunCorset(t, "check", "--hir", "--baseline", baseline, "--update-baseline", trace,
+		constraints); code != 0 {
+		t.Errorf("expected exit code 0 when updating baseline, got %d", code)
+	}
+	//
+	checkBaseline(t, baseline, trace, "HIR:p1")
+}
+
+func Test_Cmd_Baseline_05(t *testing.T) {
+	// Trace files are identified regardless of how their paths are given
+	baseline, trace, constraints := setupBaseline(t, `{"X": [1], "Y": [1]}`)
+	dir, name := filepath.Split(trace)
+	alias := dir + "." + string(filepath.Separator) + name
+	//
+	stdout, stderr, code := RunCorset(t, "check", "--hir", "--baseline", baseline, alias, constraints)
+	//
+	if code != 0 || !strings.Contains(stderr, "trace rejected by 2 expected failure(s)") {
+		t.Errorf("expected known failures with exit code 0, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+	// Recording normalises paths
+	if _, _, code := RunCorset(t, "check", "--baseline", baseline, "--update-baseline", alias,
+		constraints); code != 0 {
+		t.Errorf("expected exit code 0 when updating baseline, got %d", code)
+	}
+	//
+	checkBaseline(t, baseline, trace, "HIR:c1", "HIR:p1", "MIR:c1", "MIR:p1", "AIR:c1", "AIR:p1")
+}
+
+func Test_Cmd_ExpectFail_01(t *testing.T) {
+	// Expected failures of constraints do not prevent assertions being checked
+	trace := WriteTempFile(t, "trace.json", `{"X": [1], "Y": [1]}`)
+	constraints := WriteTempFile(t, "test.lisp", baselineSource)
+	//
+	stdout, stderr, code := RunCorset(t, "check", "--hir", "--expect-fail=c1", trace, constraints)
+	//
+	if code != 1 || !strings.Contains(stdout+stderr, `assertion "p1" does not hold`) {
+		t.Errorf("expected p1 reported with exit code 1, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+	//
+	if _, _, code = RunCorset(t, "check", "--hir", "--expect-fail=c1", "--expect-fail=p1", trace,
+		constraints); code != 0 {
+		t.Errorf("expected exit code 0 with all failures expected, got %d", code)
+	}
+}
+
+func Test_Cmd_ExpectFail_02(t *testing.T) {
+	// Expected failures must arise
+	stdout, stderr, code := RunCorset(t, "check", "--hir", "--expect-fail=p1",
+		WriteTempFile(t, "trace.json", `{"X": [0], "Y": [0]}`), WriteTempFile(t, "test.lisp", baselineSource))
+	//
+	if code != 1 || !strings.Contains(stderr, `constraint \"p1\" expected to fail (HIR)`) {
+		t.Errorf("expected missing failure with exit code 1, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+}
+
+// Record a baseline for a given trace, returning the baseline, trace and
+// constraint files.
+func setupBaseline(t *testing.T, trace string) (string, string, string) {
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	traceFile := WriteTempFile(t, "trace.json", trace)
+	constraints := WriteTempFile(t, "test.lisp", baselineSource)
+	//
+	if stdout, stderr, code := RunCorset(t, "check", "--baseline", baseline, traceFile, constraints); code != 0 {
+		t.Fatalf("expected exit code 0 when recording baseline, got %d and:\n%s%s", code, stdout, stderr)
+	}
+	//
+	return baseline, traceFile, constraints
+}
+
+// Check the failures recorded in a given baseline for a given trace file, where
+// each is given as "IR:handle".
+func checkBaseline(t *testing.T, baseline string, trace string, expected ...string) {
+	var (
+		contents struct {
+			Traces map[string][]struct {
+				IR     string `json:"ir"`
+				Handle string `json:"handle"`
+			} `json:"traces"`
+		}
+		recorded []string
+	)
+	//
+	if bytes, err := os.ReadFile(baseline); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal(bytes, &contents); err != nil {
+		t.Fatal(err)
+	}
+	//
+	for _, entry := range contents.Traces[trace] {
+		recorded = append(recorded, entry.IR+":"+entry.Handle)
+	}
+	//
+	slices.Sort(recorded)
+	slices.Sort(expected)
+	//
+	if !slices.Equal(recorded, expected) {
+		t.Errorf("expected baseline %v, got %v", expected, recorded)
+	}
+}
+
+// Overwrite the contents of a given file.
+func writeFile(t *testing.T, filename string, contents string) {
+	if err := os.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ===================================================================
+// Check (Multiple Traces)
+// ===================================================================
+
+func Test_Cmd_Batch_01(t *testing.T) {
+	// Summary is printed for a single trace file
+	trace := WriteTempFile(t, "trace.json", `{"X": [0]}`)
+	stdout, _, code := RunCorset(t, "check", trace, TestDir+"/basic_01.lisp")
+	//
+	if code != 0 {
+		t.Errorf("expected exit code 0, got exit code %d and:\n%s", code, stdout)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{"Trace | HIR | MIR | AIR |", trace + " | pass | pass | pass |"})
+}
+
+func Test_Cmd_Batch_02(t *testing.T) {
+	// Summary is printed for a directory (or glob) containing one trace file
+	dir := writeTraceFiles(t, `{"X": [1]}`)
+	//
+	for _, arg := range []string{dir, filepath.Join(dir, "*.json")} {
+		stdout, _, code := RunCorset(t, "check", arg, TestDir+"/basic_01.lisp")
+		//
+		if code != 1 {
+			t.Errorf("expected exit code 1 for %s, got exit code %d", arg, code)
+		}
+		//
+		checkPrintedColumns(t, stdout, []string{filepath.Join(dir, "trace_0.json") + " | fail | fail | fail |"})
+	}
+}
+
+func Test_Cmd_Batch_03(t *testing.T) {
+	// Each trace file is checked and summarised
+	dir := writeTraceFiles(t, `{"X": [0]}`, `{"X": [1]}`, `{"X": [0, 0]}`, `{"X": "malformed"}`)
+	stdout, _, code := RunCorset(t, "check", "--hir", dir, TestDir+"/basic_01.lisp")
+	//
+	if code != 1 {
+		t.Errorf("expected exit code 1, got exit code %d", code)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{
+		"Trace | HIR |",
+		filepath.Join(dir, "trace_0.json") + " | pass |",
+		filepath.Join(dir, "trace_1.json") + " | fail |",
+		filepath.Join(dir, "trace_2.json") + " | pass |",
+		filepath.Join(dir, "trace_3.json") + " | error |",
+	})
+}
+
+func Test_Cmd_Batch_04(t *testing.T) {
+	// Summary is not printed for machine-readable reports
+	dir := writeTraceFiles(t, `{"X": [0]}`, `{"X": [1]}`)
+	//
+	for _, arg := range []string{dir, filepath.Join(dir, "trace_1.json")} {
+		stdout, _, code := RunCorset(t, "check", "--hir", "--report-format=json", arg, TestDir+"/basic_01.lisp")
+		//
+		if records := checkJsonRecords(t, stdout); code != 1 || len(records) != 1 {
+			t.Errorf("expected one failure and exit code 1 for %s, got exit code %d and:\n%s", arg, code, stdout)
+		}
+	}
+}
+
+// Write the given trace files into a temporary directory (as trace_0.json,
+// trace_1.json, etc), returning the directory.
+func writeTraceFiles(t *testing.T, traces ...string) string {
+	dir := t.TempDir()
+	//
+	for i, trace := range traces {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("trace_%d.json", i)), []byte(trace), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	//
+	return dir
+}
+
+// ===================================================================
+// Check (Consistency)
+// ===================================================================
+
+// Constraint whose lowering introduces an inverse column, such that a raw trace
+// with an incorrect inverse is accepted at HIR and MIR, but rejected at AIR.
+const divergenceSource = "(defcolumns X Y)\n(defconstraint c () (if-zero X (vanishes! Y)))"
+
+const divergenceTrace = `{"X": [2], "Y": [1], "(inv X)": [0]}`
+
+func Test_Cmd_Consistency_01(t *testing.T) {
+	// Traces accepted at every level are consistent
+	trace := WriteTempFile(t, "trace.json", `{"X": [0], "Y": [0], "(inv X)": [0]}`)
+	stdout, stderr, code := RunCorset(t, "check", "--raw", "--warn", "--consistency", trace,
+		WriteTempFile(t, "test.lisp", divergenceSource))
+	//
+	if code != 0 || strings.Contains(stderr, "inconsistent outcome") {
+		t.Errorf("expected consistent acceptance with exit code 0, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{trace + " | pass | pass | pass | ok |"})
+}
+
+func Test_Cmd_Consistency_02(t *testing.T) {
+	// Divergence is explained and has a distinct exit code
+	trace := WriteTempFile(t, "trace.json", divergenceTrace)
+	stdout, stderr, code := RunCorset(t, "check", "--raw", "--warn", "--consistency", trace,
+		WriteTempFile(t, "test.lisp", divergenceSource))
+	//
+	if code != 3 || !strings.Contains(stderr, "inconsistent outcome: accepted at HIR, MIR, but rejected at AIR") {
+		t.Errorf("expected divergence with exit code 3, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{"[AIR] (vanish (c x1) ...) fails on row 0",
+		"lowered from [HIR] (vanish (c x1) ...) which holds on row 0", trace + " | pass | pass | fail | diverged |"})
+}
+
+func Test_Cmd_Consistency_03(t *testing.T) {
+	// Divergence is reported in machine-readable form
+	stdout, stderr, code := RunCorset(t, "check", "--raw", "--warn", "--consistency", "--report-format=json",
+		WriteTempFile(t, "trace.json", divergenceTrace), WriteTempFile(t, "test.lisp", divergenceSource))
+	//
+	if code != 3 {
+		t.Errorf("expected exit code 3, got %d and:\n%s%s", code, stdout, stderr)
+	}
+	//
+	var divergences []map[string]any
+	//
+	for _, record := range checkJsonRecords(t, stdout) {
+		if record["kind"] == "divergence" {
+			divergences = append(divergences, record)
+		}
+	}
+	//
+	if len(divergences) != 1 || divergences[0]["ir"] != "AIR" {
+		t.Errorf("expected one divergence record at AIR, got %v", divergences)
+	}
+}
+
+func Test_Cmd_Consistency_04(t *testing.T) {
+	// Divergence of any trace file is reported when checking several.
+	dir := writeTraceFiles(t, `{"X": [2], "Y": [0], "(inv X)": [0]}`, divergenceTrace)
+	stdout, stderr, code := RunCorset(t, "check", "--raw", "--warn", "--consistency", dir,
+		WriteTempFile(t, "test.lisp", divergenceSource))
+	//
+	if code != 3 || !strings.Contains(stderr, "rejected at AIR ("+filepath.Join(dir, "trace_1.json")+")") {
+		t.Errorf("expected divergence with exit code 3, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+}
+
+// ===================================================================
+// Check (Failure Limits)
+// ===================================================================
+
+func Test_Cmd_MaxFailures_01(t *testing.T) {
+	// Zero indicates no limit
+	checkFailureLimit(t, "--max-failures=0", 3)
+}
+
+func Test_Cmd_MaxFailures_02(t *testing.T) {
+	checkFailureLimit(t, "--max-failures=2", 2)
+}
+
+func Test_Cmd_MaxTotalFailures_01(t *testing.T) {
+	// Zero indicates no limit
+	checkFailureLimit(t, "--max-total-failures=0", 1)
+}
+
+func Test_Cmd_MaxTotalFailures_02(t *testing.T) {
+	// Failures are still reported when checking multiple constraints
+	stdout, _, code := RunCorset(t, "check", "--hir", "--report-format=json", "--max-total-failures=0",
+		WriteTempFile(t, "trace.json", `{"X": [1], "Y": [1]}`),
+		WriteTempFile(t, "test.lisp",
+			"(defcolumns (X :byte@loob) (Y :byte@loob))\n(defconstraint c1 () X)\n(defconstraint c2 () Y)"))
+	//
+	if records := checkJsonRecords(t, stdout); code != 1 || len(records) != 2 {
+		t.Errorf("expected two failures and exit code 1, got %d failures and exit code %d", len(records), code)
+	}
+}
+
+func Test_Cmd_MaxTotalFailures_03(t *testing.T) {
+	// Failing constraints are counted (rather than failing rows), and there is
+	// no limit by default.
+	for _, limit := range []string{"--max-total-failures=1", "--max-total-failures=2", "--max-failures=0"} {
+		stdout, _, code := RunCorset(t, "check", "--hir", "--report-format=json", "--max-failures=0", limit,
+			WriteTempFile(t, "trace.json", `{"X": [1, 1], "Y": [1, 1]}`),
+			WriteTempFile(t, "test.lisp",
+				"(defcolumns (X :byte@loob) (Y :byte@loob))\n(defconstraint c1 () X)\n(defconstraint c2 () Y)"))
+		//
+		records := checkJsonRecords(t, stdout)
+		expected := 2
+		//
+		if limit == "--max-total-failures=1" {
+			expected = 1
+		}
+		//
+		if code != 1 || len(records) != expected {
+			t.Errorf("expected %d failures and exit code 1 for %s, got %d failures and exit code %d", expected, limit,
+				len(records), code)
+		}
+		//
+		for _, r := range records {
+			if rows := r["rows"].([]any); len(rows) != 2 {
+				t.Errorf("expected 2 failing rows for %s, got %d", limit, len(rows))
+			}
+		}
+	}
+}
+
+// Check the given limit on failures results in the given number of failing rows
+// being reported for a trace with three failing rows.
+func checkFailureLimit(t *testing.T, limit string, expected int) {
+	stdout, _, code := RunCorset(t, "check", "--hir", "--report-format=json", limit,
+		WriteTempFile(t, "trace.json", `{"X": [1, 1, 1]}`), TestDir+"/basic_01.lisp")
+	//
+	records := checkJsonRecords(t, stdout)
+	//
+	if code != 1 || len(records) != 1 {
+		t.Fatalf("expected one failure and exit code 1, got %d failures and exit code %d", len(records), code)
+	} else if rows := records[0]["rows"].([]any); len(rows) != expected {
+		t.Errorf("expected %d failing rows, got %d", expected, len(rows))
+	}
+}
+
+// ===================================================================
+// Check (Source Locations)
+// ===================================================================
+
+func Test_Cmd_SourceLocation_01(t *testing.T) {
+	// Each failure is reported exactly once, alongside its source location.
+	stdout, stderr, code := RunCorset(t, "check", "--hir", WriteTempFile(t, "trace.json", `{"X": [1]}`),
+		TestDir+"/basic_01.lisp")
+	//
+	msg := `constraint "heartbeat" does not hold (row 1) (HIR)`
+	//
+	if code != 1 || strings.Count(stdout+stderr, msg) != 1 {
+		t.Errorf("expected failure reported once with exit code 1, got exit code %d and:\n%s%s", code, stdout, stderr)
+	} else if !strings.Contains(stdout, "basic_01.lisp:2:1-31 "+msg) {
+		t.Errorf("expected failure reported with source location, got:\n%s", stdout)
+	}
+}
+
+// ===================================================================
+// Check (Row Windows)
+// ===================================================================
+
+const windowSource = "(defcolumns (X :i16@loob))\n(defconstraint c () (- (shift X 2) X))"
+
+func Test_Cmd_Rows_01(t *testing.T) {
+	// Failures within the window are reported, even when they arise from rows
+	// beyond it (noting the initial padding row).
+	for _, ir := range []string{"--hir", "--air"} {
+		for _, rows := range []string{"--rows=0..3", "--rows=3..3"} {
+			stdout, _, code := RunCorset(t, "check", ir, rows, "--report-format=json",
+				WriteTempFile(t, "trace.json", `{"X": [0, 0, 0, 0, 5, 0, 0, 0, 0, 0]}`),
+				WriteTempFile(t, "test.lisp", windowSource))
+			//
+			records := checkJsonRecords(t, stdout)
+			//
+			if code != 1 || len(records) != 1 || fmt.Sprint(records[0]["rows"]) != "[3]" {
+				t.Errorf("expected failure on row 3 for %s %s, got exit code %d and:\n%s", ir, rows, code, stdout)
+			}
+		}
+	}
+}
+
+func Test_Cmd_Rows_02(t *testing.T) {
+	// Failures outside the window are not reported
+	stdout, _, code := RunCorset(t, "check", "--rows=0..2", WriteTempFile(t, "trace.json",
+		`{"X": [0, 0, 0, 0, 5, 0, 0, 0, 0, 0]}`), WriteTempFile(t, "test.lisp", windowSource))
+	//
+	if code != 0 {
+		t.Errorf("expected exit code 0, got exit code %d and:\n%s", code, stdout)
+	}
+}
+
+// ===================================================================
+// Check (Spillage)
+// ===================================================================
+
+func Test_Cmd_Spillage_01(t *testing.T) {
+	// Spillage of -1 means spillage is inferred (hence, no warnings)
+	for _, spillage := range []string{"--spillage=-1", "--spillage==-1"} {
+		stdout, stderr, code := RunCorset(t, "check", spillage, WriteTempFile(t, "trace.json", `{"X": [0, 0]}`),
+			TestDir+"/basic_01.lisp")
+		//
+		if code != 0 || stderr != "" {
+			t.Errorf("expected exit code 0 for %s, got exit code %d and:\n%s%s", spillage, code, stdout, stderr)
+		}
+	}
+}
+
+func Test_Cmd_Spillage_02(t *testing.T) {
+	// Spillage below that inferred is permitted, but generates warnings
+	stdout, stderr, code := RunCorset(t, "check", "--hir", "--spillage=0",
+		WriteTempFile(t, "trace.json", `{"X": [0, 0]}`), TestDir+"/basic_01.lisp")
+	//
+	if code != 0 || !strings.Contains(stderr, "spillage 0 for module '' is below inferred spillage 1") {
+		t.Errorf("expected spillage warning with exit code 0, got exit code %d and:\n%s%s", code, stdout, stderr)
+	}
+}
+
+func Test_Cmd_Spillage_03(t *testing.T) {
+	stdout, _, code := RunCorset(t, "check", "--spillage=m=x", WriteTempFile(t, "trace.json", `{"X": [0]}`),
+		TestDir+"/basic_01.lisp")
+	//
+	if code != 4 || !strings.Contains(stdout, "invalid --spillage: malformed spillage \"m=x\"") {
+		t.Errorf("expected malformed spillage with exit code 4, got exit code %d and:\n%s", code, stdout)
+	}
+}
+
+// ===================================================================
+// Verify Padding
+// ===================================================================
+
+func Test_Cmd_VerifyPadding_01(t *testing.T) {
+	// Assertions rejecting padding are reported (once)
+	stdout, _, code := RunCorset(t, "verify-padding", "--no-stdlib", "--hir", "--padding=0..1",
+		WriteTempFile(t, "test.lisp", "(defcolumns (A :i16))\n(defproperty lem (- A 1))"))
+	//
+	msg := "[HIR] (assert lem ...) rejects padding-only trace (padding 0, 1)"
+	//
+	if code != 1 || strings.Count(stdout, msg) != 1 {
+		t.Errorf("expected assertion failure reported once with exit code 1, got exit code %d and:\n%s", code, stdout)
+	}
+}
+
+func Test_Cmd_VerifyPadding_02(t *testing.T) {
+	// Only the requested IRs are checked
+	stdout, _, code := RunCorset(t, "verify-padding", "--mir",
+		WriteTempFile(t, "test.lisp", "(defcolumns X)\n(defconstraint c () (vanishes! X))"))
+	//
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d and:\n%s", code, stdout)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{"IR | Module | Inferred | Required | Status |", "MIR | (root) | 1 | 0 | ok |"})
+	//
+	if strings.Contains(stdout, "HIR") || strings.Contains(stdout, "AIR") {
+		t.Errorf("expected only MIR to be checked, got:\n%s", stdout)
+	}
+}
+
+// ===================================================================
+// Trace (Displays)
+// ===================================================================
+
+const displaySource = "(defcolumns (X :i16 :display :dec) (Y :i16) (Z :i16 :display :hex))"
+
+func Test_Cmd_TraceDisplay_01(t *testing.T) {
+	// Without constraints, values are shown in their original format
+	stdout, _, _ := RunCorset(t, "trace", "--print", WriteTempFile(t, "trace.json", `{"X": [255], "Y": [255]}`))
+	//
+	checkPrintedColumns(t, stdout, []string{"X | ff |", "Y | ff |"})
+}
+
+func Test_Cmd_TraceDisplay_02(t *testing.T) {
+	// With constraints, values are shown according to their declared display
+	stdout, _, _ := RunCorset(t, "trace", "--print",
+		WriteTempFile(t, "trace.json", `{"X": [255], "Y": [255], "Z": [255]}`),
+		WriteTempFile(t, "test.lisp", displaySource))
+	//
+	checkPrintedColumns(t, stdout, []string{"X | 255 |", "Y | ff |", "Z | 0xff |"})
+}
+
+func Test_Cmd_DebugDisplay_01(t *testing.T) {
+	// Displays are only shown for columns which declare them
+	stdout, _, _ := RunCorset(t, "debug", "--hir", WriteTempFile(t, "test.lisp", displaySource))
+	//
+	checkPrintedColumns(t, stdout, []string{"(column (X u16 x1 :display :dec))", "(column (Y u16 x1))",
+		"(column (Z u16 x1 :display :hex))"})
+}
+
+// Check the given lines (ignoring whitespace) are amongst those printed.
+func checkPrintedColumns(t *testing.T, output string, expected []string) {
+	lines := make(map[string]bool)
+	//
+	for _, line := range strings.Split(output, "\n") {
+		lines[strings.Join(strings.Fields(line), " ")] = true
+	}
+	//
+	for _, line := range expected {
+		if !lines[line] {
+			t.Errorf("expected \"%s\" in output:\n%s", line, output)
+		}
+	}
+}
+
+// ===================================================================
+// Trace (Profiling)
+// ===================================================================
+
+func Test_Cmd_TraceProfile_01(t *testing.T) {
+	// Columns exceeding their declared type are flagged
+	stdout, _, code := RunCorset(t, "trace", "--list", "--include=lines,maxwidth",
+		WriteTempFile(t, "trace.json", `{"X": [0, 256, 3]}`), WriteTempFile(t, "test.lisp", "(defcolumns (X :i8))"))
+	//
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d and:\n%s", code, stdout)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{"X | 3 | 9 > u8 (!) |",
+		"column X has 1 value(s) exceeding declared type u8 (e.g. 256 on row 1)"})
+}
+
+func Test_Cmd_TraceProfile_02(t *testing.T) {
+	// Without constraints, declared types are unknown and nothing is flagged
+	stdout, _, code := RunCorset(t, "trace", "--list", "--include=lines,maxwidth",
+		WriteTempFile(t, "trace.json", `{"X": [0, 256, 3]}`))
+	//
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d and:\n%s", code, stdout)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{"X | 3 | 9 |"})
+	//
+	if strings.Contains(stdout, "exceeding declared type") {
+		t.Errorf("unexpected out-of-type column reported:\n%s", stdout)
+	}
+}
+
+// ===================================================================
+// Trace (Diff)
+// ===================================================================
+
+func Test_Cmd_Diff_01(t *testing.T) {
+	// Identical traces do not differ
+	checkTraceDiff(t, `{"X": [1, 2], "Y": [3, 4]}`, `{"Y": [3, 4], "X": [1, 2]}`, 0)
+}
+
+func Test_Cmd_Diff_02(t *testing.T) {
+	// Adding one column whilst removing another is a difference
+	checkTraceDiff(t, `{"X": [1, 2], "Y": [3, 4]}`, `{"X": [1, 2], "Z": [3, 4]}`, 1,
+		"module (root): removed column Y", "module (root): added column Z")
+}
+
+func Test_Cmd_Diff_03(t *testing.T) {
+	checkTraceDiff(t, `{"X": [1, 2], "Y": [3, 4]}`, `{"X": [1, 2], "Y": [3, 5]}`, 1,
+		"module (root): column Y differs on 1 row(s)")
+}
+
+func Test_Cmd_Diff_04(t *testing.T) {
+	checkTraceDiff(t, `{"X": [1, 2]}`, `{"X": [1, 2, 3]}`, 1, "module (root): height differs (2 vs 3)",
+		"module (root): column X differs on 1 row(s)")
+}
+
+// Check the diff of two given traces has the given exit code and includes the
+// given lines.
+func checkTraceDiff(t *testing.T, before string, after string, expected int, lines ...string) {
+	stdout, stderr, code := RunCorset(t, "trace", "diff", "--ansi-escapes=false",
+		WriteTempFile(t, "before.json", before), WriteTempFile(t, "after.json", after))
+	//
+	if code != expected {
+		t.Errorf("expected exit code %d, got %d and:\n%s%s", expected, code, stdout, stderr)
+	}
+	//
+	checkPrintedColumns(t, stdout, lines)
+}
+
+// ===================================================================
+// Trace (Shrink)
+// ===================================================================
+
+func Test_Cmd_Shrink_01(t *testing.T) {
+	// Shrunk traces are rejected by the given constraint alone (i.e. no input
+	// columns are missing), whilst non-input columns are ignored.
+	constraints := WriteTempFile(t, "test.lisp", "(defcolumns X Y Z)\n(defconstraint c () (vanishes! (- X Y)))")
+	out := filepath.Join(t.TempDir(), "out.json")
+	//
+	if stdout, stderr, code := RunCorset(t, "trace", "shrink", "--schema", constraints, "--constraint", "c",
+		WriteTempFile(t, "trace.json", `{"X": [1, 2, 3], "Y": [1, 5, 3], "Z": [4, 4, 4], "W": [1]}`), out); code != 0 {
+		t.Fatalf("expected exit code 0, got %d and:\n%s%s", code, stdout, stderr)
+	}
+	//
+	stdout, stderr, code := RunCorset(t, "check", "--hir", out, constraints)
+	//
+	if code != 1 || stderr != "" || !strings.Contains(stdout, "constraint \"c\" does not hold (row 1) (HIR)") {
+		t.Errorf("expected only constraint c to fail with exit code 1, got exit code %d and:\n%s%s", code, stdout,
+			stderr)
+	}
+}
+
+// ===================================================================
+// Test (Inline)
+// ===================================================================
+
+func Test_Cmd_TestInline_01(t *testing.T) {
+	stdout, _, code := RunCorset(t, "test", "--no-stdlib", "--inline", TestDir+"/deftest_01.lisp")
+	//
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d and:\n%s", code, stdout)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{"PASS t1", "PASS t2", "2 of 2 test(s) passed"})
+}
+
+func Test_Cmd_TestInline_02(t *testing.T) {
+	// Tests whose outcome is not as expected fail
+	stdout, _, code := RunCorset(t, "test", "--inline", WriteTempFile(t, "test.lisp",
+		"(defcolumns A B)\n(defconstraint eq () (vanishes! (- A B)))\n"+
+			"(deftest t1 :accepts (A 1) (B 2))\n(deftest t2 :rejects (A 1) (B 1))\n(deftest t3 :rejects (A 1) (B 3))"))
+	//
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d and:\n%s", code, stdout)
+	}
+	//
+	checkPrintedColumns(t, stdout, []string{"FAIL t1 (rejected at HIR with padding 0, expected acceptance)",
+		"FAIL t2 (accepted at HIR with padding 0, expected rejection)", "PASS t3", "1 of 3 test(s) passed"})
+}
+
+// ===================================================================
+// Test (Random)
+// ===================================================================
+
+func Test_Cmd_TestRandom_01(t *testing.T) {
+	// Counterexamples are reported against the trace on which they were found
+	// (i.e. following one row of spillage and two rows of padding).
+	counterexample := filepath.Join(t.TempDir(), "counterexample.json")
+	stdout, stderr, code := RunCorset(t, "test", "--seed=1", "--iterations=5", "--padding=2", "--counterexample",
+		counterexample, WriteTempFile(t, "test.lisp", "(defcolumns (X :byte))\n(defproperty p (vanishes! X))"))
+	//
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d and:\n%s%s", code, stdout, stderr)
+	}
+	//
+	for _, ir := range []string{"HIR", "MIR", "AIR"} {
+		if !strings.Contains(stdout, fmt.Sprintf("assertion \"p\" does not hold (row 3) (%s)", ir)) {
+			t.Errorf("expected assertion failure on row 3 at %s, got:\n%s", ir, stdout)
+		}
+	}
+	//
+	if _, err := os.Stat(counterexample); err != nil {
+		t.Errorf("missing counterexample (%s)", err)
+	}
+}
+
+// ===================================================================
+// Test (Padding)
+// ===================================================================
+
+func Test_Cmd_TestPadding_01(t *testing.T) {
+	// Padding upto a height which is sufficient
+	stdout, _, code := RunCorset(t, "test", "--no-stdlib", "--inline", "--pad-to==4", "--pad-to=pow2",
+		TestDir+"/deftest_01.lisp")
+	//
+	if code != 0 || !strings.Contains(stdout, "2 of 2 test(s) passed") {
+		t.Errorf("expected all tests to pass with exit code 0, got exit code %d and:\n%s", code, stdout)
+	}
+}
+
+func Test_Cmd_TestPadding_02(t *testing.T) {
+	// Padding upto a height which is insufficient (i.e. t1 has two rows, plus
+	// one row of padding).
+	stdout, _, code := RunCorset(t, "test", "--no-stdlib", "--inline", "--pad-to==2", TestDir+"/deftest_01.lisp")
+	//
+	if code != 1 || !strings.Contains(stdout, "FAIL t1") || !strings.Contains(stdout, "PASS t2") {
+		t.Errorf("expected t1 to fail with exit code 1, got exit code %d and:\n%s", code, stdout)
+	}
+}
+
+func Test_Cmd_TestPadding_03(t *testing.T) {
+	stdout, _, code := RunCorset(t, "test", "--no-stdlib", "--inline", "--pad-to=m:2", TestDir+"/deftest_01.lisp")
+	//
+	if code != 2 || !strings.Contains(stdout, "invalid padding protocol \"m:2\"") {
+		t.Errorf("expected invalid padding protocol with exit code 2, got exit code %d and:\n%s", code, stdout)
+	}
+}
+
+func Test_Cmd_TestPadding_04(t *testing.T) {
+	// Ranges of padding
+	for _, padding := range []string{"0", "0..2", "2..2"} {
+		if stdout, _, code := RunCorset(t, "test", "--no-stdlib", "--inline", "--padding="+padding,
+			TestDir+"/deftest_01.lisp"); code != 0 {
+			t.Errorf("expected exit code 0 for --padding=%s, got exit code %d and:\n%s", padding, code, stdout)
+		}
+	}
+}
+
+// ===================================================================
+// Generate Traces
+// ===================================================================
+
+func Test_Cmd_GenTraces_01(t *testing.T) {
+	// Sampled traces respect both the range of heights and fixed heights
+	checkGeneratedHeights(t, []string{"--sample=20", "--rows=2..3", "--height=m=1"}, 2, 3, 1)
+}
+
+func Test_Cmd_GenTraces_02(t *testing.T) {
+	// Enumerated traces likewise
+	checkGeneratedHeights(t, []string{"--rows=2..2", "--pool=0..1", "--height=m=1"}, 2, 2, 1)
+}
+
+// Check the traces generated with the given flags have a root module whose
+// height lies in a given range, and a module m of a given height.
+func checkGeneratedHeights(t *testing.T, flags []string, lo int, hi int, height int) {
+	prefix := filepath.Join(t.TempDir(), "test.auto")
+	constraints := WriteTempFile(t, "test.lisp", "(defcolumns X)\n(module m)\n(defcolumns Y)")
+	args := append(append([]string{"gen-traces", "--out", prefix}, flags...), constraints)
+	//
+	if stdout, stderr, code := RunCorset(t, args...); code != 0 {
+		t.Fatalf("expected exit code 0, got %d and:\n%s%s", code, stdout, stderr)
+	}
+	//
+	contents, err := os.ReadFile(prefix + ".accepts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	//
+	for _, line := range lines {
+		var columns map[string][]json.Number
+		//
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.UseNumber()
+		//
+		if err := decoder.Decode(&columns); err != nil {
+			t.Fatalf("invalid trace \"%s\" (%s)", line, err)
+		} else if x, y := len(columns["X"]), len(columns["m.Y"]); x < lo || x > hi || y != height {
+			t.Errorf("unexpected heights %d and %d in trace %s", x, y, line)
+		}
+	}
+}
+
+// ===================================================================
+// Coverage
+// ===================================================================
+
+func Test_Cmd_Coverage_01(t *testing.T) {
+	// Only rows given in accepted traces are covered
+	dir := writeTraceFiles(t, `{"X": [0, 0, 0], "Y": [0, 0, 0]}`, `{"X": [0, 1], "Y": [0, 0]}`)
+	stdout, stderr, code := RunCorset(t, "coverage", "--format=json", dir, WriteTempFile(t, "test.lisp",
+		"(defcolumns X Y)\n(defconstraint c () (if-zero X (vanishes! Y) (vanishes! (- Y 1))))"))
+	//
+	var report struct {
+		Traces      uint `json:"traces"`
+		Constraints []struct {
+			Live    uint `json:"live"`
+			Vacuous uint `json:"vacuous"`
+		} `json:"constraints"`
+	}
+	//
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d and:\n%s%s", code, stdout, stderr)
+	} else if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid coverage report (%s):\n%s", err, stdout)
+	}
+	//
+	if report.Traces != 1 || len(report.Constraints) != 1 || report.Constraints[0].Live != 3 ||
+		report.Constraints[0].Vacuous != 0 {
+		t.Errorf("expected one trace with 3 live rows, got:\n%s", stdout)
+	}
+	//
+	if !strings.Contains(stderr, "trace rejected, hence ignored") {
+		t.Errorf("expected rejected trace to be reported, got:\n%s", stderr)
+	}
+}
+
+// ===================================================================
+// Test Helpers
+// ===================================================================
+
+var (
+	// Location of the go-corset binary used for testing commands.
+	corsetBinary string
+	// Error arising from building the binary (if any).
+	corsetBuildError error
+	// Ensures the binary is built only once.
+	corsetBuild sync.Once
+)
+
+// RunCorset runs the go-corset command with the given arguments, returning
+// what was written to stdout and stderr, along with its exit code.  The binary
+// is built once, on first use.
+func RunCorset(t *testing.T, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	//
+	corsetBuild.Do(func() {
+		var dir string
+		//
+		if dir, corsetBuildError = os.MkdirTemp("", "go-corset"); corsetBuildError == nil {
+			corsetBinary = filepath.Join(dir, "go-corset")
+			corsetBuildError = exec.Command("go", "build", "-o", corsetBinary, "../../cmd/go-corset").Run()
+		}
+	})
+	//
+	if corsetBuildError != nil {
+		t.Fatalf("cannot build go-corset (%s)", corsetBuildError)
+	}
+	//
+	cmd := exec.Command(corsetBinary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	//
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		//
+		if !errors.As(err, &exitErr) {
+			t.Fatal(err)
+		}
+		//
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	}
+	//
+	return stdout.String(), stderr.String(), 0
+}
+
+// WriteTempFile writes the given contents to a file with the given name in a
+// temporary directory (which is removed when the test completes), returning
+// the path of the file.
+func WriteTempFile(t *testing.T, name string, contents string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	//
+	if err := os.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	//
+	return filename
+}
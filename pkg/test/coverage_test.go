@@ -0,0 +1,93 @@
+package test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+)
+
+// Constraint with nested conditionals, where the inner conditional has no false
+// branch (hence rows on which it is taken are vacuous).
+const coverageSource = `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns (X :@loob) (Y :@loob) Z)
+(defconstraint c () (if X (if Y (vanishes! Z)) (vanishes! (- Z 1))))`
+
+func Test_Coverage_01(t *testing.T) {
+	c := coverageConstraint(t, CompileSchema(t, coverageSource))
+	coverage := hir.NewCoverage(c)
+	// Conditionals are identified in pre-order
+	if len(coverage.Conditionals) != 2 || coverage.Conditionals[0] != c.Constraint.Expr ||
+		coverage.Conditionals[1] != coverage.Conditionals[0].TrueBranch {
+		t.Fatalf("unexpected conditionals %v", coverage.Conditionals)
+	}
+	// Nothing covered initially
+	checkCoverage(t, coverage, 0, 0, []uint{0, 0}, []uint{0, 0})
+}
+
+func Test_Coverage_02(t *testing.T) {
+	schema := CompileSchema(t, coverageSource)
+	c := coverageConstraint(t, schema)
+	coverage := hir.NewCoverage(c)
+	// Row 0 takes both true branches, row 1 takes the missing false branch of
+	// the inner conditional and row 2 takes the outer false branch.  The
+	// initial padding row is excluded.
+	coverage.Record(c, BuildTrace(t, schema, `{"X": [0, 0, 1], "Y": [0, 1, 0], "Z": [0, 5, 1]}`), 1)
+	//
+	checkCoverage(t, coverage, 2, 1, []uint{2, 1}, []uint{1, 0})
+	// Coverage accumulates over traces
+	coverage.Record(c, BuildTrace(t, schema, `{"X": [1], "Y": [0], "Z": [1]}`), 1)
+	//
+	checkCoverage(t, coverage, 3, 1, []uint{2, 1}, []uint{2, 0})
+}
+
+func Test_Coverage_03(t *testing.T) {
+	schema := CompileSchema(t, coverageSource)
+	c := coverageConstraint(t, schema)
+	// Build trace with two padding rows, following the initial padding row
+	trace, errs := sc.NewTraceBuilder(schema).Padding(2).Parallel(false).Build(ParseColumns(t,
+		`{"X": [1, 1], "Y": [0, 0], "Z": [1, 1]}`))
+	//
+	if trace == nil || len(errs) > 0 {
+		t.Fatalf("error building trace: %v", errs)
+	}
+	// Padding rows are covered, unless excluded
+	withPadding, withoutPadding := hir.NewCoverage(c), hir.NewCoverage(c)
+	withPadding.Record(c, trace, 0)
+	withoutPadding.Record(c, trace, 3)
+	//
+	checkCoverage(t, withPadding, 5, 0, []uint{3, 3}, []uint{2, 0})
+	checkCoverage(t, withoutPadding, 2, 0, []uint{0, 0}, []uint{2, 0})
+}
+
+// Determine the (only) vanishing constraint of a given schema.
+func coverageConstraint(t *testing.T, schema *hir.Schema) hir.VanishingConstraint {
+	var c hir.VanishingConstraint
+	//
+	for iter := schema.Constraints(); iter.HasNext(); {
+		if ith, ok := iter.Next().(hir.VanishingConstraint); ok {
+			return ith
+		}
+	}
+	//
+	t.Fatalf("missing vanishing constraint")
+	//
+	return c
+}
+
+// Check the number of live and vacuous rows recorded, along with the number of
+// rows on which each branch of each conditional was taken.
+func checkCoverage(t *testing.T, coverage *hir.Coverage, live uint, vacuous uint, trueBranch []uint,
+	falseBranch []uint) {
+	if coverage.Live != live || coverage.Vacuous != vacuous {
+		t.Errorf("expected %d live and %d vacuous rows, got %d and %d", live, vacuous, coverage.Live,
+			coverage.Vacuous)
+	}
+	//
+	if !slices.Equal(coverage.TrueBranch, trueBranch) || !slices.Equal(coverage.FalseBranch, falseBranch) {
+		t.Errorf("expected branches %v / %v, got %v / %v", trueBranch, falseBranch, coverage.TrueBranch,
+			coverage.FalseBranch)
+	}
+}
@@ -3,9 +3,19 @@ package test
 import (
 	"testing"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/air"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/trace"
 	"github.com/consensys/go-corset/pkg/util"
 )
 
+func Test_Enumerator_0_2(t *testing.T) {
+	// The empty array is enumerated exactly once
+	enumerator := util.EnumerateElements[uint](0, []uint{0, 1})
+	checkEnumerator(t, enumerator, [][]uint{{}}, arrayEquals)
+}
+
 func Test_Enumerator_1_1(t *testing.T) {
 	enumerator := util.EnumerateElements[uint](1, []uint{0})
 	checkEnumerator(t, enumerator, [][]uint{{0}}, arrayEquals)
@@ -47,6 +57,254 @@ func Test_Enumerator_3_2(t *testing.T) {
 		{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 1, 1}}, arrayEquals)
 }
 
+// ===================================================================
+// Concatenation
+// ===================================================================
+
+func Test_ConcatEnumerator_01(t *testing.T) {
+	enumerator := util.NewConcatEnumerator([]util.Enumerator[[]uint]{})
+	checkEnumerator(t, enumerator, [][]uint{}, arrayEquals)
+}
+
+func Test_ConcatEnumerator_02(t *testing.T) {
+	enumerator := util.NewConcatEnumerator([]util.Enumerator[[]uint]{
+		util.EnumerateElements[uint](0, []uint{0}),
+		util.EnumerateElements[uint](1, []uint{0, 1}),
+		util.EnumerateElements[uint](2, []uint{2}),
+	})
+	checkEnumerator(t, enumerator, [][]uint{{}, {0}, {1}, {2, 2}}, arrayEquals)
+}
+
+func Test_ConcatEnumerator_03(t *testing.T) {
+	// Exhausted enumerators are skipped
+	exhausted := util.EnumerateElements[uint](1, []uint{3})
+	exhausted.Next()
+	//
+	enumerator := util.NewConcatEnumerator([]util.Enumerator[[]uint]{
+		exhausted,
+		util.EnumerateElements[uint](1, []uint{0, 1}),
+		exhausted,
+	})
+	checkEnumerator(t, enumerator, [][]uint{{0}, {1}}, arrayEquals)
+}
+
+// ===================================================================
+// Columns
+// ===================================================================
+
+func Test_ColumnEnumerator_01(t *testing.T) {
+	// Modules with no rows are enumerated exactly once
+	enumerator := sc.NewColumnEnumerator(enumeratorSchema(), []uint{0, 0}, toFrElements(0, 1))
+	checkEnumerator(t, enumerator, [][]trace.RawColumn{enumeratorColumns(nil, nil)}, rawColumnsEqual)
+}
+
+func Test_ColumnEnumerator_02(t *testing.T) {
+	// Column Y has a length multiplier of 2
+	enumerator := sc.NewColumnEnumerator(enumeratorSchema(), []uint{1, 1}, toFrElements(0, 1))
+	checkEnumerator(t, enumerator, [][]trace.RawColumn{
+		enumeratorColumns([]uint64{0}, []uint64{0, 0}),
+		enumeratorColumns([]uint64{1}, []uint64{0, 0}),
+		enumeratorColumns([]uint64{0}, []uint64{1, 0}),
+		enumeratorColumns([]uint64{1}, []uint64{1, 0}),
+		enumeratorColumns([]uint64{0}, []uint64{0, 1}),
+		enumeratorColumns([]uint64{1}, []uint64{0, 1}),
+		enumeratorColumns([]uint64{0}, []uint64{1, 1}),
+		enumeratorColumns([]uint64{1}, []uint64{1, 1}),
+	}, rawColumnsEqual)
+}
+
+func Test_ColumnEnumerator_03(t *testing.T) {
+	// Each module has its own height
+	enumerator := sc.NewColumnEnumerator(enumeratorSchema(), []uint{2, 0}, toFrElements(0, 1))
+	checkEnumerator(t, enumerator, [][]trace.RawColumn{
+		enumeratorColumns([]uint64{0, 0}, nil),
+		enumeratorColumns([]uint64{1, 0}, nil),
+		enumeratorColumns([]uint64{0, 1}, nil),
+		enumeratorColumns([]uint64{1, 1}, nil),
+	}, rawColumnsEqual)
+}
+
+// Construct a schema with a column X in the root module, and a column Y with a
+// length multiplier of 2 in module m.
+func enumeratorSchema() sc.Schema {
+	schema := air.EmptySchema[air.Expr]()
+	root := trace.NewContext(schema.AddModule(""), 1)
+	m := trace.NewContext(schema.AddModule("m"), 2)
+	//
+	schema.AddColumn(root, "X", sc.NewUintType(8), sc.DISPLAY_DEFAULT, fr.NewElement(0))
+	schema.AddColumn(m, "Y", sc.NewUintType(8), sc.DISPLAY_DEFAULT, fr.NewElement(0))
+	//
+	return schema
+}
+
+// Construct the columns of the schema used for testing column enumeration.
+func enumeratorColumns(x []uint64, y []uint64) []trace.RawColumn {
+	return []trace.RawColumn{{Module: "", Name: "X", Data: toFrArray(x)}, {Module: "m", Name: "Y", Data: toFrArray(y)}}
+}
+
+func toFrElements(values ...uint64) []fr.Element {
+	pool := make([]fr.Element, len(values))
+	//
+	for i, v := range values {
+		pool[i] = fr.NewElement(v)
+	}
+	//
+	return pool
+}
+
+func toFrArray(values []uint64) util.FrArray {
+	arr := util.NewFrArray(uint(len(values)), 256)
+	//
+	for i, v := range values {
+		arr.Set(uint(i), fr.NewElement(v))
+	}
+	//
+	return arr
+}
+
+func rawColumnsEqual(lhs []trace.RawColumn, rhs []trace.RawColumn) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+	//
+	for i := range lhs {
+		if lhs[i].Module != rhs[i].Module || lhs[i].Name != rhs[i].Name || lhs[i].Data.Len() != rhs[i].Data.Len() {
+			return false
+		}
+		//
+		for j := uint(0); j < lhs[i].Data.Len(); j++ {
+			if l, r := lhs[i].Data.Get(j), rhs[i].Data.Get(j); l.Cmp(&r) != 0 {
+				return false
+			}
+		}
+	}
+	//
+	return true
+}
+
+// ===================================================================
+// Trace Generator
+// ===================================================================
+
+func Test_TraceGenerator_01(t *testing.T) {
+	// Generation is deterministic for a given seed
+	lhs := generateTraces(generatorSchema(), 1, 10, 4)
+	rhs := generateTraces(generatorSchema(), 1, 10, 4)
+	//
+	if len(lhs) != 10 || len(rhs) != 10 {
+		t.Fatalf("expected 10 traces, got %d and %d", len(lhs), len(rhs))
+	}
+	//
+	for i := range lhs {
+		if !rawColumnsEqual(lhs[i], rhs[i]) {
+			t.Errorf("trace %d differs for same seed", i)
+		}
+	}
+}
+
+func Test_TraceGenerator_02(t *testing.T) {
+	// Different seeds generate different traces
+	lhs := generateTraces(generatorSchema(), 1, 10, 4)
+	rhs := generateTraces(generatorSchema(), 2, 10, 4)
+	//
+	for i := range lhs {
+		if !rawColumnsEqual(lhs[i], rhs[i]) {
+			return
+		}
+	}
+	//
+	t.Errorf("expected traces to differ for different seeds")
+}
+
+func Test_TraceGenerator_03(t *testing.T) {
+	// Module heights are within bounds, length multipliers are respected and
+	// values fit within their declared types.
+	for i, cols := range generateTraces(generatorSchema(), 3, 50, 4) {
+		x, y := cols[0].Data, cols[1].Data
+		//
+		if x.Len() < 1 || x.Len() > 4 || y.Len() < 2 || y.Len() > 8 || y.Len()%2 != 0 {
+			t.Errorf("trace %d has unexpected heights %d and %d", i, x.Len(), y.Len())
+		}
+		//
+		checkGeneratedValues(t, x, 3)
+		checkGeneratedValues(t, y, 8)
+	}
+}
+
+func Test_TraceGenerator_04(t *testing.T) {
+	// Maximum height of zero is treated as one
+	for _, cols := range generateTraces(generatorSchema(), 4, 5, 0) {
+		if cols[0].Data.Len() != 1 || cols[1].Data.Len() != 2 {
+			t.Errorf("expected heights 1 and 2, got %d and %d", cols[0].Data.Len(), cols[1].Data.Len())
+		}
+	}
+}
+
+func Test_TraceGenerator_05(t *testing.T) {
+	// Module heights are chosen from the given range
+	generator := sc.NewTraceGenerator(generatorSchema(), 5, 50, 0).Heights(util.NewPair[uint, uint](2, 3))
+	//
+	for i := 0; generator.HasNext(); i++ {
+		cols := generator.Next()
+		//
+		if x, y := cols[0].Data.Len(), cols[1].Data.Len(); x < 2 || x > 3 || y < 4 || y > 6 {
+			t.Errorf("trace %d has unexpected heights %d and %d", i, x, y)
+		}
+	}
+}
+
+func Test_TraceGenerator_06(t *testing.T) {
+	// Fixed heights take precedence over the given range
+	fixed := func(module string) (uint, bool) {
+		return 0, module == "m"
+	}
+	generator := sc.NewTraceGenerator(generatorSchema(), 6, 50, 4).FixedHeights(fixed)
+	//
+	for i := 0; generator.HasNext(); i++ {
+		cols := generator.Next()
+		//
+		if x, y := cols[0].Data.Len(), cols[1].Data.Len(); x < 1 || x > 4 || y != 0 {
+			t.Errorf("trace %d has unexpected heights %d and %d", i, x, y)
+		}
+	}
+}
+
+// Construct a schema with a 3-bit column X in the root module, and an 8-bit
+// column Y with a length multiplier of 2 in module m.
+func generatorSchema() sc.Schema {
+	schema := air.EmptySchema[air.Expr]()
+	root := trace.NewContext(schema.AddModule(""), 1)
+	m := trace.NewContext(schema.AddModule("m"), 2)
+	//
+	schema.AddColumn(root, "X", sc.NewUintType(3), sc.DISPLAY_DEFAULT, fr.NewElement(0))
+	schema.AddColumn(m, "Y", sc.NewUintType(8), sc.DISPLAY_DEFAULT, fr.NewElement(0))
+	//
+	return schema
+}
+
+// Generate all traces for a given schema.
+func generateTraces(schema sc.Schema, seed uint64, iterations uint, maxHeight uint) [][]trace.RawColumn {
+	var (
+		generator = sc.NewTraceGenerator(schema, seed, iterations, maxHeight)
+		traces    [][]trace.RawColumn
+	)
+	//
+	for generator.HasNext() {
+		traces = append(traces, generator.Next())
+	}
+	//
+	return traces
+}
+
+// Check every value of a generated column fits within a given number of bits.
+func checkGeneratedValues(t *testing.T, data util.FrArray, nbits uint) {
+	for i := uint(0); i < data.Len(); i++ {
+		if ith := data.Get(i); !ith.IsUint64() || ith.Uint64() >= 1<<nbits {
+			t.Errorf("value %s at row %d exceeds %d bits", ith.String(), i, nbits)
+		}
+	}
+}
+
 // ===================================================================
 // Test Helpers
 // ===================================================================
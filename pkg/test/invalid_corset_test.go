@@ -211,6 +211,30 @@ func Test_Invalid_Property_02(t *testing.T) {
 	CheckInvalid(t, "property_invalid_02")
 }
 
+// ===================================================================
+// DefTest Tests
+// ===================================================================
+
+func Test_Invalid_DefTest_01(t *testing.T) {
+	CheckInvalid(t, "deftest_invalid_01")
+}
+
+func Test_Invalid_DefTest_02(t *testing.T) {
+	CheckInvalid(t, "deftest_invalid_02")
+}
+
+func Test_Invalid_DefTest_03(t *testing.T) {
+	CheckInvalid(t, "deftest_invalid_03")
+}
+
+func Test_Invalid_DefTest_04(t *testing.T) {
+	CheckInvalid(t, "deftest_invalid_04")
+}
+
+func Test_Invalid_DefTest_05(t *testing.T) {
+	CheckInvalid(t, "deftest_invalid_05")
+}
+
 // ===================================================================
 // Shift Tests
 // ===================================================================
@@ -643,6 +667,10 @@ func Test_Invalid_Perspective_08(t *testing.T) {
 	CheckInvalid(t, "perspective_invalid_08")
 }
 
+func Test_Invalid_Perspective_09(t *testing.T) {
+	CheckInvalid(t, "perspective_invalid_09")
+}
+
 // ===================================================================
 // Perspectives
 // ===================================================================
@@ -0,0 +1,203 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/sexp"
+)
+
+// Constraints with computed columns (i.e. interleavings, permutations and
+// inverses) and registers allocated from perspectives.
+const loweringSource = `
+(defcolumns (X :i16) (Y :i16) (P :binary@prove) (Q :binary@prove))
+(defperspective p1 P ((B :i16)))
+(defperspective p2 Q ((C :i16)))
+(definterleaved Z (X Y))
+(defpermutation (S T) ((+ X) (+ Y)))
+(defconstraint c1 (:perspective p1) (vanishes! (- X B)))
+(defconstraint c2 (:perspective p2) (if-zero C (vanishes! (- Y 1))))
+(deflookup l1 (X) (Z))
+(defconstraint c3 () (vanishes! (- S T)))`
+
+// ===================================================================
+// Column Indices
+// ===================================================================
+
+func Test_Lowering_01(t *testing.T) {
+	// Lowering to MIR preserves the indices of all HIR columns
+	hirSchema := compileLoweringSchema(t)
+	checkColumnIndices(t, hirSchema, hirSchema.LowerToMir())
+}
+
+func Test_Lowering_02(t *testing.T) {
+	// Lowering to AIR preserves the indices of all MIR columns
+	mirSchema := compileLoweringSchema(t).LowerToMir()
+	airSchema := mirSchema.LowerToAir()
+	// Sanity check lowering introduced columns (e.g. inverses)
+	if airSchema.Columns().Count() <= mirSchema.Columns().Count() {
+		t.Fatalf("expected lowering to introduce columns")
+	}
+	//
+	checkColumnIndices(t, mirSchema, airSchema)
+}
+
+func Test_Lowering_03(t *testing.T) {
+	// Register sources (which are indexed by HIR column) identify the same
+	// register at every IR.
+	hirSchema := compileLoweringSchema(t)
+	mirSchema := hirSchema.LowerToMir()
+	airSchema := mirSchema.LowerToAir()
+	merged := 0
+	//
+	for i := uint(0); i < hirSchema.Columns().Count(); i++ {
+		sources := hirSchema.RegisterSources(i)
+		// Registers are named after the source-level columns allocated to them
+		for _, name := range []string{mirSchema.Columns().Nth(i).Name, airSchema.Columns().Nth(i).Name} {
+			for _, source := range sources {
+				if !strings.Contains(name, source.Name) {
+					t.Errorf("register %d (%s) does not hold source column %s", i, name, source.QualifiedName())
+				}
+			}
+		}
+		//
+		if len(sources) > 1 {
+			merged++
+		}
+	}
+	// Sanity check perspective columns were actually merged
+	if merged == 0 {
+		t.Errorf("expected perspective columns to be merged into registers")
+	}
+}
+
+// ===================================================================
+// Constraint Indices
+// ===================================================================
+
+func Test_Lowering_04(t *testing.T) {
+	hirSchema := compileLoweringSchema(t)
+	mirSchema := hirSchema.LowerToMir()
+	checkConstraintIndices(t, hirSchema, mirSchema, mirSchema)
+}
+
+func Test_Lowering_05(t *testing.T) {
+	mirSchema := compileLoweringSchema(t).LowerToMir()
+	airSchema := mirSchema.LowerToAir()
+	checkConstraintIndices(t, mirSchema, airSchema, airSchema)
+}
+
+// ===================================================================
+// Provenance Chains
+// ===================================================================
+
+func Test_Lowering_06(t *testing.T) {
+	hirSchema := compileLoweringSchema(t)
+	airSchema := hirSchema.LowerToMir().LowerToAir()
+	checkProvenanceChains(t, hirSchema, airSchema, airSchema)
+}
+
+// Check that every constraint of an AIR schema arising from a constraint can be
+// traced back to the HIR constraint (and its source location) from which it
+// ultimately arose.
+func checkProvenanceChains(t *testing.T, hirSchema sc.Schema, airSchema sc.Schema, provenances sc.Attributed) {
+	located := 0
+	//
+	for i := uint(0); i < airSchema.Constraints().Count(); i++ {
+		provenance := provenances.ConstraintProvenance(i)
+		//
+		if _, ok := provenance.OriginConstraint(); !ok {
+			continue
+		} else if provenance.Source == nil {
+			t.Errorf("constraint %d (%s) not traced beyond MIR", i, provenance.Origin)
+			continue
+		}
+		//
+		root := provenance.Root()
+		index, ok := root.OriginConstraint()
+		//
+		if !ok || root.IR != "HIR" {
+			t.Errorf("constraint %d not traced to HIR constraint (%s)", i, provenance.String())
+			continue
+		}
+		//
+		ith := hirSchema.Constraints().Nth(index)
+		// Compiler-generated constraints (e.g. for @prove) have no location
+		if origin := sc.OriginOf(ith, hirSchema); origin != root.Origin {
+			t.Errorf("constraint %d traced to constraint %d %s, but this is %s", i, index, root.Origin, origin)
+		} else if root.Location != sc.LocationOf(ith) {
+			t.Errorf("constraint %d traced to constraint %d without its source location", i, index)
+		} else if root.Location != nil {
+			located++
+		}
+	}
+	// Sanity check some constraints were actually traced to the source
+	if located == 0 {
+		t.Errorf("expected constraints to be traced back to source locations")
+	}
+}
+
+// Check that every column of a higher-level schema has the same index in the
+// lower-level schema to which it is lowered.
+func checkColumnIndices(t *testing.T, higher sc.Schema, lower sc.Schema) {
+	n := higher.Columns().Count()
+	//
+	if lower.Columns().Count() < n {
+		t.Fatalf("expected at least %d columns after lowering, got %d", n, lower.Columns().Count())
+	}
+	//
+	for i := uint(0); i < n; i++ {
+		hcol := higher.Columns().Nth(i)
+		lcol := lower.Columns().Nth(i)
+		//
+		if hcol.QualifiedName(higher) != lcol.QualifiedName(lower) || hcol.Context != lcol.Context {
+			t.Errorf("column %d (%s) lowered as %s", i, hcol.QualifiedName(higher), lcol.QualifiedName(lower))
+		}
+	}
+}
+
+// Check that every constraint of a lower-level schema is attributed to the
+// constraint of the higher-level schema from which it was lowered, such that
+// the constraint index recorded in its provenance identifies that constraint.
+func checkConstraintIndices(t *testing.T, higher sc.Schema, lower sc.Schema, provenances sc.Attributed) {
+	attributed := make(map[uint]bool)
+	//
+	for i := uint(0); i < lower.Constraints().Count(); i++ {
+		provenance := provenances.ConstraintProvenance(i)
+		// Constraints may also arise from lowering assignments
+		if index, ok := provenance.OriginConstraint(); ok {
+			origin := sc.OriginOf(higher.Constraints().Nth(index), higher)
+			//
+			if origin != provenance.Origin {
+				t.Errorf("constraint %d attributed to constraint %d %s, but this is %s", i, index,
+					provenance.Origin, origin)
+			}
+			//
+			attributed[index] = true
+		} else if provenance.IsEmpty() {
+			t.Errorf("constraint %d has no provenance", i)
+		}
+	}
+	// Every higher-level constraint should be lowered to something
+	for i := uint(0); i < higher.Constraints().Count(); i++ {
+		if !attributed[i] {
+			t.Errorf("no constraint attributed to constraint %d (%s)", i,
+				sc.OriginOf(higher.Constraints().Nth(i), higher))
+		}
+	}
+}
+
+// Compile the constraints used for testing lowering (with the standard library).
+func compileLoweringSchema(t *testing.T) *hir.Schema {
+	srcfile := sexp.NewSourceFile("test.lisp", []byte(loweringSource))
+	schema, errs := corset.CompileSourceFile(true, false, srcfile)
+	//
+	if len(errs) > 0 {
+		t.Fatalf("error compiling constraints: %v", errs)
+	}
+	//
+	return schema
+}
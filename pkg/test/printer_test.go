@@ -0,0 +1,69 @@
+package test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/consensys/go-corset/pkg/trace"
+)
+
+func Test_Printer_01(t *testing.T) {
+	checkPrintedRows(t, 2, 2, 0, []string{"2"})
+}
+
+func Test_Printer_02(t *testing.T) {
+	// All rows upto the end row are printed
+	checkPrintedRows(t, 2, 5, 0, []string{"2", "3", "4", "5"})
+}
+
+func Test_Printer_03(t *testing.T) {
+	checkPrintedRows(t, 2, 5, 1, []string{"1", "2", "3", "4", "5", "6"})
+}
+
+func Test_Printer_04(t *testing.T) {
+	// Rows beyond the end of the trace are not printed
+	checkPrintedRows(t, 8, 12, 2, []string{"6", "7", "8", "9", "10"})
+}
+
+// Check that printing a trace of eleven rows (i.e. including the initial
+// padding row) with the given start row, end row and padding prints exactly the
+// given rows.
+func checkPrintedRows(t *testing.T, start uint, end uint, padding uint, expected []string) {
+	schema := CompileSchema(t, "(defcolumns X)")
+	tr := BuildTrace(t, schema, `{"X": [0, 1, 2, 3, 4, 5, 6, 7, 8, 9]}`)
+	printer := trace.NewPrinter().Start(start).End(end).Padding(padding).AnsiEscapes(false)
+	// Extract the row indices from the first line
+	output := captureStdout(t, func() { printer.Print(tr) })
+	rows := strings.Fields(strings.ReplaceAll(strings.Split(output, "\n")[0], "|", " "))
+	//
+	if strings.Join(rows, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected rows %v to be printed, got %v", expected, rows)
+	}
+}
+
+// Capture everything written to stdout by a given function.
+func captureStdout(t *testing.T, fn func()) string {
+	reader, writer, err := os.Pipe()
+	//
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	stdout := os.Stdout
+	os.Stdout = writer
+	//
+	fn()
+	//
+	os.Stdout = stdout
+	writer.Close()
+	//
+	bytes, err := io.ReadAll(reader)
+	//
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	return string(bytes)
+}
@@ -0,0 +1,984 @@
+package test
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/go-corset/pkg/air"
+	"github.com/consensys/go-corset/pkg/corset"
+	"github.com/consensys/go-corset/pkg/hir"
+	sc "github.com/consensys/go-corset/pkg/schema"
+	"github.com/consensys/go-corset/pkg/schema/constraint"
+	"github.com/consensys/go-corset/pkg/sexp"
+	"github.com/consensys/go-corset/pkg/trace"
+	"github.com/consensys/go-corset/pkg/trace/json"
+	"github.com/consensys/go-corset/pkg/util"
+)
+
+// ===================================================================
+// Failure Limits
+// ===================================================================
+
+func Test_AcceptsUpto_01(t *testing.T) {
+	// One failing row per constraint by default
+	checkAcceptsUpto(t, 1, 0, []uint{1})
+}
+
+func Test_AcceptsUpto_02(t *testing.T) {
+	checkAcceptsUpto(t, 2, 0, []uint{1, 2})
+}
+
+func Test_AcceptsUpto_03(t *testing.T) {
+	// Zero indicates no limit
+	checkAcceptsUpto(t, 0, 0, []uint{1, 2, 4})
+}
+
+func Test_AcceptsUpto_04(t *testing.T) {
+	checkAcceptsUpto(t, 0, 1, []uint{1, 2, 4})
+}
+
+func checkAcceptsUpto(t *testing.T, perConstraint uint, overall uint, expected []uint) {
+	schema := CompileSchema(t, "(defcolumns (X :byte@loob))\n(defconstraint heartbeat () X)")
+	tr := BuildTrace(t, schema, `{"X": [1, 1, 0, 1]}`)
+	failures := sc.AcceptsUpto(100, perConstraint, overall, schema, tr)
+	//
+	if len(failures) != 1 {
+		t.Fatalf("expected one failure, got %d", len(failures))
+	} else if f, ok := failures[0].(*constraint.VanishingFailure); !ok {
+		t.Fatalf("unexpected failure %s", failures[0].Message())
+	} else if !equalRows(f.Rows, expected) {
+		t.Errorf("expected failing rows %v, got %v", expected, f.Rows)
+	}
+}
+
+// ===================================================================
+// Lookups
+// ===================================================================
+
+func Test_LookupFailure_01(t *testing.T) {
+	// Checking stops at the limit, but all missing tuples are still reported
+	f := checkLookupFailure(t, 1, []uint{2})
+	checkMissingTuples(t, f.Missing(f.trace), []string{"2*2@2,3", "3*1@4"})
+}
+
+func Test_LookupFailure_02(t *testing.T) {
+	f := checkLookupFailure(t, 0, []uint{2, 3, 4})
+	checkMissingTuples(t, f.Missing(f.trace), []string{"2*2@2,3", "3*1@4"})
+}
+
+func Test_LookupFailure_03(t *testing.T) {
+	checkNearestTargets(t, 0, []uint{})
+}
+
+func Test_LookupFailure_04(t *testing.T) {
+	checkNearestTargets(t, 1, []uint{1})
+}
+
+func Test_LookupFailure_05(t *testing.T) {
+	checkNearestTargets(t, 3, []uint{1, 2, 0})
+}
+
+func Test_LookupFailure_06(t *testing.T) {
+	checkNearestTargets(t, 10, []uint{1, 2, 0, 3})
+}
+
+// A lookup failure along with the trace on which it arose.
+type lookupFailure struct {
+	*constraint.LookupFailure
+	trace trace.Trace
+}
+
+// Check a failing lookup reports the expected rows for a given limit.
+func checkLookupFailure(t *testing.T, limit uint, expected []uint) lookupFailure {
+	schema := CompileSchema(t, "(defcolumns X Y)\n(deflookup test (Y) (X))")
+	tr := BuildTrace(t, schema, `{"X": [1, 2, 2, 3], "Y": [1, 5, 6, 7]}`)
+	failures := sc.AcceptsUpto(100, limit, 0, schema, tr)
+	//
+	if len(failures) != 1 {
+		t.Fatalf("expected one failure, got %d", len(failures))
+	}
+	//
+	f, ok := failures[0].(*constraint.LookupFailure)
+	//
+	if !ok || !equalRows(f.Rows, expected) {
+		t.Fatalf("expected failure on rows %v, got %s", expected, failures[0].Message())
+	}
+	//
+	return lookupFailure{f, tr}
+}
+
+// Check the missing tuples (each written as "value*multiplicity@rows") match
+// those expected.
+func checkMissingTuples(t *testing.T, tuples []constraint.Tuple, expected []string) {
+	actual := make([]string, len(tuples))
+	//
+	for i, tuple := range tuples {
+		rows := make([]string, len(tuple.Rows))
+		//
+		for j, row := range tuple.Rows {
+			rows[j] = fmt.Sprintf("%d", row)
+		}
+		//
+		actual[i] = fmt.Sprintf("%s*%d@%s", tuple.Values[0].String(), tuple.Multiplicity, strings.Join(rows, ","))
+	}
+	//
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected missing tuples %v, got %v", expected, actual)
+	}
+}
+
+// Check the n target rows closest to a missing tuple are those expected.
+func checkNearestTargets(t *testing.T, n uint, expected []uint) {
+	schema := CompileSchema(t, "(defcolumns A B X Y)\n(deflookup test (A B) (X Y))")
+	tr := BuildTrace(t, schema, `{"A": [1, 1, 2], "B": [1, 2, 2], "X": [1, 1, 1], "Y": [3, 3, 3]}`)
+	failures := sc.AcceptsUpto(100, 0, 0, schema, tr)
+	//
+	if len(failures) != 1 {
+		t.Fatalf("expected one failure, got %d", len(failures))
+	}
+	//
+	f := failures[0].(*constraint.LookupFailure)
+	missing := f.Missing(tr)
+	//
+	if len(missing) != 1 {
+		t.Fatalf("expected one missing tuple, got %d", len(missing))
+	}
+	//
+	if rows := f.NearestTargets(missing[0].Values, n, tr); !equalRows(rows, expected) {
+		t.Errorf("expected nearest target rows %v, got %v", expected, rows)
+	}
+}
+
+// ===================================================================
+// Permutations
+// ===================================================================
+
+func Test_PermutationFailure_01(t *testing.T) {
+	_, f := checkPermutation(t, []uint{1}, []uint{0}, `{"X": [1, 1, 2], "Y": [1, 2, 2]}`)
+	//
+	if f == nil {
+		t.Fatal("expected permutation failure")
+	}
+	//
+	checkMissingTuples(t, f.MissingFromTarget, []string{"1*1@0,1"})
+	checkMissingTuples(t, f.MissingFromSource, []string{"2*1@1,2"})
+	//
+	if f.Handle != "(Y)=(X)" {
+		t.Errorf("expected failure handle (Y)=(X), got %s", f.Handle)
+	}
+}
+
+func Test_PermutationFailure_02(t *testing.T) {
+	if _, f := checkPermutation(t, []uint{1}, []uint{0}, `{"X": [1, 1, 2], "Y": [2, 1, 1]}`); f != nil {
+		t.Errorf("unexpected failure %s", f.Message())
+	}
+}
+
+func Test_PermutationFailure_03(t *testing.T) {
+	// Permutations over no columns always hold
+	if _, f := checkPermutation(t, []uint{}, []uint{}, `{"X": [1], "Y": [2]}`); f != nil {
+		t.Errorf("unexpected failure %s", f.Message())
+	}
+}
+
+func Test_PermutationModules_01(t *testing.T) {
+	// Permutations are attributed to the module of their target columns
+	schema, _ := checkPermutation(t, []uint{1}, []uint{0}, `{"X": [1], "Y": [1]}`)
+	//
+	if modules := constraint.NewPermutationConstraint("p", []uint{1}, []uint{0}).Modules(schema); !slices.Equal(
+		modules, []uint{0}) {
+		t.Errorf("expected modules [0], got %v", modules)
+	}
+	// Permutations over no columns constrain no modules
+	if modules := constraint.NewPermutationConstraint("p", nil, nil).Modules(schema); len(modules) != 0 {
+		t.Errorf("expected no modules, got %v", modules)
+	}
+}
+
+func Test_PermutationHandle_01(t *testing.T) {
+	// Permutations are named after their (qualified) columns, and can be
+	// selected by name.
+	schema := CompileSchema(t, "(module m)\n(defcolumns (X :i16) (Y :i16))\n(defpermutation (A B) ((+ X) (+ Y)))").
+		LowerToMir().LowerToAir()
+	filter := sc.NameFilter(regexp.MustCompile(`^\(m:A,m:B\)=\(m:X,m:Y\)$`))
+	selected := 0
+	//
+	for iter := schema.Constraints(); iter.HasNext(); {
+		c := iter.Next()
+		//
+		if p, ok := c.(*constraint.PermutationConstraint); ok && p.Name() != "(m:A,m:B)=(m:X,m:Y)" {
+			t.Errorf("unexpected permutation handle %s", p.Name())
+		} else if filter(c) {
+			selected++
+		}
+	}
+	//
+	if selected != 1 {
+		t.Errorf("expected one permutation selected, got %d", selected)
+	}
+}
+
+// Check a permutation between the given target and source columns (where X is
+// column 0 and Y is column 1) on a given (raw) trace, returning the schema and
+// the failure (if any).
+func checkPermutation(t *testing.T, targets []uint, sources []uint,
+	text string) (*air.Schema, *constraint.PermutationFailure) {
+	schema := air.EmptySchema[air.Expr]()
+	ctx := trace.NewContext(schema.AddModule(""), 1)
+	//
+	for _, name := range []string{"X", "Y"} {
+		schema.AddColumn(ctx, name, sc.NewUintType(8), sc.DISPLAY_DEFAULT, fr.NewElement(0))
+	}
+	//
+	permutation := constraint.NewPermutationConstraint(constraint.PermutationHandle(targets, sources, schema), targets,
+		sources)
+	tr, errs := sc.NewTraceBuilder(schema).Expand(false).Padding(0).Build(ParseColumns(t, text))
+	//
+	if len(errs) > 0 {
+		t.Fatalf("error building trace: %v", errs)
+	}
+	//
+	if failure := permutation.Accepts(tr, 0); failure != nil {
+		return schema, failure.(*constraint.PermutationFailure)
+	}
+	//
+	return schema, nil
+}
+
+// ===================================================================
+// Explanations
+// ===================================================================
+
+func Test_Explain_01(t *testing.T) {
+	// Lookup failure
+	schema := CompileSchema(t, "(defcolumns X Y)\n(deflookup test (Y) ((+ X 1)))")
+	tr := BuildTrace(t, schema, `{"X": [1, 2], "Y": [2, 2]}`)
+	failures := sc.AcceptsUpto(100, 0, 0, schema, tr)
+	// Observe the padding row is also missing from the target
+	if len(failures) != 1 {
+		t.Fatalf("expected one failure, got %d", len(failures))
+	}
+	//
+	f, ok := failures[0].(*constraint.LookupFailure)
+	//
+	if !ok || !equalRows(f.Rows, []uint{0, 2}) {
+		t.Fatalf("unexpected failure %s", failures[0].Message())
+	}
+	//
+	checkExplanations(t, []sc.Explanation{f.ExplainAt(2, tr, schema)}, []string{
+		"((+ X 1)) = 0x3  [missing from target]",
+		"  (+ X 1) = 0x3",
+		"    X = 0x2",
+		"    1 = 0x1",
+	})
+}
+
+func Test_Explain_02(t *testing.T) {
+	// Permutation failure
+	schema, f := checkPermutation(t, []uint{1}, []uint{0}, `{"X": [1, 2], "Y": [2, 3]}`)
+	//
+	if f == nil {
+		t.Fatal("expected permutation failure")
+	}
+	//
+	checkExplanations(t, f.Explain(schema), []string{
+		"(X) = 0x1  [missing from target 1 time(s) (row 0)]",
+		"  X = 0x1",
+		"(Y) = 0x3  [missing from source 1 time(s) (row 1)]",
+		"  Y = 0x3",
+	})
+}
+
+// Check that the lines of a given set of explanations match those expected.
+func checkExplanations(t *testing.T, explanations []sc.Explanation, expected []string) {
+	var lines []string
+	//
+	for _, e := range explanations {
+		lines = append(lines, e.Lines()...)
+	}
+	//
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected explanation:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+// ===================================================================
+// Source Locations
+// ===================================================================
+
+func Test_SourceLocation_01(t *testing.T) {
+	// Each constraint is located at its own declaration, at every IR.  Observe
+	// that c2 is lowered into three constraints at the AIR level.
+	hirSchema := CompileSchema(t, "(defcolumns (X :byte@loob) (Y :byte@loob))\n"+
+		"(defconstraint c1 () X)\n(defconstraint c2 () (if X Y))\n(defconstraint c3 () Y)")
+	mirSchema := hirSchema.LowerToMir()
+	airSchema := mirSchema.LowerToAir()
+	//
+	checkSourceLines(t, hirSchema, []int{2, 3, 4})
+	checkSourceLines(t, mirSchema, []int{2, 3, 4})
+	checkSourceLines(t, airSchema, []int{2, 3, 3, 3, 4})
+}
+
+// Check the constraints of a given schema are located on the given lines.
+func checkSourceLines(t *testing.T, schema sc.Schema, expected []int) {
+	var lines []int
+	//
+	for i := schema.Constraints(); i.HasNext(); {
+		if loc := sc.LocationOf(i.Next()); loc != nil {
+			lines = append(lines, loc.Line)
+		} else {
+			lines = append(lines, 0)
+		}
+	}
+	//
+	if fmt.Sprint(lines) != fmt.Sprint(expected) {
+		t.Errorf("expected constraints on lines %v, got %v", expected, lines)
+	}
+}
+
+// ===================================================================
+// Displays
+// ===================================================================
+
+func Test_Display_01(t *testing.T) {
+	checkDisplay(t, "", sc.DISPLAY_DEFAULT, 255, "0xff")
+}
+
+func Test_Display_02(t *testing.T) {
+	checkDisplay(t, ":hex", sc.DISPLAY_HEX, 255, "0xff")
+}
+
+func Test_Display_03(t *testing.T) {
+	checkDisplay(t, "DEC", sc.DISPLAY_DEC, 255, "255")
+}
+
+func Test_Display_04(t *testing.T) {
+	checkDisplay(t, ":bytes", sc.DISPLAY_BYTES, 0x1ff, "01 ff")
+}
+
+func Test_Display_05(t *testing.T) {
+	checkDisplay(t, ":opcode", sc.DISPLAY_OPCODE, 0x01, "ADD")
+}
+
+func Test_Display_06(t *testing.T) {
+	// Unassigned opcodes are shown in hexadecimal
+	checkDisplay(t, ":opcode", sc.DISPLAY_OPCODE, 0x0c, "0xc")
+}
+
+// Check a given display attribute is parsed as expected (where the empty
+// attribute is unrecognised), and that it formats a given value as expected.
+func checkDisplay(t *testing.T, attribute string, expected sc.Display, value uint64, text string) {
+	display, ok := sc.ParseDisplay(attribute)
+	//
+	if display != expected || ok != (attribute != "") {
+		t.Errorf("attribute \"%s\" parsed as %s (%t)", attribute, display.String(), ok)
+	} else if actual := display.Format(fr.NewElement(value)); actual != text {
+		t.Errorf("expected %d formatted as \"%s\" by %s, got \"%s\"", value, text, display.String(), actual)
+	}
+}
+
+// ===================================================================
+// Computed Columns
+// ===================================================================
+
+func Test_Interleave_Padding_01(t *testing.T) {
+	// Padding is determined by the first source column (not the first column)
+	schema := CompileSchema(t, "(defcolumns A (X :padding 5) (Y :padding 6))\n(definterleaved Z (X Y))")
+	tr := BuildTrace(t, schema, `{"A": [1], "X": [2], "Y": [3]}`)
+	//
+	for i := uint(0); i < tr.Width(); i++ {
+		if col := tr.Column(i); col.Name() == "Z" {
+			padding := col.Padding()
+			// Check padding value and initial padding rows
+			if first, second := col.Get(0), col.Get(1); padding.Uint64() != 5 || first.Uint64() != 5 ||
+				second.Uint64() != 6 {
+				t.Errorf("expected padding 5 and padding rows [5,6], got %s and [%s,%s]", padding.String(),
+					first.String(), second.String())
+			}
+			//
+			return
+		}
+	}
+	//
+	t.Errorf("missing interleaved column")
+}
+
+// ===================================================================
+// Padding Protocols
+// ===================================================================
+
+func Test_PaddingProtocol_01(t *testing.T) {
+	// Pad upto the next power of two (empty modules are not padded)
+	checkPadding(t, sc.PowerOfTwoPadding(), "m", []uint{0, 1, 2, 3, 4, 5, 8, 9}, []uint{0, 0, 0, 1, 0, 3, 0, 7})
+}
+
+func Test_PaddingProtocol_02(t *testing.T) {
+	// Pad upto a fixed height (modules not given are not padded)
+	protocol := sc.FixedHeightPadding(map[string]uint{"m": 8})
+	//
+	checkPadding(t, protocol, "m", []uint{0, 1, 7, 8}, []uint{8, 7, 1, 0})
+	checkPadding(t, protocol, "", []uint{0, 1, 9}, []uint{0, 0, 0})
+}
+
+func Test_PaddingProtocol_03(t *testing.T) {
+	// Modules cannot exceed their fixed height
+	if _, err := sc.FixedHeightPadding(map[string]uint{"m": 8})("m", 9); err == nil {
+		t.Errorf("expected error padding module beyond its fixed height")
+	}
+}
+
+func Test_PaddingProtocol_04(t *testing.T) {
+	// Each protocol sees the height produced by those before it
+	protocol := sc.SequentialPadding(sc.FixedPadding(1), sc.PowerOfTwoPadding())
+	//
+	checkPadding(t, protocol, "m", []uint{0, 1, 3, 4, 7}, []uint{1, 1, 1, 4, 1})
+}
+
+func Test_PaddingProtocol_05(t *testing.T) {
+	// Errors from any protocol are propagated
+	protocol := sc.SequentialPadding(sc.FixedPadding(2), sc.FixedHeightPadding(map[string]uint{"m": 4}))
+	//
+	checkPadding(t, protocol, "m", []uint{0, 2}, []uint{4, 2})
+	//
+	if _, err := protocol("m", 3); err == nil {
+		t.Errorf("expected error padding module beyond its fixed height")
+	}
+}
+
+// Check a given padding protocol adds the expected number of padding rows to a
+// given module, for each of the given heights.
+func checkPadding(t *testing.T, protocol sc.PaddingProtocol, module string, heights []uint, expected []uint) {
+	for i, height := range heights {
+		if n, err := protocol(module, height); err != nil {
+			t.Errorf("unexpected error padding module '%s' of height %d (%s)", module, height, err)
+		} else if n != expected[i] {
+			t.Errorf("expected %d padding rows for module '%s' of height %d, got %d", expected[i], module, height, n)
+		}
+	}
+}
+
+// ===================================================================
+// Spillage Overrides
+// ===================================================================
+
+// Module m2 requires more spillage than the initial padding row.
+const spillageSource = `
+(defpurefun ((vanishes! :@loob) x) x)
+(module m1)
+(defcolumns S1 A)
+(defconstraint spills () (vanishes! (* S1 (* A (~ (shift A -2))))))
+(module m2)
+(defcolumns S2 B)
+(defconstraint spills () (vanishes! (* S2 (* B (~ (shift B 3))))))`
+
+func Test_SpillageOverride_01(t *testing.T) {
+	spillage := sc.GlobalSpillage(2)
+	//
+	for _, module := range []string{"", "m1", "m2"} {
+		if n, ok := spillage(module); !ok || n != 2 {
+			t.Errorf("expected spillage 2 for module '%s', got %d (%t)", module, n, ok)
+		}
+	}
+}
+
+func Test_SpillageOverride_02(t *testing.T) {
+	spillage := sc.ModuleSpillage(map[string]uint{"m1": 0, "m2": 4})
+	//
+	if n, ok := spillage("m1"); !ok || n != 0 {
+		t.Errorf("expected spillage 0 for module 'm1', got %d (%t)", n, ok)
+	} else if n, ok := spillage("m2"); !ok || n != 4 {
+		t.Errorf("expected spillage 4 for module 'm2', got %d (%t)", n, ok)
+	} else if _, ok := spillage(""); ok {
+		t.Errorf("expected spillage inferred for root module")
+	}
+}
+
+func Test_SpillageOverride_03(t *testing.T) {
+	// Sufficient spillage for every module
+	checkSpillageWarnings(t, sc.GlobalSpillage(3))
+	checkSpillageWarnings(t, sc.ModuleSpillage(map[string]uint{"m2": 3}))
+}
+
+func Test_SpillageOverride_04(t *testing.T) {
+	// Insufficient spillage for some modules
+	checkSpillageWarnings(t, sc.GlobalSpillage(2), "spillage 2 for module 'm2' is below inferred spillage 3")
+	checkSpillageWarnings(t, sc.ModuleSpillage(map[string]uint{"m1": 0, "m2": 4}),
+		"spillage 0 for module 'm1' is below inferred spillage 1")
+	checkSpillageWarnings(t, sc.GlobalSpillage(0), "spillage 0 for module '' is below inferred spillage 1",
+		"spillage 0 for module 'm1' is below inferred spillage 1",
+		"spillage 0 for module 'm2' is below inferred spillage 3")
+}
+
+// Check the warnings generated for a given spillage override (at the AIR
+// level, where spillage is required).
+func checkSpillageWarnings(t *testing.T, spillage sc.SpillageOverride, expected ...string) {
+	schema := CompileSchema(t, spillageSource).LowerToMir().LowerToAir()
+	warnings := sc.SpillageWarnings(schema, spillage)
+	//
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	//
+	for i, warning := range warnings {
+		if warning.Error() != expected[i] {
+			t.Errorf("expected warning \"%s\", got \"%s\"", expected[i], warning)
+		}
+	}
+}
+
+// ===================================================================
+// Padding Checks
+// ===================================================================
+
+func Test_CheckPadding_01(t *testing.T) {
+	// No constraints reject padding
+	schema := CompileSchema(t, spillageSource)
+	failures, errs := sc.CheckPadding(sc.NewTraceBuilder(schema), util.NewPair[uint, uint](0, 2))
+	//
+	if len(failures) != 0 || len(errs) != 0 {
+		t.Errorf("unexpected failures %v (errors %v)", failures, errs)
+	}
+}
+
+func Test_CheckPadding_02(t *testing.T) {
+	// Assertions which reject padding are reported
+	schema := CompileSchema(t, `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns A B)
+(defconstraint eq () (vanishes! (- A B)))
+(defproperty lem (vanishes! (- A 1)))`)
+	failures, errs := sc.CheckPadding(sc.NewTraceBuilder(schema), util.NewPair[uint, uint](1, 2))
+	//
+	if len(failures) != 2 || len(errs) != 0 {
+		t.Fatalf("expected two failures, got %v (errors %v)", failures, errs)
+	}
+	//
+	for i, f := range failures {
+		if f.Constraint != 0 || !f.Assertion || f.Padding != uint(i+1) {
+			t.Errorf("unexpected failure %v", f)
+		}
+	}
+}
+
+func Test_CheckSpillage_01(t *testing.T) {
+	// Inferred spillage is sufficient, including where more than the initial
+	// padding row is required.
+	schema := CompileSchema(t, spillageSource).LowerToMir().LowerToAir()
+	checkSpillageChecks(t, schema, []string{"", "m1", "m2"}, []uint{1, 1, 3}, []uint{0, 0, 0})
+}
+
+func Test_CheckSpillage_02(t *testing.T) {
+	// Least spillage is reported
+	schema := CompileSchema(t, `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns A)
+(defconstraint c () (vanishes! (- 1 (* (- (shift A 2) 1) (~ (- (shift A 2) 1))))))`)
+	//
+	checkSpillageChecks(t, schema, []string{""}, []uint{1}, []uint{1})
+}
+
+// Check the spillage checks for every module of a given schema, for padding
+// between 0 and 2.
+func checkSpillageChecks(t *testing.T, schema sc.Schema, modules []string, inferred []uint, required []uint) {
+	checks, errs := sc.CheckSpillage(sc.NewTraceBuilder(schema), util.NewPair[uint, uint](0, 2))
+	//
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	} else if len(checks) != len(modules) {
+		t.Fatalf("expected %d checks, got %v", len(modules), checks)
+	}
+	//
+	for i, check := range checks {
+		expected := sc.SpillageCheck{Module: modules[i], Inferred: inferred[i], Required: required[i]}
+		//
+		if check != expected {
+			t.Errorf("expected %v, got %v", expected, check)
+		} else if !check.IsSufficient() {
+			t.Errorf("expected spillage sufficient for module '%s'", check.Module)
+		}
+	}
+}
+
+// ===================================================================
+// Constraint Selection
+// ===================================================================
+
+const selectionSource = `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns A B)
+(defconstraint c1 () (vanishes! A))
+(module m)
+(defcolumns X Y)
+(defconstraint c2 () (vanishes! X))
+(defconstraint d1 () (vanishes! (- X Y)))
+(defproperty p1 (vanishes! Y))`
+
+func Test_Selection_01(t *testing.T) {
+	schema := CompileSchema(t, selectionSource)
+	// A nil filter selects everything (though column B is never required)
+	checkSelected(t, schema, nil, []string{"c1", "c2", "d1", "p1"}, []uint{0, 2, 3})
+	// Empty conjunctions also select everything
+	checkSelected(t, schema, sc.ConjunctFilter(), []string{"c1", "c2", "d1", "p1"}, []uint{0, 2, 3})
+}
+
+func Test_Selection_02(t *testing.T) {
+	schema := CompileSchema(t, selectionSource)
+	//
+	checkSelected(t, schema, sc.ModuleFilter(schema, []string{""}), []string{"c1"}, []uint{0})
+	checkSelected(t, schema, sc.ModuleFilter(schema, []string{"m"}), []string{"c2", "d1", "p1"}, []uint{2, 3})
+	checkSelected(t, schema, sc.ModuleFilter(schema, []string{"", "m"}), []string{"c1", "c2", "d1", "p1"},
+		[]uint{0, 2, 3})
+	checkSelected(t, schema, sc.ModuleFilter(schema, []string{"n"}), nil, nil)
+}
+
+func Test_Selection_03(t *testing.T) {
+	schema := CompileSchema(t, selectionSource)
+	//
+	checkSelected(t, schema, sc.NameFilter(regexp.MustCompile("^c")), []string{"c1", "c2"}, []uint{0, 2})
+	checkSelected(t, schema, sc.NameFilter(regexp.MustCompile("^p1$")), []string{"p1"}, []uint{3})
+	checkSelected(t, schema, sc.ConjunctFilter(sc.ModuleFilter(schema, []string{"m"}),
+		sc.NameFilter(regexp.MustCompile("^c"))), []string{"c2"}, []uint{2})
+}
+
+// Check the handles of the constraints and assertions selected by a given filter,
+// along with the columns on which they depend.
+func checkSelected(t *testing.T, schema sc.Schema, filter sc.ConstraintFilter, handles []string,
+	columns []uint) {
+	var selected []string
+	//
+	constraints := sc.SelectConstraints(schema.Constraints(), filter)
+	assertions := sc.SelectConstraints(schema.Assertions(), filter)
+	//
+	for iter := constraints.Append(assertions); iter.HasNext(); {
+		selected = append(selected, iter.Next().(sc.Selectable).Name())
+	}
+	//
+	if !slices.Equal(selected, handles) {
+		t.Errorf("expected constraints %v selected, got %v", handles, selected)
+	}
+	//
+	if actual := sc.SelectedColumns(schema, filter); !slices.Equal(actual, columns) {
+		t.Errorf("expected columns %v selected, got %v", columns, actual)
+	}
+}
+
+// ===================================================================
+// Row Windows
+// ===================================================================
+
+func Test_SelectedReach_01(t *testing.T) {
+	schema := CompileSchema(t, "(defcolumns (X :i16@loob))\n(defconstraint c () (- (shift X 2) X))")
+	checkSelectedReach(t, schema, nil, 2, true)
+}
+
+func Test_SelectedReach_02(t *testing.T) {
+	// Reach of computed columns (e.g. inverses) accumulates
+	hirSchema := CompileSchema(t, `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns A B)
+(defconstraint c () (vanishes! (* (shift B 3) (~ (shift A 1)))))`)
+	airSchema := hirSchema.LowerToMir().LowerToAir()
+	//
+	checkSelectedReach(t, hirSchema, nil, 3, true)
+	checkSelectedReach(t, airSchema, nil, 4, true)
+}
+
+func Test_SelectedReach_03(t *testing.T) {
+	// Lookups can access any row, unless they are not selected.
+	schema := CompileSchema(t, `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns A B)
+(defconstraint c () (vanishes! (shift A 1)))
+(deflookup l (A) (B))`)
+	//
+	checkSelectedReach(t, schema, nil, 0, false)
+	checkSelectedReach(t, schema, sc.NameFilter(regexp.MustCompile("^c$")), 1, true)
+}
+
+func Test_Truncate_01(t *testing.T) {
+	schema := CompileSchema(t, "(defcolumns (X :i16) (Y :i16))")
+	columns := ParseColumns(t, `{"X": [1, 2, 3, 4, 5], "Y": [6, 7, 8, 9, 10]}`)
+	// Expected values include the initial padding row
+	checkTruncate(t, schema, columns, 0, []uint{0}, []uint{0})
+	checkTruncate(t, schema, columns, 2, []uint{0, 1, 2}, []uint{0, 6, 7})
+	checkTruncate(t, schema, columns, 5, []uint{0, 1, 2, 3, 4, 5}, []uint{0, 6, 7, 8, 9, 10})
+	checkTruncate(t, schema, columns, 6, []uint{0, 1, 2, 3, 4, 5}, []uint{0, 6, 7, 8, 9, 10})
+}
+
+// Check the columns X and Y of a trace built after truncating the given columns
+// to a given height.
+func checkTruncate(t *testing.T, schema sc.Schema, columns []trace.RawColumn, height uint, xs []uint, ys []uint) {
+	tr, errs := sc.NewTraceBuilder(schema).Padding(0).Truncate(height).Build(columns)
+	//
+	if len(errs) > 0 {
+		t.Fatalf("error building trace: %v", errs)
+	}
+	//
+	for col, expected := range [][]uint{xs, ys} {
+		data := tr.Column(uint(col)).Data()
+		//
+		if data.Len() != uint(len(expected)) {
+			t.Errorf("expected %d rows after truncating to %d, got %d", len(expected), height, data.Len())
+			continue
+		}
+		//
+		for row, val := range expected {
+			if actual := data.Get(uint(row)); actual.Uint64() != uint64(val) {
+				t.Errorf("expected %d at row %d after truncating to %d, got %s", val, row, height, actual.String())
+			}
+		}
+	}
+}
+
+// Check the reach determined for the constraints of a given schema selected by a
+// given filter.
+func checkSelectedReach(t *testing.T, schema sc.Schema, filter sc.ConstraintFilter, expected uint, bounded bool) {
+	if reach, ok := sc.SelectedReach(schema, filter); ok != bounded {
+		t.Errorf("expected reach bounded to be %t, got %t", bounded, ok)
+	} else if ok && reach != expected {
+		t.Errorf("expected reach %d, got %d", expected, reach)
+	}
+}
+
+// ===================================================================
+// Boundary Values
+// ===================================================================
+
+func Test_BoundaryValues_01(t *testing.T) {
+	checkBoundaryValues(t, sc.NewUintType(1), 0, 1)
+}
+
+func Test_BoundaryValues_02(t *testing.T) {
+	checkBoundaryValues(t, sc.NewUintType(8), 0, 1, 127, 128, 255)
+}
+
+func Test_BoundaryValues_03(t *testing.T) {
+	checkBoundaryValues(t, sc.NewUintType(16), 0, 1, 32767, 32768, 65535)
+}
+
+func Test_BoundaryValues_04(t *testing.T) {
+	var minusOne fr.Element
+	// Largest element of the field
+	minusOne.SetInt64(-1)
+	//
+	values := sc.BoundaryValues(&sc.FieldType{})
+	//
+	if !slices.Equal(values, []fr.Element{fr.NewElement(0), fr.NewElement(1), minusOne}) {
+		t.Errorf("unexpected boundary values %v for field type", values)
+	}
+}
+
+func checkBoundaryValues(t *testing.T, datatype sc.Type, expected ...uint64) {
+	values := sc.BoundaryValues(datatype)
+	//
+	if !slices.Equal(values, toFrElements(expected...)) {
+		t.Errorf("boundary values of %s are %v, expected %v", datatype.String(), values, expected)
+	}
+}
+
+// ===================================================================
+// Shrinking
+// ===================================================================
+
+func Test_ShrinkTrace_01(t *testing.T) {
+	schema := CompileSchema(t, `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns (X :i16) (Y :i16))
+(defconstraint c () (vanishes! (- X Y)))`)
+	oracle := sc.RejectedBy(sc.NewTraceBuilder(schema), schema.Constraints().Next())
+	columns := ParseColumns(t, `{"X": [1, 2, 3, 4], "Y": [1, 2, 7, 4]}`)
+	// Rows are removed and values simplified (by halving) whilst c fails
+	checkShrinkTrace(t, columns, sc.InputPaddingOf(schema), sc.InputColumnsOf(schema), oracle,
+		[]trace.RawColumn{{Module: "", Name: "X", Data: toFrArray([]uint64{0})},
+			{Module: "", Name: "Y", Data: toFrArray([]uint64{1})}})
+}
+
+func Test_ShrinkTrace_02(t *testing.T) {
+	// Column B has a length multiplier of 2, hence rows are removed in pairs.
+	columns := []trace.RawColumn{{Module: "m", Name: "A", Data: toFrArray([]uint64{1, 2})},
+		{Module: "m", Name: "B", Data: toFrArray([]uint64{0, 0, 9, 0})}}
+	// Holds when both columns are present, and B holds 9
+	oracle := func(cols []trace.RawColumn) bool {
+		if len(cols) != 2 {
+			return false
+		}
+		//
+		for i := uint(0); i < cols[1].Data.Len(); i++ {
+			if cols[1].Data.Get(i) == fr.NewElement(9) {
+				return true
+			}
+		}
+		//
+		return false
+	}
+	//
+	checkShrinkTrace(t, columns, nil, nil, oracle,
+		[]trace.RawColumn{{Module: "m", Name: "A", Data: toFrArray([]uint64{0})},
+			{Module: "m", Name: "B", Data: toFrArray([]uint64{9, 0})}})
+}
+
+func Test_ShrinkTrace_03(t *testing.T) {
+	// Columns are dropped, and values simplified towards their padding value.
+	columns := ParseColumns(t, `{"X": [5, 6], "Y": [7, 8]}`)
+	padding := func(col trace.RawColumn) fr.Element {
+		return fr.NewElement(3)
+	}
+	// Holds when X is non-empty and non-zero throughout
+	oracle := func(cols []trace.RawColumn) bool {
+		for _, col := range cols {
+			for i := uint(0); col.Name == "X" && i < col.Data.Len(); i++ {
+				if ith := col.Data.Get(i); ith.IsZero() {
+					return false
+				}
+			}
+		}
+		//
+		return len(cols) > 0 && cols[0].Name == "X" && cols[0].Data.Len() > 0
+	}
+	//
+	checkShrinkTrace(t, columns, padding, nil, oracle,
+		[]trace.RawColumn{{Module: "", Name: "X", Data: toFrArray([]uint64{3})}})
+}
+
+func Test_ShrinkTrace_04(t *testing.T) {
+	// Input columns are never dropped, even when unused by the constraint.
+	schema := CompileSchema(t, `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns X Y Z)
+(defconstraint c () (vanishes! (- X Y)))`)
+	builder := sc.NewTraceBuilder(schema).Columns(sc.SelectedColumns(schema, nil))
+	oracle := sc.RejectedBy(builder, schema.Constraints().Next())
+	columns := ParseColumns(t, `{"X": [1, 2], "Y": [1, 3], "Z": [4, 5]}`)
+	//
+	checkShrinkTrace(t, columns, nil, sc.InputColumnsOf(schema), oracle,
+		[]trace.RawColumn{{Module: "", Name: "X", Data: toFrArray([]uint64{0})},
+			{Module: "", Name: "Y", Data: toFrArray([]uint64{1})},
+			{Module: "", Name: "Z", Data: toFrArray([]uint64{0})}})
+}
+
+func Test_ShrinkTrace_05(t *testing.T) {
+	// Traces built with warnings (e.g. missing input columns) do not preserve
+	// any property.
+	schema := CompileSchema(t, `
+(defpurefun ((vanishes! :@loob) x) x)
+(defcolumns X Y)
+(defconstraint c () (vanishes! X))`)
+	builder := sc.NewTraceBuilder(schema)
+	//
+	if !sc.RejectedBy(builder, schema.Constraints().Next())(ParseColumns(t, `{"X": [1], "Y": [0]}`)) {
+		t.Errorf("expected trace to be rejected")
+	}
+	//
+	if sc.RejectedBy(builder, schema.Constraints().Next())(ParseColumns(t, `{"X": [1]}`)) {
+		t.Errorf("expected trace with missing column not to be rejected")
+	}
+	//
+	if sc.AcceptedBy(builder)(ParseColumns(t, `{"X": [0]}`)) {
+		t.Errorf("expected trace with missing column not to be accepted")
+	}
+}
+
+// Check shrinking the given columns whilst preserving a given oracle produces
+// the expected columns, and that the original columns are not modified.
+func checkShrinkTrace(t *testing.T, columns []trace.RawColumn, padding func(trace.RawColumn) fr.Element,
+	required func(trace.RawColumn) bool, oracle sc.TraceOracle, expected []trace.RawColumn) {
+	original := make([]trace.RawColumn, len(columns))
+	// Columns parsed from JSON are in no particular order, whilst the order in
+	// which columns are shrunk affects the outcome.
+	slices.SortFunc(columns, func(l trace.RawColumn, r trace.RawColumn) int {
+		return strings.Compare(l.QualifiedName(), r.QualifiedName())
+	})
+	//
+	for i, col := range columns {
+		original[i] = trace.RawColumn{Module: col.Module, Name: col.Name, Data: col.Data.Clone()}
+	}
+	//
+	shrunk := sc.ShrinkTrace(columns, padding, required, oracle)
+	//
+	if !rawColumnsEqual(shrunk, expected) {
+		t.Errorf("expected %s, got %s", rawColumnsString(expected), rawColumnsString(shrunk))
+	}
+	//
+	if !rawColumnsEqual(columns, original) {
+		t.Errorf("original columns modified by shrinking")
+	}
+}
+
+func rawColumnsString(columns []trace.RawColumn) string {
+	var builder strings.Builder
+	//
+	for _, col := range columns {
+		builder.WriteString(fmt.Sprintf("%s=[", col.QualifiedName()))
+		//
+		for i := uint(0); i < col.Data.Len(); i++ {
+			if i != 0 {
+				builder.WriteString(",")
+			}
+			//
+			ith := col.Data.Get(i)
+			builder.WriteString(ith.String())
+		}
+		//
+		builder.WriteString("] ")
+	}
+	//
+	return builder.String()
+}
+
+// ===================================================================
+// Test Helpers
+// ===================================================================
+
+// CompileSchema compiles a given set of constraints (without the standard
+// library) into an HIR schema.
+func CompileSchema(t *testing.T, source string) *hir.Schema {
+	srcfile := sexp.NewSourceFile("test.lisp", []byte(source))
+	schema, errs := corset.CompileSourceFile(false, false, srcfile)
+	//
+	if len(errs) > 0 {
+		t.Fatalf("error compiling constraints: %v", errs)
+	}
+	//
+	return schema
+}
+
+// ParseColumns parses a given trace expressed as JSON.
+func ParseColumns(t *testing.T, text string) []trace.RawColumn {
+	columns, err := json.FromBytes([]byte(text))
+	//
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	return columns
+}
+
+// BuildTrace builds (i.e. expands) a given trace expressed as JSON for a given
+// schema, with no padding.
+func BuildTrace(t *testing.T, schema sc.Schema, text string) trace.Trace {
+	tr, errs := sc.NewTraceBuilder(schema).Padding(0).Parallel(false).Build(ParseColumns(t, text))
+	//
+	if tr == nil || len(errs) > 0 {
+		t.Fatalf("error building trace: %v", errs)
+	}
+	//
+	return tr
+}
+
+func equalRows(lhs []uint, rhs []uint) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+	//
+	for i := range lhs {
+		if lhs[i] != rhs[i] {
+			return false
+		}
+	}
+	//
+	return true
+}
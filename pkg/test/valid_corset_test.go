@@ -5,9 +5,11 @@ import (
 	"encoding/gob"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/corset"
 	"github.com/consensys/go-corset/pkg/hir"
 	sc "github.com/consensys/go-corset/pkg/schema"
@@ -202,6 +204,32 @@ func Test_Property_01(t *testing.T) {
 	Check(t, false, "property_01")
 }
 
+// ===================================================================
+// DefTest Tests
+// ===================================================================
+
+func Test_DefTest_01(t *testing.T) {
+	Check(t, false, "deftest_01")
+}
+
+func Test_DefTest_02(t *testing.T) {
+	// Inline tests are recorded in the schema, and survive encoding.
+	bytes, err := os.ReadFile(fmt.Sprintf("%s/deftest_01.lisp", TestDir))
+	//
+	if err != nil {
+		t.Fatal(err)
+	}
+	//
+	schema := CompileSchema(t, string(bytes))
+	expected := []hir.InlineTest{
+		{Name: "t1", Accepts: true, Columns: []hir.InlineColumn{inlineColumn("A", 1, 2), inlineColumn("B", 1, 2)}},
+		{Name: "t2", Accepts: false, Columns: []hir.InlineColumn{inlineColumn("A", 1), inlineColumn("B", 2)}},
+	}
+	//
+	checkInlineTests(t, schema.InlineTests(), expected)
+	checkInlineTests(t, encodeDecodeSchema(t, schema).InlineTests(), expected)
+}
+
 // ===================================================================
 // Shift Tests
 // ===================================================================
@@ -278,6 +306,18 @@ func Test_Spillage_09(t *testing.T) {
 	Check(t, false, "spillage_09")
 }
 
+// ===================================================================
+// Padding Tests
+// ===================================================================
+
+func Test_Padding_01(t *testing.T) {
+	Check(t, false, "padding_01")
+}
+
+func Test_Padding_02(t *testing.T) {
+	Check(t, false, "padding_02")
+}
+
 // ===================================================================
 // Normalisation Tests
 // ===================================================================
@@ -1236,6 +1276,30 @@ func ReadTracesFile(filename string) [][]trace.RawColumn {
 
 // This is a little test to ensure the binary file format (specifically the
 // binary encoder / decoder) works as expected.
+// Check the given inline tests match those expected.
+func checkInlineTests(t *testing.T, tests []hir.InlineTest, expected []hir.InlineTest) {
+	if len(tests) != len(expected) {
+		t.Fatalf("expected %d inline test(s), got %d", len(expected), len(tests))
+	}
+	//
+	for i, test := range tests {
+		if !reflect.DeepEqual(test, expected[i]) {
+			t.Errorf("expected inline test %v, got %v", expected[i], test)
+		}
+	}
+}
+
+// Construct an inline test column of the root module with the given values.
+func inlineColumn(name string, values ...uint64) hir.InlineColumn {
+	elements := make([]fr.Element, len(values))
+	//
+	for i, v := range values {
+		elements[i] = fr.NewElement(v)
+	}
+	//
+	return hir.InlineColumn{Module: "", Name: name, Values: elements}
+}
+
 func encodeDecodeSchema(t *testing.T, schema *hir.Schema) *hir.Schema {
 	var (
 		buffer     bytes.Buffer
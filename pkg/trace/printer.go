@@ -5,6 +5,7 @@ import (
 	"math"
 	"unicode/utf8"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	"github.com/consensys/go-corset/pkg/util"
 )
 
@@ -15,6 +16,17 @@ type ColumnFilter = func(uint, Trace) bool
 // Highlighter identifies cells which should be highlighted.
 type Highlighter = func(CellRef, Trace) bool
 
+// ColumnNamer determines the title used for a given column in the print out.
+type ColumnNamer = func(uint, Trace) string
+
+// Annotator provides an (optional) annotation for a given cell, which is shown
+// alongside its value.  An empty annotation indicates nothing should be shown.
+type Annotator = func(CellRef, Trace) string
+
+// CellFormatter determines the text used for a given value of a given column in
+// the print out.
+type CellFormatter = func(uint, fr.Element) string
+
 // Printer encapsulates various configuration options useful for printing out
 // traces in human-readable forms.
 type Printer struct {
@@ -28,6 +40,12 @@ type Printer struct {
 	colFilter ColumnFilter
 	// Which columns to highlight
 	highlighter Highlighter
+	// Determines column titles
+	namer ColumnNamer
+	// Determines cell annotations
+	annotator Annotator
+	// Determines cell text
+	formatter CellFormatter
 	// Determine maximum width to print
 	maxCellWidth uint
 	// Enable ANSI
@@ -44,8 +62,21 @@ func NewPrinter() *Printer {
 	emptyHighlighter := func(cell CellRef, t Trace) bool {
 		return false
 	}
+	// Use column names by default
+	defaultNamer := func(col uint, t Trace) string {
+		return t.Column(col).Name()
+	}
+	// Annotate nothing by default
+	emptyAnnotator := func(cell CellRef, t Trace) string {
+		return ""
+	}
+	// Use hexadecimal notation by default
+	hexFormatter := func(col uint, val fr.Element) string {
+		return fmt.Sprintf("0x%s", val.Text(16))
+	}
 	// Return an empty printer
-	return &Printer{0, math.MaxInt, 2, emptyFilter, emptyHighlighter, math.MaxUint, true}
+	return &Printer{0, math.MaxInt, 2, emptyFilter, emptyHighlighter, defaultNamer, emptyAnnotator, hexFormatter,
+		math.MaxUint, true}
 }
 
 // Start configures the starting row for this printer.
@@ -88,6 +119,27 @@ func (p *Printer) Highlight(highlighter Highlighter) *Printer {
 	return p
 }
 
+// ColumnNames configures how column titles are determined.  By default, the
+// name of each column is used.
+func (p *Printer) ColumnNames(namer ColumnNamer) *Printer {
+	p.namer = namer
+	return p
+}
+
+// Annotate configures annotations to be shown alongside cell values.  By
+// default, no cells are annotated.
+func (p *Printer) Annotate(annotator Annotator) *Printer {
+	p.annotator = annotator
+	return p
+}
+
+// Format configures how the values of each column are shown.  By default,
+// values are shown in hexadecimal notation.
+func (p *Printer) Format(formatter CellFormatter) *Printer {
+	p.formatter = formatter
+	return p
+}
+
 // MaxCellWidth sets the maximum width to use for the cell data.
 func (p *Printer) MaxCellWidth(width uint) *Printer {
 	p.maxCellWidth = width
@@ -105,7 +157,7 @@ func (p *Printer) Print(trace Trace) {
 		start = p.startRow
 	}
 
-	end := min(MaxHeight(trace), p.startRow+p.padding+1)
+	end := min(MaxHeight(trace), p.endRow+p.padding+1)
 	columns := make([]uint, 0)
 	width := 1 + end - start
 	// Filter columns
@@ -130,28 +182,33 @@ func (p *Printer) Print(trace Trace) {
 		column := trace.Column(col)
 		maxRow := min(end, column.Data().Len())
 		// Set columns names
-		tp.Set(0, uint(i+1), column.Name())
+		tp.Set(0, uint(i+1), p.namer(col, trace))
 		tp.SetEscape(0, uint(i+1), util.NewAnsiEscape().FgColour(util.TERM_WHITE).Build())
 		//
 		for row := start; row < maxRow; row++ {
-			var hex string
+			var text string
 			// Extract data for cell
 			jth := column.Data().Get(row)
 			// Determine text of cell
-			highlight := p.highlighter(NewCellRef(col, int(row)), trace)
+			cell := NewCellRef(col, int(row))
+			highlight := p.highlighter(cell, trace)
 			//
 			if highlight && !p.ansiEscapes {
 				// In a non-ANSI environment, use a marker "*" to identify which cells were depended upon.
-				hex = fmt.Sprintf("*0x%s", jth.Text(16))
+				text = fmt.Sprintf("*%s", p.formatter(col, jth))
 			} else {
 				if highlight {
 					tp.SetEscape(1+row-start, uint(i+1), highlightEscape)
 				}
 				//
-				hex = fmt.Sprintf("0x%s", jth.Text(16))
+				text = p.formatter(col, jth)
+			}
+			// Append annotation (if applicable)
+			if note := p.annotator(cell, trace); note != "" {
+				text = fmt.Sprintf("%s [%s]", text, note)
 			}
 			//
-			tp.Set(1+row-start, uint(i+1), hex)
+			tp.Set(1+row-start, uint(i+1), text)
 		}
 	}
 	// Cap cells
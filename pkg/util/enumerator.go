@@ -39,7 +39,8 @@ func (p *enumerator[E]) Next() []E {
 		rs[i] = p.elements[p.counters[i]]
 	}
 	//
-	carry := false
+	// An empty array is enumerated exactly once
+	carry := len(p.counters) == 0
 	// Increment counters
 	for i := 0; i < len(p.counters); i++ {
 		ithp1 := p.counters[i] + 1
@@ -63,3 +64,35 @@ func (p *enumerator[E]) Next() []E {
 	//
 	return rs
 }
+
+// NewConcatEnumerator returns an enumerator which enumerates the items of each
+// given enumerator in turn.
+func NewConcatEnumerator[T any](enumerators []Enumerator[T]) Enumerator[T] {
+	return &concatEnumerator[T]{enumerators}
+}
+
+type concatEnumerator[T any] struct {
+	enumerators []Enumerator[T]
+}
+
+// HasNext checks whether or not there are any items remaining to visit.
+//
+//nolint:revive
+func (p *concatEnumerator[T]) HasNext() bool {
+	// Skip over exhausted enumerators
+	for len(p.enumerators) > 0 && !p.enumerators[0].HasNext() {
+		p.enumerators = p.enumerators[1:]
+	}
+	//
+	return len(p.enumerators) > 0
+}
+
+// Next returns the next item, and advance the iterator.
+//
+//nolint:revive
+func (p *concatEnumerator[T]) Next() T {
+	// Ensure current enumerator is not exhausted
+	p.HasNext()
+	//
+	return p.enumerators[0].Next()
+}
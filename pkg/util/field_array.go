@@ -73,6 +73,23 @@ func FrArrayFromBigInts(bitWidth uint, ints []*big.Int) FrArray {
 	return elements
 }
 
+// WidenFrArray returns an array holding the same elements as a given array, but
+// which can hold any element of (at least) a given bitwidth.  If the given
+// array is already wide enough, then it is returned unchanged.
+func WidenFrArray(arr FrArray, bitWidth uint) FrArray {
+	if bitWidth <= arr.BitWidth() {
+		return arr
+	}
+	//
+	elements := NewFrArray(arr.Len(), bitWidth)
+	//
+	for i := uint(0); i < arr.Len(); i++ {
+		elements.Set(i, arr.Get(i))
+	}
+	//
+	return elements
+}
+
 // ----------------------------------------------------------------------------
 
 // FrElementArray implements an array of field elements using an underlying
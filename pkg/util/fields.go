@@ -2,6 +2,7 @@ package util
 
 import (
 	"encoding/binary"
+	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 )
@@ -40,3 +41,13 @@ func FrElementToBytes(element fr.Element) [32]byte {
 	// Done
 	return bytes
 }
+
+// FrElementBitWidth determines the number of bits required to represent a given
+// field element (i.e. when viewed as an unsigned integer).
+func FrElementBitWidth(element fr.Element) uint {
+	var val big.Int
+	//
+	element.BigInt(&val)
+	//
+	return uint(val.BitLen())
+}
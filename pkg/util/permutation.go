@@ -19,6 +19,9 @@ import (
 func ArePermutationOf[T Array[fr.Element]](dst []T, src []T) bool {
 	if len(dst) != len(src) {
 		return false
+	} else if len(dst) == 0 {
+		// Empty tables are trivially permutations of each other
+		return true
 	}
 	// Determine geometry
 	ncols := len(dst)
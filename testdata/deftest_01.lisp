@@ -0,0 +1,6 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns A B)
+(defconstraint eq () (vanishes! (- A B)))
+(deftest t1 :accepts (A 1 2) (B 1 2))
+(deftest t2 :rejects (A 1) (B 2))
@@ -0,0 +1,3 @@
+;;error:3:13-19:expected :accepts or :rejects
+(defcolumns X)
+(deftest t1 :maybe (X 1))
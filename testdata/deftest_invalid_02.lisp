@@ -0,0 +1,3 @@
+;;error:3:25-26:invalid test value
+(defcolumns X)
+(deftest t1 :accepts (X a))
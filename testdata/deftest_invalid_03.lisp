@@ -0,0 +1,4 @@
+;;error:4:23-24:not an input column
+(defcolumns X)
+(definterleaved Z (X X))
+(deftest t1 :accepts (Z 1))
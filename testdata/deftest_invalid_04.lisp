@@ -0,0 +1,4 @@
+;;error:4:1-28:test t1 already declared
+(defcolumns X)
+(deftest t1 :accepts (X 1))
+(deftest t1 :rejects (X 2))
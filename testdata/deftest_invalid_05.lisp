@@ -0,0 +1,3 @@
+;;error:3:30-35:inconsistent test column height (expected 2 rows)
+(defcolumns X Y)
+(deftest t1 :accepts (X 1 2) (Y 1))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :padding 1) Y)
+(defconstraint c1 ()
+  (vanishes! (- X Y 1)))
@@ -0,0 +1,5 @@
+(defpurefun ((vanishes! :@loob) x) x)
+
+(defcolumns (X :padding 0x10) Y)
+(defconstraint c1 ()
+  (vanishes! (- (~ (- X 16)) Y)))
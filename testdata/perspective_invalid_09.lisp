@@ -0,0 +1,5 @@
+;;error:4:23-42:conflicting padding for register B_xor_C
+;;error:5:23-42:conflicting padding for register B_xor_C
+(defcolumns (P :binary@prove) (Q :binary@prove))
+(defperspective p1 P ((B :i16 :padding 1)))
+(defperspective p2 Q ((C :i16 :padding 2)))